@@ -1,9 +1,15 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/ka1fe1/crypto-monitoring/config"
 	_ "github.com/ka1fe1/crypto-monitoring/docs"
@@ -13,9 +19,16 @@ import (
 	"github.com/ka1fe1/crypto-monitoring/internal/tasks"
 	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter/dingding"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter/email"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter/feishu"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter/outbox"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter/telegram"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter/webhook"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/opensea"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/polymarket"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/tsdb"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/twitter"
 )
 
@@ -62,10 +75,53 @@ func main() {
 	// Initialize Handlers
 	_ = handlers.NewDexPairHandler(dexService)
 
-	// Initialize DingTalk Bots
-	dingBots := make(map[string]*dingding.DingBot)
+	// Initialize the notification outbox, every message is recorded and retried until delivered
+	box, err := outbox.Open(cfg.Outbox.Path, cfg.Outbox.MaxAttempts)
+	if err != nil {
+		logger.Error("Failed to open outbox, sending notifications directly: %v", err)
+	}
+	withOutbox := func(name, channel string, n alter.Notifier) alter.Notifier {
+		if box == nil {
+			return n
+		}
+		return box.Wrap(name, channel, n)
+	}
+
+	// Initialize Notifiers (DingTalk, Telegram, Feishu, Email & Webhook), keyed by bot name
+	notifiers := make(map[string]alter.Notifier)
+	var dingBots []*dingding.DingBot
+	var emailBots []*email.EmailBot
 	for name, botCfg := range cfg.DingTalk {
-		dingBots[name] = dingding.NewDingBot(botCfg.AccessToken, botCfg.Secret, botCfg.Keyword)
+		dingBot := dingding.NewDingBot(botCfg.AccessToken, botCfg.Secret, botCfg.Keyword)
+		// The outbox retries failed deliveries itself, the queue only does without one.
+		dingBot.StartQueue(botCfg.RateLimitPerMinute, botCfg.QueueSize, box != nil)
+		dingBots = append(dingBots, dingBot)
+		alter.AddNotifier(notifiers, name, withOutbox(name, "dingtalk", dingBot))
+	}
+	for name, botCfg := range cfg.Telegram {
+		alter.AddNotifier(notifiers, name, withOutbox(name, "telegram", telegram.NewTelegramBot(botCfg.BotToken, botCfg.ChatID, botCfg.Keyword)))
+	}
+	for name, botCfg := range cfg.Feishu {
+		feishuBot := feishu.NewFeishuBot(botCfg.AccessToken, botCfg.Secret, botCfg.Keyword)
+		if botCfg.BaseURL != "" {
+			feishuBot.BaseURL = botCfg.BaseURL
+		}
+		alter.AddNotifier(notifiers, name, withOutbox(name, "feishu", feishuBot))
+	}
+	for name, hookCfg := range cfg.Webhook {
+		webhookBot, err := webhook.NewWebhookBot(hookCfg.URL, hookCfg.Secret, hookCfg.Keyword, hookCfg.Template, hookCfg.Headers)
+		if err != nil {
+			logger.Error("Failed to create webhook %s: %v", name, err)
+			continue
+		}
+		alter.AddNotifier(notifiers, name, withOutbox(name, "webhook", webhookBot))
+	}
+	for name, mailCfg := range cfg.Email {
+		emailBot := email.NewEmailBot(mailCfg.Host, mailCfg.Port, mailCfg.Username, mailCfg.Password, mailCfg.From, mailCfg.To, mailCfg.Keyword, mailCfg.Digest, mailCfg.DailyHour)
+		emailBot.Start()
+		emailBots = append(emailBots, emailBot)
+		// The digest keeps unsent messages itself, so email bypasses the outbox.
+		alter.AddNotifier(notifiers, name, emailBot)
 	}
 
 	// Initialize Tokens & OpenSea
@@ -78,19 +134,100 @@ func main() {
 
 	// Initialize Twitter
 	twitterClient := twitter.NewTwitterClient(cfg.Twitter.APIKey)
+	twitterCursors := service.NewTwitterCursors(cfg.TwitterMonitor.StatePath)
+
+	// Initialize the metric store, the history of everything the tasks fetch
+	var store *tsdb.Store
+	if cfg.MetricStore.Enabled == nil || *cfg.MetricStore.Enabled {
+		store, err = openMetricStore(cfg.MetricStore)
+		if err != nil {
+			logger.Error("Failed to open metric store, not keeping history: %v", err)
+		}
+	}
+
+	// Cancel the root context on SIGINT/SIGTERM to shut down gracefully
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	// Initialize and Start Tasks
-	tasks.InitTasks(cfg, dingBots, dexService, tokenService, openSeaService, polyClient, twitterClient)
+	scheduler := tasks.InitTasks(cfg, notifiers, dexService, tokenService, openSeaService, polyClient, twitterClient, twitterCursors, store)
+	scheduler.Start(ctx)
+
+	if box != nil {
+		box.Start()
+	}
 
 	// SetupRouter
-	r := routers.SetupRouter(cfg)
+	r := routers.SetupRouter(cfg, box, store, twitterCursors)
 
 	// Start Server
 	addr := cfg.Server.Port
 	if !strings.HasPrefix(addr, ":") {
 		addr = ":" + addr
 	}
-	if err := r.Run(addr); err != nil {
-		log.Fatalf("Failed to run server: %v", err)
+	srv := &http.Server{Addr: addr, Handler: r}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to run server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	logger.Info("Shutting down, waiting for tasks and queued notifications...")
+
+	timeout := time.Duration(cfg.Server.ShutdownTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Failed to shut down server: %v", err)
+	}
+	// Task runs are canceled through ctx; wait for them so their notifications get queued.
+	if err := scheduler.Stop(shutdownCtx); err != nil {
+		logger.Error("Tasks still running at shutdown: %v", err)
+	}
+	// Deliveries in progress wait on the DingTalk queues, so drain the outbox before stopping them.
+	if box != nil {
+		if err := box.Stop(shutdownCtx); err != nil {
+			logger.Error("Failed to drain outbox: %v", err)
+		}
+	}
+	for _, dingBot := range dingBots {
+		if err := dingBot.StopQueue(shutdownCtx); err != nil {
+			logger.Error("Failed to drain DingTalk queue for %s: %v", dingBot.Keyword, err)
+		}
+	}
+	for _, emailBot := range emailBots {
+		if err := emailBot.Stop(); err != nil {
+			logger.Error("Failed to send final email digest: %v", err)
+		}
+	}
+	if store != nil {
+		if err := store.Close(); err != nil {
+			logger.Error("Failed to close metric store: %v", err)
+		}
+	}
+	logger.Info("Shutdown complete")
+}
+
+// openMetricStore converts the retention policies of the config and opens the store.
+// Rollups with an invalid resolution are logged and skipped.
+func openMetricStore(cfg config.MetricStoreConfig) (*tsdb.Store, error) {
+	options := tsdb.Options{RawRetention: time.Duration(max(cfg.RawRetentionDays, 0)) * 24 * time.Hour}
+	for _, r := range cfg.Rollups {
+		resolution, err := utils.ParseWindow(r.Resolution)
+		if err != nil {
+			logger.Error("Ignoring metric store rollup: %v", err)
+			continue
+		}
+		options.Rollups = append(options.Rollups, tsdb.Rollup{
+			Resolution: resolution,
+			Retention:  time.Duration(max(r.RetentionDays, 0)) * 24 * time.Hour,
+		})
 	}
+	return tsdb.Open(cfg.Path, options)
 }
@@ -66,7 +66,7 @@ func maskSensitiveData(node *yaml.Node) {
 }
 
 func isSensitive(key string) bool {
-	sensitiveKeys := []string{"api_key", "access_token", "secret"}
+	sensitiveKeys := []string{"api_key", "access_token", "secret", "bot_token", "password"}
 	for _, k := range sensitiveKeys {
 		if k == key {
 			return true
@@ -15,6 +15,13 @@ type Config struct {
 	Server               ServerConfig               `yaml:"server"`
 	CoinMarketCap        CoinMarketCapConfig        `yaml:"coinmarketcap"`
 	DingTalk             map[string]DingTalkConfig  `yaml:"dingtalk"`
+	Telegram             map[string]TelegramConfig  `yaml:"telegram"`
+	Feishu               map[string]FeishuConfig    `yaml:"feishu"`
+	Email                map[string]EmailConfig     `yaml:"email"`
+	Webhook              map[string]WebhookConfig   `yaml:"webhook"`
+	Outbox               OutboxConfig               `yaml:"outbox"`
+	MetricStore          MetricStoreConfig          `yaml:"metric_store"`
+	Defaults             DefaultsConfig             `yaml:"defaults"`
 	DexPairAlter         DexPairAlterConfig         `yaml:"dex_pair_alter"`
 	TokenPriceMonitor    TokenPriceMonitorConfig    `yaml:"token_price_monitor"`
 	BinanceCex           BinanceCexConfig           `yaml:"binance-cex"`
@@ -28,6 +35,7 @@ type Config struct {
 	TwitterMonitor       TwitterMonitorConfig       `yaml:"twitter_monitor"`
 	GeneralMonitor       GeneralMonitorConfig       `yaml:"general_monitor"`
 	BtcDashboardMonitor  BtcDashboardMonitorConfig  `yaml:"btc_dashboard_monitor"`
+	RuleEvaluator        RuleEvaluatorConfig        `yaml:"rule_evaluator"`
 	Log                  LogConfig                  `yaml:"log"`
 	WebStaticDir         string                     `yaml:"web_static_dir"`
 }
@@ -37,7 +45,9 @@ type LogConfig struct {
 }
 
 type ServerConfig struct {
-	Port string `yaml:"port"`
+	Port                   string `yaml:"port"`
+	ShutdownTimeoutSeconds int    `yaml:"shutdown_timeout_seconds"` // time to drain tasks and notifications on exit
+	AdminToken             string `yaml:"admin_token"`              // bearer token for the endpoints that change state, they are disabled when empty
 }
 
 type CoinMarketCapConfig struct {
@@ -49,37 +59,142 @@ type OpenSeaConfig struct {
 }
 
 type DingTalkConfig struct {
-	AccessToken string `yaml:"access_token"`
+	AccessToken        string `yaml:"access_token"`
+	Secret             string `yaml:"secret"`
+	RateLimitPerMinute int    `yaml:"rate_limit_per_minute"` // send queue pacing, defaults to the robot limit of 20
+	QueueSize          int    `yaml:"queue_size"`            // messages buffered per priority lane, defaults to 100
+	Keyword            string
+}
+
+type TelegramConfig struct {
+	BotToken string `yaml:"bot_token"`
+	ChatID   string `yaml:"chat_id"`
+	Keyword  string
+}
+
+type FeishuConfig struct {
+	AccessToken string `yaml:"access_token"` // webhook token, the last path segment of the hook url
 	Secret      string `yaml:"secret"`
+	BaseURL     string `yaml:"base_url"` // optional, e.g. https://open.larksuite.com/open-apis/bot/v2/hook for Lark
 	Keyword     string
 }
 
+type EmailConfig struct {
+	Host      string   `yaml:"host"`
+	Port      int      `yaml:"port"` // 465 uses implicit TLS, 587/25 use STARTTLS when offered
+	Username  string   `yaml:"username"`
+	Password  string   `yaml:"password"`
+	From      string   `yaml:"from"`
+	ToStr     string   `yaml:"to"` // comma separated recipients
+	To        []string `yaml:"-"`
+	Digest    string   `yaml:"digest"`     // "hourly" (default) or "daily"
+	DailyHour int      `yaml:"daily_hour"` // hour (UTC+8) of the daily digest
+	Keyword   string
+}
+
+type WebhookConfig struct {
+	URL      string            `yaml:"url"`
+	Secret   string            `yaml:"secret"`   // optional, signs the body with HMAC-SHA256
+	Template string            `yaml:"template"` // optional text/template for the body, defaults to the JSON event
+	Headers  map[string]string `yaml:"headers"`
+	Keyword  string
+}
+
+type OutboxConfig struct {
+	Path        string `yaml:"path"`         // defaults to ./data/outbox.jsonl
+	MaxAttempts int    `yaml:"max_attempts"` // automatic delivery attempts before a message is marked failed
+}
+
+// MetricStoreConfig is the embedded store keeping the history of every collected metric.
+type MetricStoreConfig struct {
+	Enabled          *bool                `yaml:"enabled"`            // defaults to true
+	Path             string               `yaml:"path"`               // defaults to ./data/metrics.db
+	RawRetentionDays int                  `yaml:"raw_retention_days"` // defaults to 30, negative keeps raw samples forever
+	Rollups          []MetricRollupConfig `yaml:"rollups"`            // defaults to 1h kept 365 days and 1d kept forever
+}
+
+type MetricRollupConfig struct {
+	Resolution    string `yaml:"resolution"`     // "5m", "1h", "1d"
+	RetentionDays int    `yaml:"retention_days"` // 0 keeps the buckets forever
+}
+
 type DexPairAlterConfig struct {
 	ContractAddrs   []string `yaml:"contract_addrs"`
 	IntervalSeconds int      `yaml:"interval_seconds"`
+	Schedule        string   `yaml:"schedule"` // optional cron expression like "0 0 9,21 * * *", overrides interval_seconds
 	BotName         string   `yaml:"bot_name"`
 	// key: networkId, value: contractAddrs
 	ContractAddrInfo map[string][]string
+	Drain            DexDrainConfig    `yaml:"drain"`
+	Summary          *bool             `yaml:"summary"` // periodic price/liquidity report, defaults to true
 	QuietHours       *QuietHoursConfig `yaml:"quiet_hours"`
+	MinSeverity      string            `yaml:"min_severity"`
+}
+
+// DexDrainConfig alerts on signs of a rug pull by comparing each pair with its own recent history.
+// Zero values disable a check.
+type DexDrainConfig struct {
+	LiquidityDropPercent float64 `yaml:"liquidity_drop_percent"` // drop from the highest liquidity within the window
+	PriceDropPercent     float64 `yaml:"price_drop_percent"`     // drop from the highest price within the window
+	WindowMinutes        int     `yaml:"window_minutes"`         // defaults to 60
+	MissingRuns          int     `yaml:"missing_runs"`           // consecutive runs CMC does not return the pair
+	Severity             string  `yaml:"severity"`               // defaults to critical
+	CooldownMinutes      int     `yaml:"cooldown_minutes"`
 }
 
 type TokenPriceMonitorConfig struct {
-	TokenIds        string            `yaml:"token_ids"`
-	TokenIDs        []string          `yaml:"-"`
-	RwaTokenIds     string            `yaml:"rwa_token_ids"`
-	RwaTokenIDs     []string          `yaml:"-"`
-	RwaTokenNames   map[string]string `yaml:"rwa_token_names"`
-	IntervalSeconds int               `yaml:"interval_seconds"`
-	BotName         string            `yaml:"bot_name"`
-	QuietHours      *QuietHoursConfig `yaml:"quiet_hours"`
+	TokenIds        string                 `yaml:"token_ids"`
+	TokenIDs        []string               `yaml:"-"`
+	RwaTokenIds     string                 `yaml:"rwa_token_ids"`
+	RwaTokenIDs     []string               `yaml:"-"`
+	RwaTokenNames   map[string]string      `yaml:"rwa_token_names"`
+	Rules           []TokenAlertRuleConfig `yaml:"rules"`
+	Summary         *bool                  `yaml:"summary"`         // periodic price summary, defaults to true without rules and false with rules
+	NotifyResolved  *bool                  `yaml:"notify_resolved"` // send a notice when a fired rule clears, defaults to true
+	HistoryPath     string                 `yaml:"history_path"`    // daily highs/lows for high_low_days rules, defaults to ./data/token_price_history.json
+	IntervalSeconds int                    `yaml:"interval_seconds"`
+	Schedule        string                 `yaml:"schedule"`
+	BotName         string                 `yaml:"bot_name"`
+	QuietHours      *QuietHoursConfig      `yaml:"quiet_hours"`
+	MinSeverity     string                 `yaml:"min_severity"`
+}
+
+// TokenAlertRuleConfig alerts when a token's price crosses a level or moves sharply.
+// Zero values disable a condition.
+type TokenAlertRuleConfig struct {
+	TokenID           string  `yaml:"token_id"` // CoinMarketCap id, does not need to be listed in token_ids
+	Above             float64 `yaml:"above"`
+	Below             float64 `yaml:"below"`
+	Change1h          float64 `yaml:"change_1h"`          // percent, either direction
+	Change24h         float64 `yaml:"change_24h"`         // percent, either direction
+	HighLowDays       int     `yaml:"high_low_days"`      // alert on a new N-day high or low
+	Severity          string  `yaml:"severity"`           // defaults to warning
+	CooldownMinutes   int     `yaml:"cooldown_minutes"`   // a condition firing again within this time of its last alert stays silent
+	HysteresisPercent float64 `yaml:"hysteresis_percent"` // percent of the level the value must move back before the condition resolves
 }
 
 type NFTFloorPriceMonitorConfig struct {
-	IntervalSeconds   int               `yaml:"interval_seconds"`
-	BotName           string            `yaml:"bot_name"`
-	NFTCollectionsStr string            `yaml:"nft_collections"`
-	NFTCollections    []string          `yaml:"-"`
-	QuietHours        *QuietHoursConfig `yaml:"quiet_hours"`
+	IntervalSeconds   int                   `yaml:"interval_seconds"`
+	Schedule          string                `yaml:"schedule"`
+	BotName           string                `yaml:"bot_name"`
+	NFTCollectionsStr string                `yaml:"nft_collections"`
+	NFTCollections    []string              `yaml:"-"`
+	Alerts            []NFTFloorAlertConfig `yaml:"alerts"`
+	Summary           *bool                 `yaml:"summary"`      // periodic floor report, defaults to true without alerts and false with alerts
+	HistoryPath       string                `yaml:"history_path"` // floor history for the alert windows, defaults to ./data/nft_floor_history.json
+	QuietHours        *QuietHoursConfig     `yaml:"quiet_hours"`
+	MinSeverity       string                `yaml:"min_severity"`
+}
+
+// NFTFloorAlertConfig alerts when a floor moves by a percentage over a window, measured against
+// the floor at the start of the window. Zero percentages disable that currency.
+type NFTFloorAlertConfig struct {
+	Collection      string  `yaml:"collection"`     // slug, empty applies to every collection
+	Window          string  `yaml:"window"`         // e.g. "1h", "24h", "7d"
+	NativePercent   float64 `yaml:"native_percent"` // move in the collection's own token (ETH, ...)
+	UsdPercent      float64 `yaml:"usd_percent"`
+	Severity        string  `yaml:"severity"` // defaults to warning
+	CooldownMinutes int     `yaml:"cooldown_minutes"`
 }
 
 type BinanceCexConfig struct {
@@ -100,7 +215,10 @@ type PolymarketReportConfig struct {
 	AddressListFile string            `yaml:"address_list_file"`
 	OutputDir       string            `yaml:"output_dir"`
 	IntervalSeconds int               `yaml:"interval_seconds"`
+	Schedule        string            `yaml:"schedule"`
+	BotName         string            `yaml:"bot_name"` // optional, also send the report table to this bot
 	QuietHours      *QuietHoursConfig `yaml:"quiet_hours"`
+	MinSeverity     string            `yaml:"min_severity"`
 }
 
 type TwitterConfig struct {
@@ -108,42 +226,86 @@ type TwitterConfig struct {
 }
 
 type PolymarketMonitorConfig struct {
-	IntervalSeconds int               `yaml:"interval_seconds"`
-	BotName         string            `yaml:"bot_name"`
-	MarketIDsStr    string            `yaml:"market_ids"`
-	MarketIDs       []string          `yaml:"-"`
-	QuietHours      *QuietHoursConfig `yaml:"quiet_hours"`
+	IntervalSeconds int                    `yaml:"interval_seconds"`
+	Schedule        string                 `yaml:"schedule"`
+	BotName         string                 `yaml:"bot_name"`
+	MarketIDsStr    string                 `yaml:"market_ids"`
+	MarketIDs       []string               `yaml:"-"`
+	Rules           []PolymarketRuleConfig `yaml:"rules"`
+	Summary         *bool                  `yaml:"summary"`       // periodic report of every market, defaults to true without rules and false with rules
+	NotifyClosed    *bool                  `yaml:"notify_closed"` // notice with the final outcome when a market closes, defaults to true
+	StatePath       string                 `yaml:"state_path"`    // defaults to ./data/polymarket_monitor_state.json
+	QuietHours      *QuietHoursConfig      `yaml:"quiet_hours"`
+	MinSeverity     string                 `yaml:"min_severity"`
+}
+
+// PolymarketRuleConfig alerts on probability swings of a market outcome. Levels are
+// probabilities (0-1), moves are percentage points. Zero values disable a condition.
+type PolymarketRuleConfig struct {
+	MarketID        string  `yaml:"market_id"` // empty applies to every tracked market
+	Outcome         string  `yaml:"outcome"`   // defaults to the first outcome, usually "Yes"
+	Above           float64 `yaml:"above"`
+	Below           float64 `yaml:"below"`
+	MovePoints      float64 `yaml:"move_points"` // move since the previous move alert
+	Change1h        float64 `yaml:"change_1h"`   // points, reported by Polymarket for the first outcome
+	Change24h       float64 `yaml:"change_24h"`
+	Severity        string  `yaml:"severity"` // defaults to warning
+	CooldownMinutes int     `yaml:"cooldown_minutes"`
 }
 
 type TwitterMonitorConfig struct {
 	IntervalSeconds int                 `yaml:"interval_seconds"`
+	Schedule        string              `yaml:"schedule"`
 	BotName         string              `yaml:"bot_name"`
 	UsernamesStr    string              `yaml:"usernames"`
 	Usernames       []string            `yaml:"-"`
 	KeywordsStr     map[string]string   `yaml:"keywords"`
 	Keywords        map[string][]string `yaml:"-"`
 	WithinTime      string              `yaml:"within_time"`
+	StatePath       string              `yaml:"state_path"` // last seen and sent tweet IDs per user, defaults to ./data/twitter_monitor_state.json
 	QuietHours      *QuietHoursConfig   `yaml:"quiet_hours"`
+	MinSeverity     string              `yaml:"min_severity"`
 }
 
 type QuietHoursConfig struct {
-	Enabled            bool   `yaml:"enabled"`
-	StartHour          int    `yaml:"start_hour"`
-	EndHour            int    `yaml:"end_hour"`
+	Enabled            *bool               `yaml:"enabled"` // pointers tell an unset field from false/0 when merging with defaults
+	StartHour          *int                `yaml:"start_hour"`
+	EndHour            *int                `yaml:"end_hour"`
+	Behavior           string              `yaml:"behavior"`
+	ThrottleMultiplier *int                `yaml:"throttle_multiplier"`
+	Timezone           string              `yaml:"timezone"` // IANA name such as "Europe/Berlin", defaults to Beijing time
+	Windows            []QuietWindowConfig `yaml:"windows"`  // replaces start_hour/end_hour when set
+	Holidays           []string            `yaml:"holidays"` // "2006-01-02" dates that are quiet all day
+}
+
+// DefaultsConfig holds the settings every monitor task inherits unless its own block sets them.
+type DefaultsConfig struct {
+	BotName     string            `yaml:"bot_name"`
+	QuietHours  *QuietHoursConfig `yaml:"quiet_hours"`  // merged field by field with the task's quiet_hours
+	MinSeverity string            `yaml:"min_severity"` // "info", "warning" or "critical", lower events are dropped
+}
+
+type QuietWindowConfig struct {
+	Start              string `yaml:"start"` // "22:30"
+	End                string `yaml:"end"`   // "07:00", before start when the window crosses midnight
+	Days               string `yaml:"days"`  // "weekdays", "weekends", "mon-fri,sun"; empty for every day
 	Behavior           string `yaml:"behavior"`
 	ThrottleMultiplier int    `yaml:"throttle_multiplier"`
 }
 
 type GeneralMonitorConfig struct {
 	IntervalSeconds int               `yaml:"interval_seconds"`
+	Schedule        string            `yaml:"schedule"`
 	BotName         string            `yaml:"bot_name"`
 	ModulesStr      string            `yaml:"modules"`
 	Modules         []string          `yaml:"-"`
 	QuietHours      *QuietHoursConfig `yaml:"quiet_hours"`
+	MinSeverity     string            `yaml:"min_severity"`
 }
 
 type BtcDashboardMonitorConfig struct {
 	IntervalSeconds    int               `yaml:"interval_seconds"`
+	Schedule           string            `yaml:"schedule"`
 	BotName            string            `yaml:"bot_name"`
 	BinanceApiUrl      string            `yaml:"binance_api_url"`
 	MempoolApiUrl      string            `yaml:"mempool_api_url"`
@@ -151,7 +313,31 @@ type BtcDashboardMonitorConfig struct {
 	BgeometricsApiKey  string            `yaml:"bgeometrics_api_key"`
 	BgeometricsApiUrl  string            `yaml:"bgeometrics_api_url"`
 	BgeometricsTimeout int               `yaml:"bgeometrics_timeout"` // 超时秒数，默认 10
+	ZoneAlerts         *bool             `yaml:"zone_alerts"`         // alert when an indicator enters a new zone, defaults to true
+	Summary            *bool             `yaml:"summary"`             // periodic full report, defaults to true
+	StatePath          string            `yaml:"state_path"`          // defaults to ./data/btc_zone_state.json
 	QuietHours         *QuietHoursConfig `yaml:"quiet_hours"`
+	MinSeverity        string            `yaml:"min_severity"`
+}
+
+// RuleEvaluatorConfig evaluates expression rules over the shared metric namespace,
+// e.g. `btc.ahr999 < 0.45 && fgi.value < 20` or `polymarket["12345"].yes > 0.8`.
+type RuleEvaluatorConfig struct {
+	IntervalSeconds int                    `yaml:"interval_seconds"`
+	Schedule        string                 `yaml:"schedule"`
+	BotName         string                 `yaml:"bot_name"`
+	Rules           []ExpressionRuleConfig `yaml:"rules"`
+	NotifyResolved  *bool                  `yaml:"notify_resolved"` // defaults to true
+	QuietHours      *QuietHoursConfig      `yaml:"quiet_hours"`
+	MinSeverity     string                 `yaml:"min_severity"`
+}
+
+type ExpressionRuleConfig struct {
+	Name            string `yaml:"name"`
+	Expr            string `yaml:"expr"`
+	Message         string `yaml:"message"`          // optional text added to the alert
+	Severity        string `yaml:"severity"`         // defaults to warning
+	CooldownMinutes int    `yaml:"cooldown_minutes"` // a rule firing again within this time of its last alert stays silent
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -274,6 +460,29 @@ func LoadConfig(path string) (*Config, error) {
 		v.Keyword = botName
 		cfg.DingTalk[botName] = v
 	}
+	for botName, v := range cfg.Telegram {
+		v.Keyword = botName
+		cfg.Telegram[botName] = v
+	}
+	for botName, v := range cfg.Feishu {
+		v.Keyword = botName
+		cfg.Feishu[botName] = v
+	}
+	for botName, v := range cfg.Webhook {
+		v.Keyword = botName
+		cfg.Webhook[botName] = v
+	}
+	for botName, v := range cfg.Email {
+		v.Keyword = botName
+		for _, p := range strings.Split(v.ToStr, ",") {
+			if trimmed := strings.TrimSpace(p); trimmed != "" {
+				v.To = append(v.To, trimmed)
+			}
+		}
+		cfg.Email[botName] = v
+	}
+
+	cfg.applyDefaults()
 
 	// Resolve relative paths in PolymarketReport to be relative to project root.
 	// Config file is at <project_root>/config/config.yaml, so project root = parent of config dir.
@@ -285,6 +494,54 @@ func LoadConfig(path string) (*Config, error) {
 	if cfg.PolymarketReport.OutputDir != "" && !filepath.IsAbs(cfg.PolymarketReport.OutputDir) {
 		cfg.PolymarketReport.OutputDir = filepath.Join(projectRoot, cfg.PolymarketReport.OutputDir)
 	}
+	if cfg.TokenPriceMonitor.HistoryPath == "" {
+		cfg.TokenPriceMonitor.HistoryPath = "./data/token_price_history.json"
+	}
+	if !filepath.IsAbs(cfg.TokenPriceMonitor.HistoryPath) {
+		cfg.TokenPriceMonitor.HistoryPath = filepath.Join(projectRoot, cfg.TokenPriceMonitor.HistoryPath)
+	}
+	if cfg.NFTFloorPriceMonitor.HistoryPath == "" {
+		cfg.NFTFloorPriceMonitor.HistoryPath = "./data/nft_floor_history.json"
+	}
+	if !filepath.IsAbs(cfg.NFTFloorPriceMonitor.HistoryPath) {
+		cfg.NFTFloorPriceMonitor.HistoryPath = filepath.Join(projectRoot, cfg.NFTFloorPriceMonitor.HistoryPath)
+	}
+	if cfg.PolymarketMonitor.StatePath == "" {
+		cfg.PolymarketMonitor.StatePath = "./data/polymarket_monitor_state.json"
+	}
+	if !filepath.IsAbs(cfg.PolymarketMonitor.StatePath) {
+		cfg.PolymarketMonitor.StatePath = filepath.Join(projectRoot, cfg.PolymarketMonitor.StatePath)
+	}
+	if cfg.BtcDashboardMonitor.StatePath == "" {
+		cfg.BtcDashboardMonitor.StatePath = "./data/btc_zone_state.json"
+	}
+	if !filepath.IsAbs(cfg.BtcDashboardMonitor.StatePath) {
+		cfg.BtcDashboardMonitor.StatePath = filepath.Join(projectRoot, cfg.BtcDashboardMonitor.StatePath)
+	}
+	if cfg.TwitterMonitor.StatePath == "" {
+		cfg.TwitterMonitor.StatePath = "./data/twitter_monitor_state.json"
+	}
+	if !filepath.IsAbs(cfg.TwitterMonitor.StatePath) {
+		cfg.TwitterMonitor.StatePath = filepath.Join(projectRoot, cfg.TwitterMonitor.StatePath)
+	}
+	if cfg.MetricStore.Path == "" {
+		cfg.MetricStore.Path = "./data/metrics.db"
+	}
+	if !filepath.IsAbs(cfg.MetricStore.Path) {
+		cfg.MetricStore.Path = filepath.Join(projectRoot, cfg.MetricStore.Path)
+	}
+	if cfg.MetricStore.RawRetentionDays == 0 {
+		cfg.MetricStore.RawRetentionDays = 30
+	}
+	if len(cfg.MetricStore.Rollups) == 0 {
+		cfg.MetricStore.Rollups = []MetricRollupConfig{{Resolution: "1h", RetentionDays: 365}, {Resolution: "1d"}}
+	}
+	if cfg.Outbox.Path == "" {
+		cfg.Outbox.Path = "./data/outbox.jsonl"
+	}
+	if !filepath.IsAbs(cfg.Outbox.Path) {
+		cfg.Outbox.Path = filepath.Join(projectRoot, cfg.Outbox.Path)
+	}
 	if cfg.WebStaticDir == "" {
 		cfg.WebStaticDir = "./web/static"
 	}
@@ -304,3 +561,74 @@ func GetConfigTempPath() string {
 	_, filename, _, _ := runtime.Caller(0)
 	return filepath.Join(filepath.Dir(filename), "config.yaml.temp")
 }
+
+// applyDefaults fills unset task settings from the top-level defaults section.
+// The polymarket report bot is optional, so it does not inherit the default bot.
+func (cfg *Config) applyDefaults() {
+	d := cfg.Defaults
+	tasks := []struct {
+		botName     *string
+		quietHours  **QuietHoursConfig
+		minSeverity *string
+	}{
+		{&cfg.DexPairAlter.BotName, &cfg.DexPairAlter.QuietHours, &cfg.DexPairAlter.MinSeverity},
+		{&cfg.TokenPriceMonitor.BotName, &cfg.TokenPriceMonitor.QuietHours, &cfg.TokenPriceMonitor.MinSeverity},
+		{&cfg.NFTFloorPriceMonitor.BotName, &cfg.NFTFloorPriceMonitor.QuietHours, &cfg.NFTFloorPriceMonitor.MinSeverity},
+		{&cfg.PolymarketMonitor.BotName, &cfg.PolymarketMonitor.QuietHours, &cfg.PolymarketMonitor.MinSeverity},
+		{&cfg.TwitterMonitor.BotName, &cfg.TwitterMonitor.QuietHours, &cfg.TwitterMonitor.MinSeverity},
+		{&cfg.GeneralMonitor.BotName, &cfg.GeneralMonitor.QuietHours, &cfg.GeneralMonitor.MinSeverity},
+		{&cfg.BtcDashboardMonitor.BotName, &cfg.BtcDashboardMonitor.QuietHours, &cfg.BtcDashboardMonitor.MinSeverity},
+		{&cfg.RuleEvaluator.BotName, &cfg.RuleEvaluator.QuietHours, &cfg.RuleEvaluator.MinSeverity},
+		{nil, &cfg.PolymarketReport.QuietHours, &cfg.PolymarketReport.MinSeverity},
+	}
+	for _, t := range tasks {
+		if t.botName != nil && *t.botName == "" {
+			*t.botName = d.BotName
+		}
+		if *t.minSeverity == "" {
+			*t.minSeverity = d.MinSeverity
+		}
+		*t.quietHours = MergeQuietHours(d.QuietHours, *t.quietHours)
+	}
+}
+
+// MergeQuietHours returns base with every field set in override applied on top.
+// Windows and holidays set in override replace the base lists, and an override that sets
+// start_hour or end_hour without windows drops the base windows. Either argument may be nil.
+func MergeQuietHours(base, override *QuietHoursConfig) *QuietHoursConfig {
+	if base == nil {
+		return override
+	}
+	merged := *base
+	if override == nil {
+		return &merged
+	}
+
+	if override.Enabled != nil {
+		merged.Enabled = override.Enabled
+	}
+	if override.StartHour != nil {
+		merged.StartHour = override.StartHour
+	}
+	if override.EndHour != nil {
+		merged.EndHour = override.EndHour
+	}
+	if override.Behavior != "" {
+		merged.Behavior = override.Behavior
+	}
+	if override.ThrottleMultiplier != nil {
+		merged.ThrottleMultiplier = override.ThrottleMultiplier
+	}
+	if override.Timezone != "" {
+		merged.Timezone = override.Timezone
+	}
+	if len(override.Windows) > 0 {
+		merged.Windows = override.Windows
+	} else if override.StartHour != nil || override.EndHour != nil {
+		merged.Windows = nil
+	}
+	if len(override.Holidays) > 0 {
+		merged.Holidays = override.Holidays
+	}
+	return &merged
+}
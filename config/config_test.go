@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfig_Defaults(t *testing.T) {
+	yaml := `
+defaults:
+    bot_name: "team"
+    min_severity: "warning"
+    quiet_hours:
+        enabled: true
+        start_hour: 23
+        end_hour: 7
+        behavior: "pause"
+        timezone: "Europe/Berlin"
+token_price_monitor:
+    interval_seconds: 60
+    quiet_hours:
+        behavior: "throttle"
+        throttle_multiplier: 5
+twitter_monitor:
+    bot_name: "x"
+    min_severity: "info"
+    quiet_hours:
+        enabled: false
+polymarket_report:
+    interval_seconds: 60
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	token := cfg.TokenPriceMonitor
+	if token.BotName != "team" || token.MinSeverity != "warning" {
+		t.Errorf("expected inherited bot and severity, got %q %q", token.BotName, token.MinSeverity)
+	}
+	qh := token.QuietHours
+	if qh == nil || !*qh.Enabled || *qh.StartHour != 23 || *qh.EndHour != 7 || qh.Timezone != "Europe/Berlin" {
+		t.Fatalf("expected default quiet hours to be inherited, got %+v", qh)
+	}
+	if qh.Behavior != "throttle" || *qh.ThrottleMultiplier != 5 {
+		t.Errorf("expected the task override to apply, got %+v", qh)
+	}
+
+	twitter := cfg.TwitterMonitor
+	if twitter.BotName != "x" || twitter.MinSeverity != "info" || *twitter.QuietHours.Enabled {
+		t.Errorf("expected task settings to win, got %+v", twitter)
+	}
+	if *twitter.QuietHours.StartHour != 23 {
+		t.Errorf("expected unset fields to be inherited, got %+v", twitter.QuietHours)
+	}
+
+	if cfg.PolymarketReport.BotName != "" {
+		t.Errorf("expected the report bot not to inherit the default, got %q", cfg.PolymarketReport.BotName)
+	}
+	if cfg.NFTFloorPriceMonitor.QuietHours == cfg.Defaults.QuietHours || cfg.NFTFloorPriceMonitor.QuietHours == cfg.BtcDashboardMonitor.QuietHours {
+		t.Errorf("expected every task to get its own copy of the default quiet hours")
+	}
+}
+
+func TestMergeQuietHours(t *testing.T) {
+	hour := func(h int) *int { return &h }
+	base := &QuietHoursConfig{
+		StartHour:          hour(23),
+		EndHour:            hour(7),
+		ThrottleMultiplier: hour(5),
+		Windows:            []QuietWindowConfig{{Start: "22:00", End: "08:00"}},
+	}
+
+	merged := MergeQuietHours(base, &QuietHoursConfig{StartHour: hour(1), ThrottleMultiplier: hour(0)})
+	if len(merged.Windows) != 0 {
+		t.Errorf("expected the task hours to replace the default windows, got %+v", merged.Windows)
+	}
+	if *merged.StartHour != 1 || *merged.EndHour != 7 || *merged.ThrottleMultiplier != 0 {
+		t.Errorf("unexpected merge %+v", merged)
+	}
+
+	merged = MergeQuietHours(base, &QuietHoursConfig{Behavior: "mute"})
+	if len(merged.Windows) != 1 || *merged.ThrottleMultiplier != 5 {
+		t.Errorf("expected the default windows and multiplier to be kept, got %+v", merged)
+	}
+	if len(base.Windows) != 1 {
+		t.Errorf("expected the defaults to be left untouched, got %+v", base)
+	}
+}
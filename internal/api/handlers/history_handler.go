@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ka1fe1/crypto-monitoring/internal/service"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+)
+
+const defaultHistoryRange = 24 * time.Hour
+
+type HistoryHandler struct {
+	service service.HistoryService
+}
+
+// NewHistoryHandler creates the handler, service is nil when the metric store is not enabled.
+func NewHistoryHandler(service service.HistoryService) *HistoryHandler {
+	return &HistoryHandler{
+		service: service,
+	}
+}
+
+// GetTokenHistory godoc
+// @Summary      Token price history
+// @Description  Stored quotes of a token (price, change_1h, change_24h)
+// @Tags         history
+// @Produce      json,text/csv
+// @Param        id           path   string  true   "Token ID"
+// @Param        from         query  string  false  "Start, RFC3339, date or Unix seconds (default: 24h before to)"
+// @Param        to           query  string  false  "End, exclusive (default: now)"
+// @Param        resolution   query  string  false  "Bucket size like 5m, 1h or 1d, raw samples when empty"
+// @Param        aggregation  query  string  false  "last, avg or ohlc (default: last)"
+// @Param        fields       query  string  false  "Fields (comma separated)"
+// @Param        format       query  string  false  "json or csv"
+// @Success      200  {object}  service.HistorySeries
+// @Failure      400  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Router       /api/v1/history/token/{id} [get]
+func (h *HistoryHandler) GetTokenHistory(c *gin.Context) {
+	h.query(c, service.TokenSeries(c.Param("id")))
+}
+
+// GetDexPairHistory godoc
+// @Summary      DEX pair history
+// @Description  Stored quotes of a DEX pair (price, liquidity, change_1h); takes the same query parameters as the token history
+// @Tags         history
+// @Produce      json,text/csv
+// @Param        network  path  string  true  "Network ID"
+// @Param        address  path  string  true  "Pair contract address"
+// @Success      200  {object}  service.HistorySeries
+// @Router       /api/v1/history/dex/{network}/{address} [get]
+func (h *HistoryHandler) GetDexPairHistory(c *gin.Context) {
+	h.query(c, service.DexPairSeries(c.Param("network"), c.Param("address")))
+}
+
+// GetNFTHistory godoc
+// @Summary      NFT floor price history
+// @Description  Stored floor prices of a collection (floor, floor_usd); takes the same query parameters as the token history
+// @Tags         history
+// @Produce      json,text/csv
+// @Param        slug  path  string  true  "Collection slug"
+// @Success      200  {object}  service.HistorySeries
+// @Router       /api/v1/history/nft/{slug} [get]
+func (h *HistoryHandler) GetNFTHistory(c *gin.Context) {
+	h.query(c, service.NFTSeries(c.Param("slug")))
+}
+
+// GetPolymarketHistory godoc
+// @Summary      Polymarket market history
+// @Description  Stored outcome prices of a market (one field per lower-case outcome, volume, change_1h, change_24h, change_1w, closed); takes the same query parameters as the token history
+// @Tags         history
+// @Produce      json,text/csv
+// @Param        marketId  path  string  true  "Market ID"
+// @Success      200  {object}  service.HistorySeries
+// @Router       /api/v1/history/polymarket/{marketId} [get]
+func (h *HistoryHandler) GetPolymarketHistory(c *gin.Context) {
+	h.query(c, service.PolymarketSeries(c.Param("marketId")))
+}
+
+// GetBtcDashboardHistory godoc
+// @Summary      BTC dashboard history
+// @Description  Stored BTC cycle metrics (price, wma200, wma_ratio, ahr999, balanced_price, bp_ratio, fgi, halving_days); takes the same query parameters as the token history
+// @Tags         history
+// @Produce      json,text/csv
+// @Success      200  {object}  service.HistorySeries
+// @Router       /api/v1/history/btc-dashboard [get]
+func (h *HistoryHandler) GetBtcDashboardHistory(c *gin.Context) {
+	h.query(c, service.SERIES_BTC_DASHBOARD)
+}
+
+func (h *HistoryHandler) query(c *gin.Context, series string) {
+	if h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "metric store is not enabled"})
+		return
+	}
+
+	q, err := parseHistoryQuery(c, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	result, err := h.service.Query(series, q)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if strings.EqualFold(c.Query("format"), "csv") || (c.Query("format") == "" && strings.Contains(c.GetHeader("Accept"), "text/csv")) {
+		writeHistoryCSV(c, result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func parseHistoryQuery(c *gin.Context, now time.Time) (service.HistoryQuery, error) {
+	q := service.HistoryQuery{To: now, Aggregation: c.Query("aggregation")}
+	if s := c.Query("to"); s != "" {
+		t, err := parseHistoryTime(s)
+		if err != nil {
+			return q, err
+		}
+		q.To = t
+	}
+	q.From = q.To.Add(-defaultHistoryRange)
+	if s := c.Query("from"); s != "" {
+		t, err := parseHistoryTime(s)
+		if err != nil {
+			return q, err
+		}
+		q.From = t
+	}
+	if s := c.Query("resolution"); s != "" {
+		d, err := utils.ParseWindow(s)
+		if err != nil {
+			return q, err
+		}
+		q.Resolution = d
+	}
+	if s := c.Query("fields"); s != "" {
+		for _, f := range strings.Split(s, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				q.Fields = append(q.Fields, f)
+			}
+		}
+	}
+	return q, nil
+}
+
+// parseHistoryTime accepts RFC3339, a date (UTC midnight) or Unix seconds.
+func parseHistoryTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, use RFC3339, YYYY-MM-DD or Unix seconds", s)
+}
+
+// writeHistoryCSV writes one row per point, columns missing from a point are left empty.
+func writeHistoryCSV(c *gin.Context, result *service.HistorySeries) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", strings.ReplaceAll(result.Series, "/", "_")+".csv"))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write(append([]string{"time"}, result.Columns...))
+	for _, p := range result.Points {
+		row := []string{p.Time.UTC().Format(time.RFC3339)}
+		for _, column := range result.Columns {
+			v, ok := p.Values[column]
+			if !ok {
+				row = append(row, "")
+				continue
+			}
+			row = append(row, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+		w.Write(row)
+	}
+	w.Flush()
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter/outbox"
+)
+
+type OutboxHandler struct {
+	outbox *outbox.Outbox
+}
+
+func NewOutboxHandler(box *outbox.Outbox) *OutboxHandler {
+	return &OutboxHandler{
+		outbox: box,
+	}
+}
+
+// ListMessages godoc
+// @Summary      List outbox messages
+// @Description  List outgoing notifications by delivery status, undelivered ones (pending, retrying, failed) by default
+// @Tags         outbox
+// @Produce      json
+// @Param        status  query     string  false  "pending, retrying, failed or sent"
+// @Success      200  {array}   outbox.Record
+// @Failure      503  {object}  map[string]string
+// @Router       /api/v1/outbox [get]
+func (h *OutboxHandler) ListMessages(c *gin.Context) {
+	if h.outbox == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "outbox is not enabled"})
+		return
+	}
+
+	records := h.outbox.List(c.Query("status"))
+	if records == nil {
+		records = []outbox.Record{}
+	}
+	c.JSON(http.StatusOK, records)
+}
+
+// ResendMessage godoc
+// @Summary      Re-send an outbox message
+// @Description  Deliver an undelivered notification again, also after automatic retries gave up
+// @Tags         outbox
+// @Produce      json
+// @Param        id   path      string  true  "Message ID"
+// @Success      202  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Router       /api/v1/outbox/{id}/resend [post]
+func (h *OutboxHandler) ResendMessage(c *gin.Context) {
+	if h.outbox == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "outbox is not enabled"})
+		return
+	}
+
+	id := c.Param("id")
+	if err := h.outbox.Resend(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"id": id, "status": "resending"})
+}
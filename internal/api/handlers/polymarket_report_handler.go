@@ -8,27 +8,38 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ka1fe1/crypto-monitoring/config"
+	"github.com/ka1fe1/crypto-monitoring/internal/service"
+	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/markdown"
 )
 
 // PolymarketReportHandler handles requests for Polymarket daily report data.
 type PolymarketReportHandler struct {
-	cfg *config.Config
+	cfg   *config.Config
+	store *service.PolymarketReportStore
 }
 
 // NewPolymarketReportHandler creates a new handler instance.
 func NewPolymarketReportHandler(cfg *config.Config) *PolymarketReportHandler {
-	return &PolymarketReportHandler{cfg: cfg}
+	return &PolymarketReportHandler{
+		cfg:   cfg,
+		store: service.NewPolymarketReportStore(cfg.PolymarketReport.OutputDir),
+	}
 }
 
 // ReportResponse is the JSON response for the report API.
 type ReportResponse struct {
 	Filename    string     `json:"filename"`
+	Timestamp   string     `json:"timestamp"`
 	GeneratedAt string     `json:"generatedAt"`
 	Headers     []string   `json:"headers"`
 	Rows        [][]string `json:"rows"`
+	// Snapshot is the structured form of the run, nil for runs from before snapshots were written.
+	Snapshot *service.PolymarketReportSnapshot `json:"snapshot,omitempty"`
 }
 
 // GetLatestReport reads the latest report file and returns parsed table data as JSON.
@@ -46,6 +57,93 @@ func (h *PolymarketReportHandler) GetLatestReport(c *gin.Context) {
 		return
 	}
 
+	h.writeReport(c, filename)
+}
+
+// ListReports returns every report run, newest first.
+func (h *PolymarketReportHandler) ListReports(c *gin.Context) {
+	if h.cfg.PolymarketReport.OutputDir == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "OutputDir not configured"})
+		return
+	}
+
+	runs, err := h.store.ListRuns()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to list reports: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+// GetReport returns the report of the run at the timestamp in the path, formatted like its
+// file name (20060102_1504), in the same form as GetLatestReport.
+func (h *PolymarketReportHandler) GetReport(c *gin.Context) {
+	if h.cfg.PolymarketReport.OutputDir == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "OutputDir not configured"})
+		return
+	}
+
+	timestamp := c.Param("timestamp")
+	if _, err := markdown.ParseReportTimestamp(timestamp); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid report timestamp %q, use YYYYMMDD_HHMM", timestamp)})
+		return
+	}
+	filename := markdown.REPORT_FILE_PREFIX + timestamp + ".md"
+	if _, err := os.Stat(filepath.Join(h.cfg.PolymarketReport.OutputDir, filename)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		return
+	}
+	h.writeReport(c, filename)
+}
+
+// GetTraderSeries returns the volume, rank, PnL and position value of every trader across the
+// report runs. The optional address query parameter selects traders (comma separated wallet or
+// proxy addresses), from and to limit the runs like the history API.
+func (h *PolymarketReportHandler) GetTraderSeries(c *gin.Context) {
+	if h.cfg.PolymarketReport.OutputDir == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "OutputDir not configured"})
+		return
+	}
+
+	var from, to time.Time
+	for _, p := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		if s := c.Query(p.name); s != "" {
+			t, err := parseHistoryTime(s)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			*p.t = t
+		}
+	}
+
+	series, err := h.store.TraderSeries(from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to read report snapshots: %v", err)})
+		return
+	}
+	if s := c.Query("address"); s != "" {
+		wanted := make(map[string]bool)
+		for _, addr := range strings.Split(s, ",") {
+			wanted[strings.ToLower(strings.TrimSpace(addr))] = true
+		}
+		filtered := []service.PolymarketTraderSeries{}
+		for _, t := range series {
+			if wanted[strings.ToLower(t.Address)] || wanted[strings.ToLower(t.ProxyAddr)] {
+				filtered = append(filtered, t)
+			}
+		}
+		series = filtered
+	}
+	c.JSON(http.StatusOK, series)
+}
+
+// writeReport parses the markdown report file and responds with it and its snapshot.
+func (h *PolymarketReportHandler) writeReport(c *gin.Context, filename string) {
+	outputDir := h.cfg.PolymarketReport.OutputDir
 	filePath := filepath.Join(outputDir, filename)
 	headers, rows, generatedAt, err := parseMarkdownTable(filePath)
 	if err != nil {
@@ -75,11 +173,19 @@ func (h *PolymarketReportHandler) GetLatestReport(c *gin.Context) {
 		}
 	}
 
+	timestamp := strings.TrimSuffix(strings.TrimPrefix(filename, markdown.REPORT_FILE_PREFIX), ".md")
+	snapshot, err := h.store.Load(timestamp)
+	if err != nil && !os.IsNotExist(err) {
+		logger.Warn("Failed to load report snapshot %s: %v", timestamp, err)
+	}
+
 	c.JSON(http.StatusOK, ReportResponse{
 		Filename:    filename,
+		Timestamp:   timestamp,
 		GeneratedAt: generatedAt,
 		Headers:     mergedHeaders,
 		Rows:        mergedRows,
+		Snapshot:    snapshot,
 	})
 }
 
@@ -92,7 +198,8 @@ func findLatestReportFile(dir string) (string, error) {
 
 	var mdFiles []string
 	for _, e := range entries {
-		if !e.IsDir() && strings.HasSuffix(e.Name(), ".md") {
+		// Skip the diff reports and JSON snapshots written next to the reports
+		if !e.IsDir() && strings.HasPrefix(e.Name(), markdown.REPORT_FILE_PREFIX) && strings.HasSuffix(e.Name(), ".md") {
 			mdFiles = append(mdFiles, e.Name())
 		}
 	}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ka1fe1/crypto-monitoring/internal/service"
+)
+
+type TwitterCursorHandler struct {
+	cursors *service.TwitterCursors
+}
+
+func NewTwitterCursorHandler(cursors *service.TwitterCursors) *TwitterCursorHandler {
+	return &TwitterCursorHandler{
+		cursors: cursors,
+	}
+}
+
+// ListCursors godoc
+// @Summary      List Twitter monitor cursors
+// @Description  Last seen tweet ID and recently sent tweet IDs of every monitored user
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  map[string]service.TwitterCursor
+// @Failure      503  {object}  map[string]string
+// @Router       /api/v1/admin/twitter/cursors [get]
+func (h *TwitterCursorHandler) ListCursors(c *gin.Context) {
+	if h.cursors == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "twitter monitor is not enabled"})
+		return
+	}
+	c.JSON(http.StatusOK, h.cursors.List())
+}
+
+// ResetCursors godoc
+// @Summary      Reset Twitter monitor cursors
+// @Description  Clear the last seen tweet of a user, or of every user without one, so the next run searches within_time again. Sent tweets are still skipped unless sent=true.
+// @Tags         admin
+// @Produce      json
+// @Param        username  path      string  false  "Twitter username"
+// @Param        sent      query     bool    false  "Also forget the sent tweet IDs, so tweets found again are re-sent"
+// @Success      200  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Router       /api/v1/admin/twitter/cursors/{username} [delete]
+func (h *TwitterCursorHandler) ResetCursors(c *gin.Context) {
+	if h.cursors == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "twitter monitor is not enabled"})
+		return
+	}
+
+	username := c.Param("username")
+	dropSent := c.Query("sent") == "true"
+	found, err := h.cursors.Reset(username, dropSent)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !found && username != "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no cursor for " + username})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"username": username, "status": "reset"})
+}
@@ -7,13 +7,17 @@ import (
 	"github.com/ka1fe1/crypto-monitoring/internal/api/handlers"
 	"github.com/ka1fe1/crypto-monitoring/internal/service"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter/outbox"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/opensea"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/tsdb"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-// SetupRouter initializes the Gin engine and defines the routes
-func SetupRouter(cfg *config.Config) *gin.Engine {
+// SetupRouter initializes the Gin engine and defines the routes.
+// box and store may be nil when the notification outbox or the metric store could not be opened.
+// twitterCursors are the cursors of the running Twitter monitor.
+func SetupRouter(cfg *config.Config, box *outbox.Outbox, store *tsdb.Store, twitterCursors *service.TwitterCursors) *gin.Engine {
 	r := gin.Default()
 
 	// Initialize services and handlers
@@ -32,6 +36,16 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 	// Initialize Polymarket Report Handler
 	polyReportHandler := handlers.NewPolymarketReportHandler(cfg)
 
+	outboxHandler := handlers.NewOutboxHandler(box)
+
+	var historyService service.HistoryService
+	if store != nil {
+		historyService = service.NewHistoryService(store)
+	}
+	historyHandler := handlers.NewHistoryHandler(historyService)
+
+	twitterCursorHandler := handlers.NewTwitterCursorHandler(twitterCursors)
+
 	// Register routes
 	r.GET("/ping", handlers.PingHandler)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -48,6 +62,24 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 		api.GET("/token/price", tokenHandler.GetTokenPrice)
 		api.GET("/nft/floor_price", openSeaHandler.GetNFTFloorPrice)
 		api.GET("/polymarket/report", polyReportHandler.GetLatestReport)
+		api.GET("/polymarket/reports", polyReportHandler.ListReports)
+		api.GET("/polymarket/reports/series", polyReportHandler.GetTraderSeries)
+		api.GET("/polymarket/reports/:timestamp", polyReportHandler.GetReport)
+		api.GET("/outbox", outboxHandler.ListMessages)
+		api.GET("/history/token/:id", historyHandler.GetTokenHistory)
+		api.GET("/history/dex/:network/:address", historyHandler.GetDexPairHistory)
+		api.GET("/history/nft/:slug", historyHandler.GetNFTHistory)
+		api.GET("/history/polymarket/:marketId", historyHandler.GetPolymarketHistory)
+		api.GET("/history/btc-dashboard", historyHandler.GetBtcDashboardHistory)
+		api.GET("/admin/twitter/cursors", twitterCursorHandler.ListCursors)
+	}
+
+	// Endpoints that change state require server.admin_token.
+	admin := r.Group("/api/v1", handlers.AdminAuth(cfg.Server.AdminToken))
+	{
+		admin.POST("/outbox/:id/resend", outboxHandler.ResendMessage)
+		admin.DELETE("/admin/twitter/cursors", twitterCursorHandler.ResetCursors)
+		admin.DELETE("/admin/twitter/cursors/:username", twitterCursorHandler.ResetCursors)
 	}
 
 	return r
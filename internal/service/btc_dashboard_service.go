@@ -177,39 +177,20 @@ func (s *btcDashboardService) GenerateMarkdownReport(metrics *BtcDashboardMetric
 	report += fmt.Sprintf("- **当前价格**: $%.2f\n", metrics.CurrentPrice)
 
 	// WMA200
-	wmaStatus := "正常牛市区间"
-	if metrics.WMARatio < wmaThresholdExtremeBear {
-		wmaStatus = "极端熊市信号"
-	} else if metrics.WMARatio < wmaThresholdBottom {
-		wmaStatus = "历史底部区间"
-	} else if metrics.WMARatio >= wmaThresholdOverheated {
-		wmaStatus = "过热信号"
-	}
+	wmaStatus := WMARatioZone(metrics.WMARatio)
 	report += fmt.Sprintf("- **200 周均线 (200WMA)**: $%.2f\n  - 偏离度: %.2fx (状态: %s)\n",
 		metrics.WMA200, metrics.WMARatio, wmaStatus)
 
 	// Balanced Price
 	if metrics.BalancedPrice > 0 {
-		bpStatus := "过高区间 🚨"
-		if metrics.BPRatio <= bpRatioThresholdUndervalued {
-			bpStatus = "基于成本线大底 📉"
-		} else if metrics.BPRatio <= bpRatioThresholdOvervalued {
-			bpStatus = "正常估值区间 📈"
-		}
+		bpStatus := BPRatioZone(metrics.BPRatio)
 
 		report += fmt.Sprintf("- **均衡价格 (BP)**: $%.2f\n  - 当前售价 / BP: %.2fx (状态: %s)\n",
 			metrics.BalancedPrice, metrics.BPRatio, bpStatus)
 	}
 
 	// Ahr999
-	ahrStatus := "泡沫区间"
-	if metrics.Ahr999 < ahr999ThresholdBottom {
-		ahrStatus = "抄底区间"
-	} else if metrics.Ahr999 < ahr999ThresholdInvest {
-		ahrStatus = "定投区间"
-	} else if metrics.Ahr999 < ahr999ThresholdWaitAndSee {
-		ahrStatus = "观望区间"
-	}
+	ahrStatus := Ahr999Zone(metrics.Ahr999)
 	report += fmt.Sprintf("- **ahr999 定投指数**: %.3f (状态: %s)\n", metrics.Ahr999, ahrStatus)
 
 	// FGI
@@ -221,3 +202,67 @@ func (s *btcDashboardService) GenerateMarkdownReport(metrics *BtcDashboardMetric
 
 	return report
 }
+
+// BtcZone is the zone the report puts an indicator in.
+type BtcZone struct {
+	Indicator string // stable key: "wma_ratio", "bp_ratio" or "ahr999"
+	Label     string
+	Zone      string
+	Value     float64
+}
+
+// Zones classifies the cycle indicators like the markdown report does. An indicator whose inputs
+// are missing is left out, its zero value would otherwise read as the most bearish zone: the WMA
+// ratio without the 200WMA or price, the BP ratio without the balanced price and ahr999 without
+// the price or 200DMA.
+func (m *BtcDashboardMetrics) Zones() []BtcZone {
+	var zones []BtcZone
+	if m.CurrentPrice > 0 && m.WMA200 > 0 {
+		zones = append(zones, BtcZone{Indicator: "wma_ratio", Label: "200 周均线偏离度", Zone: WMARatioZone(m.WMARatio), Value: m.WMARatio})
+	}
+	if m.BalancedPrice > 0 {
+		zones = append(zones, BtcZone{Indicator: "bp_ratio", Label: "当前售价 / BP", Zone: BPRatioZone(m.BPRatio), Value: m.BPRatio})
+	}
+	// ahr999 is a product of positive ratios, it stays 0 when the 200DMA is missing.
+	if m.CurrentPrice > 0 && m.Ahr999 > 0 {
+		zones = append(zones, BtcZone{Indicator: "ahr999", Label: "ahr999 定投指数", Zone: Ahr999Zone(m.Ahr999), Value: m.Ahr999})
+	}
+	return zones
+}
+
+// WMARatioZone classifies the price / 200WMA ratio.
+func WMARatioZone(ratio float64) string {
+	switch {
+	case ratio < wmaThresholdExtremeBear:
+		return "极端熊市信号"
+	case ratio < wmaThresholdBottom:
+		return "历史底部区间"
+	case ratio >= wmaThresholdOverheated:
+		return "过热信号"
+	}
+	return "正常牛市区间"
+}
+
+// BPRatioZone classifies the price / balanced price ratio.
+func BPRatioZone(ratio float64) string {
+	switch {
+	case ratio <= bpRatioThresholdUndervalued:
+		return "基于成本线大底 📉"
+	case ratio <= bpRatioThresholdOvervalued:
+		return "正常估值区间 📈"
+	}
+	return "过高区间 🚨"
+}
+
+// Ahr999Zone classifies the ahr999 index.
+func Ahr999Zone(v float64) string {
+	switch {
+	case v < ahr999ThresholdBottom:
+		return "抄底区间"
+	case v < ahr999ThresholdInvest:
+		return "定投区间"
+	case v < ahr999ThresholdWaitAndSee:
+		return "观望区间"
+	}
+	return "泡沫区间"
+}
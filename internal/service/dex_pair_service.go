@@ -8,6 +8,7 @@ import (
 )
 
 type DexPairInfo struct {
+	ContractAddress string  `json:"contract_address"`
 	Name            string  `json:"name"`
 	Price           float64 `json:"price"`
 	PercentChange1h float64 `json:"percent_change_price_1h"`
@@ -46,9 +47,10 @@ func (s *dexPairService) GetDexPairInfo(contractAddresses []string, networkSlug,
 		}
 
 		info := &DexPairInfo{
-			Name:        pair.Name,
-			DexSlug:     pair.DexSlug,
-			NetworkSlug: pair.NetworkSlug,
+			ContractAddress: address,
+			Name:            pair.Name,
+			DexSlug:         pair.DexSlug,
+			NetworkSlug:     pair.NetworkSlug,
 		}
 
 		if len(pair.Quote) > 0 {
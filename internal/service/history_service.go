@@ -0,0 +1,135 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/tsdb"
+)
+
+const (
+	AGGREGATION_LAST = "last"
+	AGGREGATION_AVG  = "avg"
+	AGGREGATION_OHLC = "ohlc"
+)
+
+// HistoryQuery selects a time range of a series. Without a resolution the raw samples are
+// returned and the aggregation is ignored.
+type HistoryQuery struct {
+	From        time.Time
+	To          time.Time
+	Resolution  time.Duration
+	Aggregation string   // last, avg or ohlc, defaults to last
+	Fields      []string // all fields when empty
+}
+
+// HistoryPoint is one row of a series. With the ohlc aggregation every field becomes four
+// columns, e.g. price.open, price.high, price.low and price.close.
+type HistoryPoint struct {
+	Time   time.Time          `json:"time"`
+	Values map[string]float64 `json:"values"`
+}
+
+type HistorySeries struct {
+	Series      string         `json:"series"`
+	Resolution  string         `json:"resolution,omitempty"`
+	Aggregation string         `json:"aggregation,omitempty"`
+	Columns     []string       `json:"columns"`
+	Points      []HistoryPoint `json:"points"`
+}
+
+type HistoryService interface {
+	Query(series string, q HistoryQuery) (*HistorySeries, error)
+}
+
+type historyService struct {
+	store *tsdb.Store
+}
+
+func NewHistoryService(store *tsdb.Store) HistoryService {
+	return &historyService{store: store}
+}
+
+func (s *historyService) Query(series string, q HistoryQuery) (*HistorySeries, error) {
+	if !q.From.Before(q.To) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+	wanted := make(map[string]bool, len(q.Fields))
+	for _, f := range q.Fields {
+		wanted[f] = true
+	}
+	keep := func(field string) bool { return len(wanted) == 0 || wanted[field] }
+
+	result := &HistorySeries{Series: series, Points: []HistoryPoint{}}
+	columns := make(map[string]bool)
+
+	if q.Resolution <= 0 {
+		samples, err := s.store.Samples(series, q.From, q.To)
+		if err != nil {
+			return nil, err
+		}
+		for _, sample := range samples {
+			values := make(map[string]float64, len(sample.Fields))
+			for field, v := range sample.Fields {
+				if keep(field) {
+					values[field] = v
+					columns[field] = true
+				}
+			}
+			result.Points = append(result.Points, HistoryPoint{Time: sample.At, Values: values})
+		}
+		result.Columns = sortedKeys(columns)
+		return result, nil
+	}
+
+	aggregation := strings.ToLower(q.Aggregation)
+	if aggregation == "" {
+		aggregation = AGGREGATION_LAST
+	}
+	if aggregation != AGGREGATION_LAST && aggregation != AGGREGATION_AVG && aggregation != AGGREGATION_OHLC {
+		return nil, fmt.Errorf("unknown aggregation %q, use last, avg or ohlc", q.Aggregation)
+	}
+	result.Resolution = q.Resolution.String()
+	result.Aggregation = aggregation
+
+	buckets, err := s.store.Buckets(series, q.Resolution, q.From, q.To)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range buckets {
+		values := make(map[string]float64)
+		for field, agg := range b.Fields {
+			if !keep(field) {
+				continue
+			}
+			switch aggregation {
+			case AGGREGATION_LAST:
+				values[field] = agg.Close
+			case AGGREGATION_AVG:
+				values[field] = agg.Avg()
+			case AGGREGATION_OHLC:
+				values[field+".open"] = agg.Open
+				values[field+".high"] = agg.High
+				values[field+".low"] = agg.Low
+				values[field+".close"] = agg.Close
+			}
+		}
+		for column := range values {
+			columns[column] = true
+		}
+		result.Points = append(result.Points, HistoryPoint{Time: b.Start, Values: values})
+	}
+	result.Columns = sortedKeys(columns)
+	return result, nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
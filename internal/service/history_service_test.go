@@ -0,0 +1,47 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/tsdb"
+)
+
+func TestHistoryService_Query(t *testing.T) {
+	store, err := tsdb.Open(filepath.Join(t.TempDir(), "metrics.db"), tsdb.Options{Rollups: []tsdb.Rollup{{Resolution: time.Hour}}})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer store.Close()
+
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	for i, price := range []float64{100, 140, 120} {
+		store.Write(tsdb.Sample{Series: TokenSeries("1"), At: start.Add(time.Duration(i) * 20 * time.Minute), Fields: map[string]float64{"price": price, "change_24h": 1}})
+	}
+	svc := NewHistoryService(store)
+	end := start.Add(time.Hour)
+
+	raw, err := svc.Query(TokenSeries("1"), HistoryQuery{From: start, To: end, Fields: []string{"price"}})
+	if err != nil || len(raw.Points) != 3 || len(raw.Columns) != 1 || raw.Points[1].Values["price"] != 140 {
+		t.Fatalf("unexpected raw series %+v, %v", raw, err)
+	}
+
+	ohlc, err := svc.Query(TokenSeries("1"), HistoryQuery{From: start, To: end, Resolution: time.Hour, Aggregation: "ohlc", Fields: []string{"price"}})
+	if err != nil || len(ohlc.Points) != 1 {
+		t.Fatalf("unexpected ohlc series %+v, %v", ohlc, err)
+	}
+	values := ohlc.Points[0].Values
+	if values["price.open"] != 100 || values["price.high"] != 140 || values["price.low"] != 100 || values["price.close"] != 120 {
+		t.Errorf("unexpected ohlc values %v", values)
+	}
+
+	avg, _ := svc.Query(TokenSeries("1"), HistoryQuery{From: start, To: end, Resolution: time.Hour, Aggregation: "avg"})
+	if got := avg.Points[0].Values["price"]; got != 120 {
+		t.Errorf("expected an average of 120, got %v", got)
+	}
+
+	if _, err := svc.Query(TokenSeries("1"), HistoryQuery{From: start, To: end, Resolution: time.Hour, Aggregation: "median"}); err == nil {
+		t.Errorf("expected an unknown aggregation to fail")
+	}
+}
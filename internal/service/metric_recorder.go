@@ -0,0 +1,155 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/polymarket"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/tsdb"
+)
+
+// Series names in the metric store. Field names follow the metric namespace of MetricsService,
+// e.g. series "token/1" has the fields price, change_1h and change_24h.
+const (
+	SERIES_BTC_DASHBOARD = "btc_dashboard"
+)
+
+func TokenSeries(id string) string {
+	return "token/" + id
+}
+
+func DexPairSeries(networkId, address string) string {
+	return "dex/" + networkId + "/" + address
+}
+
+func NFTSeries(slug string) string {
+	return "nft/" + strings.ToLower(slug)
+}
+
+func PolymarketSeries(marketId string) string {
+	return "polymarket/" + marketId
+}
+
+// MetricRecorder writes what the monitors fetch into the metric store. Write errors are logged,
+// a failing store never fails a task.
+type MetricRecorder struct {
+	store *tsdb.Store
+	now   func() time.Time
+}
+
+func NewMetricRecorder(store *tsdb.Store) *MetricRecorder {
+	return &MetricRecorder{store: store, now: time.Now}
+}
+
+func (r *MetricRecorder) RecordTokens(prices map[string]utils.TokenInfo) {
+	now := r.now()
+	samples := make([]tsdb.Sample, 0, len(prices))
+	for id, p := range prices {
+		samples = append(samples, tsdb.Sample{Series: TokenSeries(id), At: now, Fields: tokenFields(p)})
+	}
+	r.write("token quotes", samples)
+}
+
+func (r *MetricRecorder) RecordDexPairs(networkId string, pairs []*DexPairInfo) {
+	now := r.now()
+	samples := make([]tsdb.Sample, 0, len(pairs))
+	for _, p := range pairs {
+		if p == nil {
+			continue
+		}
+		samples = append(samples, tsdb.Sample{
+			Series: DexPairSeries(networkId, p.ContractAddress),
+			At:     now,
+			Fields: map[string]float64{"price": p.Price, "liquidity": p.Liquidity, "change_1h": p.PercentChange1h},
+		})
+	}
+	r.write("dex pair quotes", samples)
+}
+
+func (r *MetricRecorder) RecordNFTFloors(floors []NFTFloorPriceInfo) {
+	now := r.now()
+	samples := make([]tsdb.Sample, 0, len(floors))
+	for _, f := range floors {
+		samples = append(samples, tsdb.Sample{Series: NFTSeries(f.CollectionSlug), At: now, Fields: nftFields(f)})
+	}
+	r.write("nft floors", samples)
+}
+
+func (r *MetricRecorder) RecordPolymarketMarkets(markets []polymarket.MarketDetail) {
+	now := r.now()
+	samples := make([]tsdb.Sample, 0, len(markets))
+	for _, m := range markets {
+		if m.ID == "" {
+			continue
+		}
+		samples = append(samples, tsdb.Sample{Series: PolymarketSeries(m.ID), At: now, Fields: polymarketFields(m)})
+	}
+	r.write("polymarket markets", samples)
+}
+
+// RecordBtcDashboard leaves out the indicators whose inputs could not be fetched, as Zones does,
+// so a data gap is not stored as a zero.
+func (r *MetricRecorder) RecordBtcDashboard(m *BtcDashboardMetrics) {
+	fields := map[string]float64{
+		"fgi":          float64(m.FGIValue),
+		"halving_days": float64(m.HalvingDays),
+	}
+	if m.CurrentPrice > 0 {
+		fields["price"] = m.CurrentPrice
+		if m.WMA200 > 0 {
+			fields["wma200"] = m.WMA200
+			fields["wma_ratio"] = m.WMARatio
+		}
+		if m.Ahr999 > 0 {
+			fields["ahr999"] = m.Ahr999
+		}
+	}
+	if m.BalancedPrice > 0 {
+		fields["balanced_price"] = m.BalancedPrice
+		fields["bp_ratio"] = m.BPRatio
+	}
+	r.write("btc dashboard metrics", []tsdb.Sample{{Series: SERIES_BTC_DASHBOARD, At: r.now(), Fields: fields}})
+}
+
+func (r *MetricRecorder) write(what string, samples []tsdb.Sample) {
+	if len(samples) == 0 {
+		return
+	}
+	if err := r.store.Write(samples...); err != nil {
+		logger.Error("Failed to record %s: %v", what, err)
+	}
+}
+
+func tokenFields(p utils.TokenInfo) map[string]float64 {
+	return map[string]float64{
+		"price":      p.Price,
+		"change_1h":  p.PercentChange1h,
+		"change_24h": p.PercentChange24h,
+	}
+}
+
+func nftFields(f NFTFloorPriceInfo) map[string]float64 {
+	fields := map[string]float64{"floor": f.FloorPrice}
+	if f.FloorPriceUSD > 0 {
+		fields["floor_usd"] = f.FloorPriceUSD
+	}
+	return fields
+}
+
+func polymarketFields(m polymarket.MarketDetail) map[string]float64 {
+	fields := make(map[string]float64, len(m.OutcomePrices)+5)
+	for outcome, price := range m.OutcomePrices {
+		fields[strings.ToLower(outcome)] = price
+	}
+	fields["volume"] = m.Volume
+	fields["change_1h"] = m.OneHourPriceChange
+	fields["change_24h"] = m.OneDayPriceChange
+	fields["change_1w"] = m.OneWeekPriceChange
+	fields["closed"] = 0
+	if m.Closed {
+		fields["closed"] = 1
+	}
+	return fields
+}
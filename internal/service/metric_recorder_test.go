@@ -0,0 +1,65 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/tsdb"
+)
+
+func TestRecordingServices(t *testing.T) {
+	store, err := tsdb.Open(filepath.Join(t.TempDir(), "metrics.db"), tsdb.Options{})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer store.Close()
+	rec := NewMetricRecorder(store)
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	rec.now = func() time.Time { return at }
+
+	tokens := RecordingTokenService(stubTokenService{"1": {Price: 61000, PercentChange24h: 2}}, rec)
+	tokens.GetTokenPrice([]string{"1"})
+	tokens.GetTokenPrice([]string{"1"}, "CNY")
+
+	markets := RecordingPolymarketMonitorService(stubPolymarketService{"7": {ID: "7", OutcomePrices: map[string]float64{"Yes": 0.3, "No": 0.7}, OneDayPriceChange: 0.05}}, rec)
+	markets.GetMarketDetails([]string{"7"})
+
+	samples, _ := store.Samples(TokenSeries("1"), at, at.Add(time.Second))
+	if len(samples) != 1 || samples[0].Fields["price"] != 61000 || samples[0].Fields["change_24h"] != 2 {
+		t.Errorf("expected one USD token sample, got %+v", samples)
+	}
+	samples, _ = store.Samples(PolymarketSeries("7"), at, at.Add(time.Second))
+	if len(samples) != 1 || samples[0].Fields["yes"] != 0.3 || samples[0].Fields["change_24h"] != 0.05 || samples[0].Fields["closed"] != 0 {
+		t.Errorf("expected one market sample, got %+v", samples)
+	}
+
+	if svc := RecordingTokenService(tokens, nil); svc != tokens {
+		t.Errorf("expected the service as is without a recorder")
+	}
+}
+
+func TestRecordBtcDashboard_SkipsMissingIndicators(t *testing.T) {
+	store, err := tsdb.Open(filepath.Join(t.TempDir(), "metrics.db"), tsdb.Options{})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer store.Close()
+	rec := NewMetricRecorder(store)
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	rec.now = func() time.Time { return at }
+
+	// the weekly klines were empty, ahr999 is still known
+	rec.RecordBtcDashboard(&BtcDashboardMetrics{CurrentPrice: 60000, Ahr999: 0.9, FGIValue: 40})
+	samples, _ := store.Samples(SERIES_BTC_DASHBOARD, at, at.Add(time.Second))
+	if len(samples) != 1 {
+		t.Fatalf("expected one sample, got %+v", samples)
+	}
+	fields := samples[0].Fields
+	if _, ok := fields["wma_ratio"]; ok {
+		t.Errorf("expected no wma_ratio without the 200WMA, got %+v", fields)
+	}
+	if fields["ahr999"] != 0.9 || fields["price"] != 60000 || fields["fgi"] != 40 {
+		t.Errorf("expected the known indicators, got %+v", fields)
+	}
+}
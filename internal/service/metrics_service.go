@@ -0,0 +1,160 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/polymarket"
+)
+
+// MetricsService exposes the values the monitors collect under one namespace of
+// lower-case dotted names, so rules can combine them:
+//
+//	btc.price btc.wma200 btc.wma_ratio btc.ahr999 btc.balanced_price btc.bp_ratio
+//	fgi.value halving.days halving.block
+//	token.<id>.price token.<id>.change_1h token.<id>.change_24h
+//	nft.<slug>.floor nft.<slug>.floor_usd
+//	polymarket.<id>.<outcome> polymarket.<id>.volume polymarket.<id>.change_1h
+//	polymarket.<id>.change_24h polymarket.<id>.change_1w polymarket.<id>.closed (1 or 0)
+type MetricsService interface {
+	// Collect fetches the sources behind the requested names and returns every metric they
+	// provide. Sources that fail are reported in the error, the others are still returned.
+	Collect(names []string) (map[string]float64, error)
+}
+
+type metricsService struct {
+	btcDashboard BtcDashboardService
+	tokens       TokenService
+	openSea      OpenSeaService
+	polymarket   PolymarketMonitorService
+}
+
+// NewMetricsService creates a MetricsService, any of the services may be nil when not configured.
+func NewMetricsService(btcDashboard BtcDashboardService, tokens TokenService, openSea OpenSeaService, polymarket PolymarketMonitorService) MetricsService {
+	return &metricsService{
+		btcDashboard: btcDashboard,
+		tokens:       tokens,
+		openSea:      openSea,
+		polymarket:   polymarket,
+	}
+}
+
+func (s *metricsService) Collect(names []string) (map[string]float64, error) {
+	var (
+		needBtc, needUsd                   bool
+		tokenIds, slugs, marketIds         []string
+		seenTokens, seenSlugs, seenMarkets = map[string]bool{}, map[string]bool{}, map[string]bool{}
+	)
+	for _, name := range names {
+		parts := strings.Split(name, ".")
+		switch parts[0] {
+		case "btc", "fgi", "halving":
+			needBtc = true
+		case "token":
+			if len(parts) > 2 && !seenTokens[parts[1]] {
+				seenTokens[parts[1]] = true
+				tokenIds = append(tokenIds, parts[1])
+			}
+		case "nft":
+			if len(parts) > 2 {
+				if !seenSlugs[parts[1]] {
+					seenSlugs[parts[1]] = true
+					slugs = append(slugs, parts[1])
+				}
+				needUsd = needUsd || parts[2] == "floor_usd"
+			}
+		case "polymarket":
+			if len(parts) > 2 && !seenMarkets[parts[1]] {
+				seenMarkets[parts[1]] = true
+				marketIds = append(marketIds, parts[1])
+			}
+		}
+	}
+
+	metrics := make(map[string]float64)
+	var errs []error
+
+	if needBtc {
+		if s.btcDashboard == nil {
+			errs = append(errs, fmt.Errorf("btc dashboard service is not configured"))
+		} else if m, err := s.btcDashboard.FetchAndCalculateMetrics(); err != nil {
+			errs = append(errs, fmt.Errorf("btc dashboard: %w", err))
+		} else {
+			addBtcDashboardMetrics(metrics, m)
+		}
+	}
+
+	if len(tokenIds) > 0 {
+		if s.tokens == nil {
+			errs = append(errs, fmt.Errorf("token service is not configured"))
+		} else if prices, err := s.tokens.GetTokenPrice(tokenIds); err != nil {
+			errs = append(errs, err)
+		} else {
+			addTokenMetrics(metrics, prices)
+		}
+	}
+
+	if len(slugs) > 0 {
+		if s.openSea == nil {
+			errs = append(errs, fmt.Errorf("opensea service is not configured"))
+		} else if floors, err := s.openSea.GetNFTFloorPrices(slugs, needUsd); err != nil {
+			errs = append(errs, err)
+		} else {
+			addNFTMetrics(metrics, floors)
+		}
+	}
+
+	if len(marketIds) > 0 {
+		if s.polymarket == nil {
+			errs = append(errs, fmt.Errorf("polymarket service is not configured"))
+		} else {
+			// one market per call, GetMarketDetails skips failed markets and the details carry no id
+			for _, id := range marketIds {
+				markets, err := s.polymarket.GetMarketDetails([]string{id})
+				if err != nil || len(markets) == 0 {
+					errs = append(errs, fmt.Errorf("failed to fetch polymarket market %s", id))
+					continue
+				}
+				addPolymarketMetrics(metrics, id, markets[0])
+			}
+		}
+	}
+
+	return metrics, errors.Join(errs...)
+}
+
+func addBtcDashboardMetrics(metrics map[string]float64, m *BtcDashboardMetrics) {
+	metrics["btc.price"] = m.CurrentPrice
+	metrics["btc.wma200"] = m.WMA200
+	metrics["btc.wma_ratio"] = m.WMARatio
+	metrics["btc.ahr999"] = m.Ahr999
+	metrics["btc.balanced_price"] = m.BalancedPrice
+	metrics["btc.bp_ratio"] = m.BPRatio
+	metrics["fgi.value"] = float64(m.FGIValue)
+	metrics["halving.days"] = float64(m.HalvingDays)
+	metrics["halving.block"] = float64(m.HalvingBlock)
+}
+
+func addTokenMetrics(metrics map[string]float64, prices map[string]utils.TokenInfo) {
+	for id, p := range prices {
+		addFields(metrics, "token."+strings.ToLower(id)+".", tokenFields(p))
+	}
+}
+
+func addNFTMetrics(metrics map[string]float64, floors []NFTFloorPriceInfo) {
+	for _, f := range floors {
+		addFields(metrics, "nft."+strings.ToLower(f.CollectionSlug)+".", nftFields(f))
+	}
+}
+
+func addPolymarketMetrics(metrics map[string]float64, id string, m polymarket.MarketDetail) {
+	addFields(metrics, "polymarket."+strings.ToLower(id)+".", polymarketFields(m))
+}
+
+func addFields(metrics map[string]float64, prefix string, fields map[string]float64) {
+	for name, v := range fields {
+		metrics[prefix+name] = v
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/polymarket"
+)
+
+type stubTokenService map[string]utils.TokenInfo
+
+func (s stubTokenService) GetTokenPrice(ids []string, convert ...string) (map[string]utils.TokenInfo, error) {
+	out := make(map[string]utils.TokenInfo)
+	for _, id := range ids {
+		if p, ok := s[id]; ok {
+			out[id] = p
+		}
+	}
+	return out, nil
+}
+
+type stubPolymarketService map[string]polymarket.MarketDetail
+
+func (s stubPolymarketService) GetMarketDetails(ids []string) ([]polymarket.MarketDetail, error) {
+	var markets []polymarket.MarketDetail
+	for _, id := range ids {
+		if m, ok := s[id]; ok {
+			markets = append(markets, m)
+		}
+	}
+	return markets, nil
+}
+
+func TestMetricsService_Collect(t *testing.T) {
+	tokens := stubTokenService{"1": {Symbol: "BTC", Price: 61000, PercentChange1h: -1.5}}
+	markets := stubPolymarketService{"12345": {OutcomePrices: map[string]float64{"Yes": 0.83, "No": 0.17}, Closed: true}}
+	svc := NewMetricsService(nil, tokens, nil, markets)
+
+	metrics, err := svc.Collect([]string{"token.1.price", "polymarket.12345.yes", "polymarket.999.yes"})
+	if err == nil {
+		t.Errorf("expected an error for the missing market")
+	}
+	expected := map[string]float64{
+		"token.1.price":           61000,
+		"token.1.change_1h":       -1.5,
+		"polymarket.12345.yes":    0.83,
+		"polymarket.12345.no":     0.17,
+		"polymarket.12345.closed": 1,
+	}
+	for name, v := range expected {
+		if metrics[name] != v {
+			t.Errorf("expected %s = %v, got %v", name, v, metrics[name])
+		}
+	}
+
+	if _, err := svc.Collect([]string{"btc.ahr999"}); err == nil {
+		t.Errorf("expected an error without a btc dashboard service")
+	}
+}
@@ -0,0 +1,444 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/markdown"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/polymarket"
+)
+
+const (
+	POSITION_OPENED  = "opened"
+	POSITION_CLOSED  = "closed"
+	POSITION_RESIZED = "resized"
+
+	// REPORT_DIFF_FILE_PREFIX starts the name of every diff report, followed by the timestamp of its run.
+	REPORT_DIFF_FILE_PREFIX = "polymarket_diff_"
+)
+
+// positionSizeEpsilon is the smallest change in shares that counts as a resize.
+const positionSizeEpsilon = 0.01
+
+// PolymarketTraderSnapshot is one trader of a report run, with the positions kept as fetched.
+type PolymarketTraderSnapshot struct {
+	Name           string                `json:"name"`
+	Address        string                `json:"address"`
+	ProxyAddr      string                `json:"proxy_addr"`
+	Volume         float64               `json:"volume"`
+	Rank           int                   `json:"rank"` // volume rank, 0 when unranked
+	Pnl            float64               `json:"pnl"`
+	PositionValue  float64               `json:"position_value"`
+	LastActive     *time.Time            `json:"last_active,omitempty"`
+	Positions      []polymarket.Position `json:"positions"`
+	PositionsError string                `json:"positions_error,omitempty"` // positions could not be fetched
+}
+
+// PolymarketReportSnapshot is the structured form of a report run, written next to its markdown.
+type PolymarketReportSnapshot struct {
+	GeneratedAt time.Time                  `json:"generated_at"`
+	Traders     []PolymarketTraderSnapshot `json:"traders"`
+}
+
+// PolymarketPositionChange is a position opened, closed or resized between two runs.
+type PolymarketPositionChange struct {
+	Title     string  `json:"title"`
+	Outcome   string  `json:"outcome"`
+	Change    string  `json:"change"` // opened, closed or resized
+	PrevSize  float64 `json:"prev_size"`
+	Size      float64 `json:"size"`
+	PrevValue float64 `json:"prev_value"`
+	Value     float64 `json:"value"`
+}
+
+// PolymarketTraderDiff is what changed for one trader between two runs. A trader only in the
+// current run is New, one only in the previous run is Removed; neither has deltas.
+type PolymarketTraderDiff struct {
+	Name          string                     `json:"name"`
+	Address       string                     `json:"address"`
+	New           bool                       `json:"new,omitempty"`
+	Removed       bool                       `json:"removed,omitempty"`
+	Pnl           float64                    `json:"pnl"`
+	PnlDelta      float64                    `json:"pnl_delta"`
+	PrevRank      int                        `json:"prev_rank"`
+	Rank          int                        `json:"rank"`
+	PositionValue float64                    `json:"position_value"`
+	ValueDelta    float64                    `json:"value_delta"`
+	Positions     []PolymarketPositionChange `json:"positions,omitempty"`
+}
+
+type PolymarketReportDiff struct {
+	From    time.Time              `json:"from"`
+	To      time.Time              `json:"to"`
+	Traders []PolymarketTraderDiff `json:"traders"`
+}
+
+// DiffPolymarketReports compares two runs trader by trader, in the order of the current run
+// followed by the traders that were dropped. Positions are matched by title and outcome and
+// are not compared when either run failed to fetch them.
+func DiffPolymarketReports(prev, cur *PolymarketReportSnapshot) *PolymarketReportDiff {
+	diff := &PolymarketReportDiff{From: prev.GeneratedAt, To: cur.GeneratedAt}
+
+	prevTraders := make(map[string]PolymarketTraderSnapshot, len(prev.Traders))
+	for _, t := range prev.Traders {
+		prevTraders[strings.ToLower(t.Address)] = t
+	}
+	seen := make(map[string]bool, len(cur.Traders))
+
+	for _, t := range cur.Traders {
+		key := strings.ToLower(t.Address)
+		seen[key] = true
+		d := PolymarketTraderDiff{Name: t.Name, Address: t.Address, Pnl: t.Pnl, Rank: t.Rank, PositionValue: t.PositionValue}
+		p, ok := prevTraders[key]
+		if !ok {
+			d.New = true
+			diff.Traders = append(diff.Traders, d)
+			continue
+		}
+		d.PnlDelta = t.Pnl - p.Pnl
+		d.PrevRank = p.Rank
+		d.ValueDelta = t.PositionValue - p.PositionValue
+		if p.PositionsError == "" && t.PositionsError == "" {
+			d.Positions = diffPositions(p.Positions, t.Positions)
+		}
+		diff.Traders = append(diff.Traders, d)
+	}
+
+	for _, p := range prev.Traders {
+		if seen[strings.ToLower(p.Address)] {
+			continue
+		}
+		diff.Traders = append(diff.Traders, PolymarketTraderDiff{
+			Name: p.Name, Address: p.Address, Removed: true,
+			Pnl: p.Pnl, PrevRank: p.Rank, Rank: p.Rank, PositionValue: p.PositionValue,
+		})
+	}
+	return diff
+}
+
+func diffPositions(prev, cur []polymarket.Position) []PolymarketPositionChange {
+	key := func(p polymarket.Position) string { return p.Title + "\x00" + p.Outcome }
+	prevByKey := make(map[string]polymarket.Position, len(prev))
+	for _, p := range prev {
+		prevByKey[key(p)] = p
+	}
+
+	var changes []PolymarketPositionChange
+	for _, c := range cur {
+		p, ok := prevByKey[key(c)]
+		delete(prevByKey, key(c))
+		change := PolymarketPositionChange{Title: c.Title, Outcome: c.Outcome, Size: c.Size, Value: c.CurrentValue}
+		switch {
+		case !ok:
+			change.Change = POSITION_OPENED
+		case math.Abs(c.Size-p.Size) >= positionSizeEpsilon:
+			change.Change = POSITION_RESIZED
+			change.PrevSize, change.PrevValue = p.Size, p.CurrentValue
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	// Keep closed positions in the order of the previous run.
+	for _, p := range prev {
+		if _, ok := prevByKey[key(p)]; !ok {
+			continue
+		}
+		changes = append(changes, PolymarketPositionChange{
+			Title: p.Title, Outcome: p.Outcome, Change: POSITION_CLOSED, PrevSize: p.Size, PrevValue: p.CurrentValue,
+		})
+	}
+	return changes
+}
+
+// Changed reports whether anything of the trader moved between the two runs.
+func (d PolymarketTraderDiff) Changed() bool {
+	return d.New || d.Removed || d.PnlDelta != 0 || d.Rank != d.PrevRank || d.ValueDelta != 0 || len(d.Positions) > 0
+}
+
+// FormatPolymarketReportDiff renders the changed traders as markdown, one section per trader.
+func FormatPolymarketReportDiff(diff *PolymarketReportDiff) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Changes since %s\n\n", diff.From.In(markdown.ReportLocation).Format("2006-01-02 15:04:05")))
+
+	changed := 0
+	for _, d := range diff.Traders {
+		if !d.Changed() {
+			continue
+		}
+		changed++
+		sb.WriteString(fmt.Sprintf("#### %s (`%s`)\n", d.Name, d.Address))
+		switch {
+		case d.New:
+			sb.WriteString(fmt.Sprintf("- new trader, pnl: %s, rank: %s, position value: %s\n", formatUSD(d.Pnl), formatRank(d.Rank), formatUSD(d.PositionValue)))
+		case d.Removed:
+			sb.WriteString("- no longer in the report\n")
+		default:
+			sb.WriteString(fmt.Sprintf("- pnl: %s (%s)\n", formatUSD(d.Pnl), formatSignedUSD(d.PnlDelta)))
+			if d.Rank != d.PrevRank {
+				sb.WriteString(fmt.Sprintf("- rank: %s → %s\n", formatRank(d.PrevRank), formatRank(d.Rank)))
+			}
+			sb.WriteString(fmt.Sprintf("- position value: %s (%s)\n", formatUSD(d.PositionValue), formatSignedUSD(d.ValueDelta)))
+		}
+		for _, p := range d.Positions {
+			switch p.Change {
+			case POSITION_OPENED:
+				sb.WriteString(fmt.Sprintf("- opened: %s | %s | size %.2f (%s)\n", p.Title, p.Outcome, p.Size, formatUSD(p.Value)))
+			case POSITION_CLOSED:
+				sb.WriteString(fmt.Sprintf("- closed: %s | %s | size %.2f (%s)\n", p.Title, p.Outcome, p.PrevSize, formatUSD(p.PrevValue)))
+			case POSITION_RESIZED:
+				sb.WriteString(fmt.Sprintf("- resized: %s | %s | size %.2f → %.2f (%s → %s)\n",
+					p.Title, p.Outcome, p.PrevSize, p.Size, formatUSD(p.PrevValue), formatUSD(p.Value)))
+			}
+		}
+		sb.WriteString("\n")
+	}
+	if changed == 0 {
+		sb.WriteString("No changes.\n")
+	}
+	return sb.String()
+}
+
+func formatUSD(v float64) string {
+	if v < 0 {
+		return fmt.Sprintf("-$%.2f", -v)
+	}
+	return fmt.Sprintf("$%.2f", v)
+}
+
+func formatSignedUSD(v float64) string {
+	if v < 0 {
+		return formatUSD(v)
+	}
+	return "+" + formatUSD(v)
+}
+
+func formatRank(rank int) string {
+	if rank <= 0 {
+		return "unranked"
+	}
+	return fmt.Sprintf("#%d", rank)
+}
+
+// PolymarketReportStore keeps the snapshot of every report run as JSON in the report output
+// directory, named like the markdown report of the same run.
+type PolymarketReportStore struct {
+	dir string
+}
+
+func NewPolymarketReportStore(dir string) *PolymarketReportStore {
+	return &PolymarketReportStore{dir: dir}
+}
+
+func (s *PolymarketReportStore) snapshotPath(timestamp string) string {
+	return filepath.Join(s.dir, markdown.REPORT_FILE_PREFIX+timestamp+".json")
+}
+
+// Save writes the snapshot under the timestamp of its run.
+func (s *PolymarketReportStore) Save(snapshot *PolymarketReportSnapshot) error {
+	return utils.SaveStateFile(s.snapshotPath(markdown.ReportTimestamp(snapshot.GeneratedAt)), snapshot)
+}
+
+// Runs returns the timestamps of the stored snapshots, oldest first.
+func (s *PolymarketReportStore) Runs() ([]string, error) {
+	return s.timestamps(markdown.REPORT_FILE_PREFIX, ".json")
+}
+
+// timestamps returns the timestamps of the files named prefix + timestamp + suffix, oldest first.
+func (s *PolymarketReportStore) timestamps(prefix, suffix string) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var runs []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		timestamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix)
+		if _, err := markdown.ParseReportTimestamp(timestamp); err != nil {
+			continue
+		}
+		runs = append(runs, timestamp)
+	}
+	// The timestamp layout sorts chronologically.
+	sort.Strings(runs)
+	return runs, nil
+}
+
+// PolymarketReportRun is a report run and the files it left in the output directory.
+// Runs from before snapshots were written only have the markdown report.
+type PolymarketReportRun struct {
+	Timestamp   string    `json:"timestamp"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Report      bool      `json:"report"`
+	Snapshot    bool      `json:"snapshot"`
+	Diff        bool      `json:"diff"`
+}
+
+// ListRuns returns every run with a markdown report or a snapshot, newest first.
+func (s *PolymarketReportStore) ListRuns() ([]PolymarketReportRun, error) {
+	reports, err := s.timestamps(markdown.REPORT_FILE_PREFIX, ".md")
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := s.Runs()
+	if err != nil {
+		return nil, err
+	}
+	diffs, err := s.timestamps(REPORT_DIFF_FILE_PREFIX, ".md")
+	if err != nil {
+		return nil, err
+	}
+
+	byTimestamp := make(map[string]*PolymarketReportRun)
+	run := func(timestamp string) *PolymarketReportRun {
+		r, ok := byTimestamp[timestamp]
+		if !ok {
+			generatedAt, _ := markdown.ParseReportTimestamp(timestamp)
+			r = &PolymarketReportRun{Timestamp: timestamp, GeneratedAt: generatedAt}
+			byTimestamp[timestamp] = r
+		}
+		return r
+	}
+	for _, ts := range reports {
+		run(ts).Report = true
+	}
+	for _, ts := range snapshots {
+		run(ts).Snapshot = true
+	}
+	for _, ts := range diffs {
+		// A diff alone is not a run, its report was removed.
+		if r, ok := byTimestamp[ts]; ok {
+			r.Diff = true
+		}
+	}
+
+	runs := make([]PolymarketReportRun, 0, len(byTimestamp))
+	for _, r := range byTimestamp {
+		runs = append(runs, *r)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Timestamp > runs[j].Timestamp })
+	return runs, nil
+}
+
+// PolymarketTraderPoint is a trader as of one report run.
+type PolymarketTraderPoint struct {
+	Time          time.Time `json:"time"`
+	Volume        float64   `json:"volume"`
+	Rank          int       `json:"rank"`
+	Pnl           float64   `json:"pnl"`
+	PositionValue float64   `json:"position_value"`
+	Positions     int       `json:"positions"`
+}
+
+// PolymarketTraderSeries is a trader across report runs, named as in the newest run.
+type PolymarketTraderSeries struct {
+	Name      string                  `json:"name"`
+	Address   string                  `json:"address"`
+	ProxyAddr string                  `json:"proxy_addr"`
+	Points    []PolymarketTraderPoint `json:"points"`
+}
+
+// TraderSeries returns every trader across the snapshots generated in [from, to), oldest point
+// first. A zero from or to leaves that end open. Traders are ordered as in the newest snapshot,
+// followed by those that were dropped from the address list. Unreadable snapshots are skipped.
+func (s *PolymarketReportStore) TraderSeries(from, to time.Time) ([]PolymarketTraderSeries, error) {
+	runs, err := s.Runs()
+	if err != nil {
+		return nil, err
+	}
+
+	byAddress := make(map[string]*PolymarketTraderSeries)
+	var order []string
+	for i := len(runs) - 1; i >= 0; i-- {
+		// The timestamp is the run's minute, so runs outside the range are skipped before reading them.
+		runAt, _ := markdown.ParseReportTimestamp(runs[i])
+		if (!from.IsZero() && !runAt.Add(time.Minute).After(from)) || (!to.IsZero() && !runAt.Before(to)) {
+			continue
+		}
+		snapshot, err := s.Load(runs[i])
+		if err != nil {
+			logger.Warn("Skipping Polymarket report snapshot %s: %v", runs[i], err)
+			continue
+		}
+		if (!from.IsZero() && snapshot.GeneratedAt.Before(from)) || (!to.IsZero() && !snapshot.GeneratedAt.Before(to)) {
+			continue
+		}
+		for _, t := range snapshot.Traders {
+			key := strings.ToLower(t.Address)
+			series, ok := byAddress[key]
+			if !ok {
+				series = &PolymarketTraderSeries{Name: t.Name, Address: t.Address, ProxyAddr: t.ProxyAddr}
+				byAddress[key] = series
+				order = append(order, key)
+			}
+			series.Points = append(series.Points, PolymarketTraderPoint{
+				Time:          snapshot.GeneratedAt,
+				Volume:        t.Volume,
+				Rank:          t.Rank,
+				Pnl:           t.Pnl,
+				PositionValue: t.PositionValue,
+				Positions:     len(t.Positions),
+			})
+		}
+	}
+
+	result := make([]PolymarketTraderSeries, 0, len(order))
+	for _, key := range order {
+		series := byAddress[key]
+		// Snapshots were read newest first.
+		slices.Reverse(series.Points)
+		result = append(result, *series)
+	}
+	return result, nil
+}
+
+// Load reads the snapshot of the run at timestamp, an error wrapping os.ErrNotExist when there is none.
+func (s *PolymarketReportStore) Load(timestamp string) (*PolymarketReportSnapshot, error) {
+	if _, err := markdown.ParseReportTimestamp(timestamp); err != nil {
+		return nil, fmt.Errorf("invalid report timestamp %q: %w", timestamp, err)
+	}
+	data, err := os.ReadFile(s.snapshotPath(timestamp))
+	if err != nil {
+		return nil, err
+	}
+	var snapshot PolymarketReportSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse report snapshot %s: %w", timestamp, err)
+	}
+	return &snapshot, nil
+}
+
+// Latest returns the newest snapshot, nil when none was stored yet.
+func (s *PolymarketReportStore) Latest() (*PolymarketReportSnapshot, error) {
+	runs, err := s.Runs()
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return s.Load(runs[len(runs)-1])
+}
+
+// SaveDiff writes the diff as a markdown report named after the timestamp of the newer run.
+func (s *PolymarketReportStore) SaveDiff(diff *PolymarketReportDiff) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	content := fmt.Sprintf("# Polymarket Trader Report Diff - %s\n\n%s",
+		diff.To.In(markdown.ReportLocation).Format("2006-01-02 15:04:05"), FormatPolymarketReportDiff(diff))
+	path := filepath.Join(s.dir, REPORT_DIFF_FILE_PREFIX+markdown.ReportTimestamp(diff.To)+".md")
+	return os.WriteFile(path, []byte(content), 0644)
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/polymarket"
+)
+
+func TestDiffPolymarketReports(t *testing.T) {
+	day := time.Date(2026, 10, 1, 1, 0, 0, 0, time.UTC)
+	prev := &PolymarketReportSnapshot{GeneratedAt: day, Traders: []PolymarketTraderSnapshot{
+		{Name: "alice", Address: "0xAAA", Rank: 12, Pnl: 100, PositionValue: 500, Positions: []polymarket.Position{
+			{Title: "BTC 100k", Outcome: "Yes", Size: 100, CurrentValue: 60},
+			{Title: "ETH 5k", Outcome: "No", Size: 50, CurrentValue: 20},
+			{Title: "SOL 300", Outcome: "Yes", Size: 10, CurrentValue: 5},
+		}},
+		{Name: "bob", Address: "0xbbb", Rank: 3, Pnl: 10, PositionValue: 10, PositionsError: "timeout"},
+		{Name: "carol", Address: "0xccc", Rank: 7},
+	}}
+	cur := &PolymarketReportSnapshot{GeneratedAt: day.Add(24 * time.Hour), Traders: []PolymarketTraderSnapshot{
+		{Name: "alice", Address: "0xaaa", Rank: 9, Pnl: 150, PositionValue: 480, Positions: []polymarket.Position{
+			{Title: "BTC 100k", Outcome: "Yes", Size: 150, CurrentValue: 90},
+			{Title: "SOL 300", Outcome: "Yes", Size: 10.001, CurrentValue: 6},
+			{Title: "Fed cut", Outcome: "Yes", Size: 30, CurrentValue: 12},
+		}},
+		{Name: "bob", Address: "0xbbb", Rank: 3, Pnl: 10, PositionValue: 10, Positions: []polymarket.Position{{Title: "BTC 100k", Outcome: "No", Size: 5}}},
+		{Name: "dave", Address: "0xddd", Rank: 1, Pnl: 1000},
+	}}
+
+	diff := DiffPolymarketReports(prev, cur)
+	if len(diff.Traders) != 4 {
+		t.Fatalf("expected alice, bob, dave and carol, got %+v", diff.Traders)
+	}
+
+	alice := diff.Traders[0]
+	if alice.PnlDelta != 50 || alice.ValueDelta != -20 || alice.PrevRank != 12 || alice.Rank != 9 {
+		t.Errorf("unexpected deltas for alice %+v", alice)
+	}
+	changes := map[string]string{}
+	for _, p := range alice.Positions {
+		changes[p.Title] = p.Change
+	}
+	want := map[string]string{"BTC 100k": POSITION_RESIZED, "Fed cut": POSITION_OPENED, "ETH 5k": POSITION_CLOSED}
+	if len(changes) != len(want) {
+		t.Errorf("expected position changes %v, got %v", want, changes)
+	}
+	for title, change := range want {
+		if changes[title] != change {
+			t.Errorf("expected %s to be %s, got %q", title, change, changes[title])
+		}
+	}
+
+	if bob := diff.Traders[1]; bob.Changed() {
+		t.Errorf("expected no change for bob, whose previous positions are unknown, got %+v", bob)
+	}
+	if dave, carol := diff.Traders[2], diff.Traders[3]; !dave.New || !carol.Removed {
+		t.Errorf("expected dave to be new and carol removed, got %+v and %+v", dave, carol)
+	}
+
+	text := FormatPolymarketReportDiff(diff)
+	for _, s := range []string{"+$50.00", "#12 → #9", "-$20.00", "opened: Fed cut", "closed: ETH 5k", "size 100.00 → 150.00", "no longer in the report"} {
+		if !strings.Contains(text, s) {
+			t.Errorf("expected %q in the diff report:\n%s", s, text)
+		}
+	}
+	if strings.Contains(text, "bob") {
+		t.Errorf("expected unchanged traders to be left out:\n%s", text)
+	}
+}
+
+func TestPolymarketReportStore(t *testing.T) {
+	dir := t.TempDir()
+	store := NewPolymarketReportStore(dir)
+	if latest, err := store.Latest(); latest != nil || err != nil {
+		t.Fatalf("expected no snapshot in an empty directory, got %+v, %v", latest, err)
+	}
+
+	first := time.Date(2026, 10, 1, 1, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{first.Add(24 * time.Hour), first} {
+		if err := store.Save(&PolymarketReportSnapshot{GeneratedAt: at, Traders: []PolymarketTraderSnapshot{{Name: "alice", Pnl: float64(at.Day())}}}); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	// Files that are not snapshots are ignored.
+	os.WriteFile(filepath.Join(dir, "polymarket_volume_20261002_0900.md"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "polymarket_volume_notes.json"), nil, 0644)
+
+	runs, err := store.Runs()
+	if err != nil || len(runs) != 2 || runs[0] != "20261001_0900" || runs[1] != "20261002_0900" {
+		t.Fatalf("unexpected runs %v, %v", runs, err)
+	}
+	latest, err := store.Latest()
+	if err != nil || latest.Traders[0].Pnl != 2 || !latest.GeneratedAt.Equal(first.Add(24*time.Hour)) {
+		t.Errorf("unexpected latest snapshot %+v, %v", latest, err)
+	}
+	if _, err := store.Load("20261003_0900"); !os.IsNotExist(err) {
+		t.Errorf("expected a missing run to not exist, got %v", err)
+	}
+	if _, err := store.Load("../secrets"); err == nil {
+		t.Errorf("expected an invalid timestamp to fail")
+	}
+}
+
+func TestPolymarketReportStore_History(t *testing.T) {
+	dir := t.TempDir()
+	store := NewPolymarketReportStore(dir)
+	first := time.Date(2026, 10, 1, 1, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		traders := []PolymarketTraderSnapshot{{Name: "alice", Address: "0xAAA", Pnl: float64(i * 10), PositionValue: 100}}
+		if i < 2 {
+			traders = append(traders, PolymarketTraderSnapshot{Name: "bob", Address: "0xbbb", Pnl: -1})
+		}
+		store.Save(&PolymarketReportSnapshot{GeneratedAt: first.Add(time.Duration(i) * 24 * time.Hour), Traders: traders})
+	}
+	// A run from before snapshots were written, with a stray diff.
+	os.WriteFile(filepath.Join(dir, "polymarket_volume_20260930_0900.md"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "polymarket_diff_20261002_0900.md"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "polymarket_diff_20260901_0900.md"), nil, 0644)
+	// Corrupt snapshots are skipped.
+	os.WriteFile(filepath.Join(dir, "polymarket_volume_20260901_0900.json"), []byte("{"), 0644)
+	os.WriteFile(filepath.Join(dir, "polymarket_volume_20261002_1200.json"), []byte("{"), 0644)
+
+	runs, err := store.ListRuns()
+	if err != nil || len(runs) != 6 {
+		t.Fatalf("expected 6 runs, got %+v, %v", runs, err)
+	}
+	if runs[0].Timestamp != "20261003_0900" || !runs[0].Snapshot || runs[0].Report {
+		t.Errorf("expected the newest run first, got %+v", runs[0])
+	}
+	if !runs[2].Diff || runs[4].Timestamp != "20260930_0900" || !runs[4].Report || runs[4].Snapshot {
+		t.Errorf("unexpected runs %+v", runs)
+	}
+
+	series, err := store.TraderSeries(first.Add(time.Hour), time.Time{})
+	if err != nil || len(series) != 2 {
+		t.Fatalf("expected alice and bob, got %+v, %v", series, err)
+	}
+	alice, bob := series[0], series[1]
+	if alice.Name != "alice" || len(alice.Points) != 2 || alice.Points[0].Pnl != 10 || alice.Points[1].Pnl != 20 {
+		t.Errorf("unexpected series for alice %+v", alice)
+	}
+	if len(bob.Points) != 1 || !bob.Points[0].Time.Equal(first.Add(24*time.Hour)) {
+		t.Errorf("unexpected series for bob %+v", bob)
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/polymarket"
+)
+
+// The Recording* wrappers record every successful fetch of a service into the metric store,
+// so the tasks using them keep history without changes of their own. With a nil recorder the
+// service is returned as is.
+
+func RecordingTokenService(svc TokenService, rec *MetricRecorder) TokenService {
+	if rec == nil || svc == nil {
+		return svc
+	}
+	return &recordingTokenService{TokenService: svc, rec: rec}
+}
+
+type recordingTokenService struct {
+	TokenService
+	rec *MetricRecorder
+}
+
+func (s *recordingTokenService) GetTokenPrice(ids []string, convert ...string) (map[string]utils.TokenInfo, error) {
+	prices, err := s.TokenService.GetTokenPrice(ids, convert...)
+	// only USD quotes are recorded, so a series never mixes currencies
+	if err == nil && (len(convert) == 0 || convert[0] == "" || convert[0] == "USD") {
+		s.rec.RecordTokens(prices)
+	}
+	return prices, err
+}
+
+func RecordingDexPairService(svc DexPairService, rec *MetricRecorder) DexPairService {
+	if rec == nil || svc == nil {
+		return svc
+	}
+	return &recordingDexPairService{DexPairService: svc, rec: rec}
+}
+
+type recordingDexPairService struct {
+	DexPairService
+	rec *MetricRecorder
+}
+
+func (s *recordingDexPairService) GetDexPairInfo(contractAddresses []string, networkSlug, networkId string) ([]*DexPairInfo, error) {
+	pairs, err := s.DexPairService.GetDexPairInfo(contractAddresses, networkSlug, networkId)
+	if err == nil {
+		network := networkId
+		if network == "" {
+			network = networkSlug
+		}
+		s.rec.RecordDexPairs(network, pairs)
+	}
+	return pairs, err
+}
+
+func RecordingOpenSeaService(svc OpenSeaService, rec *MetricRecorder) OpenSeaService {
+	if rec == nil || svc == nil {
+		return svc
+	}
+	return &recordingOpenSeaService{OpenSeaService: svc, rec: rec}
+}
+
+type recordingOpenSeaService struct {
+	OpenSeaService
+	rec *MetricRecorder
+}
+
+func (s *recordingOpenSeaService) GetNFTFloorPrices(slugs []string, convertToUsd bool) ([]NFTFloorPriceInfo, error) {
+	floors, err := s.OpenSeaService.GetNFTFloorPrices(slugs, convertToUsd)
+	if err == nil {
+		s.rec.RecordNFTFloors(floors)
+	}
+	return floors, err
+}
+
+func RecordingPolymarketMonitorService(svc PolymarketMonitorService, rec *MetricRecorder) PolymarketMonitorService {
+	if rec == nil || svc == nil {
+		return svc
+	}
+	return &recordingPolymarketMonitorService{PolymarketMonitorService: svc, rec: rec}
+}
+
+type recordingPolymarketMonitorService struct {
+	PolymarketMonitorService
+	rec *MetricRecorder
+}
+
+func (s *recordingPolymarketMonitorService) GetMarketDetails(ids []string) ([]polymarket.MarketDetail, error) {
+	markets, err := s.PolymarketMonitorService.GetMarketDetails(ids)
+	if err == nil {
+		s.rec.RecordPolymarketMarkets(markets)
+	}
+	return markets, err
+}
+
+func RecordingBtcDashboardService(svc BtcDashboardService, rec *MetricRecorder) BtcDashboardService {
+	if rec == nil || svc == nil {
+		return svc
+	}
+	return &recordingBtcDashboardService{BtcDashboardService: svc, rec: rec}
+}
+
+type recordingBtcDashboardService struct {
+	BtcDashboardService
+	rec *MetricRecorder
+}
+
+func (s *recordingBtcDashboardService) FetchAndCalculateMetrics() (*BtcDashboardMetrics, error) {
+	m, err := s.BtcDashboardService.FetchAndCalculateMetrics()
+	if err == nil && m != nil {
+		s.rec.RecordBtcDashboard(m)
+	}
+	return m, err
+}
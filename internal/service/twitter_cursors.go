@@ -0,0 +1,131 @@
+package service
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+)
+
+// maxSentTweetIDs bounds the sent tweet IDs kept per user, enough to cover any search window.
+const maxSentTweetIDs = 200
+
+// TwitterCursor is where the monitor of one user stands.
+type TwitterCursor struct {
+	LastID    string    `json:"last_id"`            // newest tweet seen, the next search starts after it
+	SentIDs   []string  `json:"sent_ids,omitempty"` // tweets already notified, newest last
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TwitterCursors keeps the cursor of every monitored user in a JSON file, so a restart continues
+// after the last tweet seen instead of searching within_time again. An empty path keeps them in
+// memory only.
+type TwitterCursors struct {
+	path string
+
+	mu      sync.Mutex
+	cursors map[string]*TwitterCursor
+}
+
+func NewTwitterCursors(path string) *TwitterCursors {
+	c := &TwitterCursors{path: path, cursors: make(map[string]*TwitterCursor)}
+	if path != "" {
+		utils.LoadStateFile(path, &c.cursors)
+	}
+	if c.cursors == nil {
+		c.cursors = make(map[string]*TwitterCursor)
+	}
+	for username, cur := range c.cursors {
+		if cur == nil {
+			delete(c.cursors, username)
+		}
+	}
+	return c
+}
+
+// Get returns a copy of the cursor of username, the zero cursor when there is none.
+func (c *TwitterCursors) Get(username string) TwitterCursor {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cur, ok := c.cursors[username]
+	if !ok {
+		return TwitterCursor{}
+	}
+	return TwitterCursor{LastID: cur.LastID, SentIDs: slices.Clone(cur.SentIDs), UpdatedAt: cur.UpdatedAt}
+}
+
+// Advance moves the cursor of username to lastID and records the notified tweets.
+// An empty lastID keeps the current one.
+func (c *TwitterCursors) Advance(username, lastID string, sentIDs []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cur, ok := c.cursors[username]
+	if !ok {
+		cur = &TwitterCursor{}
+		c.cursors[username] = cur
+	}
+	if lastID != "" {
+		cur.LastID = lastID
+	}
+	for _, id := range sentIDs {
+		if !slices.Contains(cur.SentIDs, id) {
+			cur.SentIDs = append(cur.SentIDs, id)
+		}
+	}
+	if n := len(cur.SentIDs); n > maxSentTweetIDs {
+		cur.SentIDs = cur.SentIDs[n-maxSentTweetIDs:]
+	}
+	cur.UpdatedAt = time.Now()
+	return c.save()
+}
+
+// List returns a copy of every cursor, keyed by username.
+func (c *TwitterCursors) List() map[string]TwitterCursor {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string]TwitterCursor, len(c.cursors))
+	for username, cur := range c.cursors {
+		out[username] = TwitterCursor{LastID: cur.LastID, SentIDs: slices.Clone(cur.SentIDs), UpdatedAt: cur.UpdatedAt}
+	}
+	return out
+}
+
+// Reset clears the last seen tweet of username, or of every user when username is empty, so the
+// next run searches within_time again. The sent tweet IDs are kept so tweets found again are not
+// re-sent, unless dropSent is set. It reports whether there was a cursor to reset.
+func (c *TwitterCursors) Reset(username string, dropSent bool) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	reset := func(username string) {
+		if dropSent {
+			delete(c.cursors, username)
+			return
+		}
+		cur := c.cursors[username]
+		cur.LastID = ""
+		cur.UpdatedAt = time.Now()
+	}
+
+	if username == "" {
+		if len(c.cursors) == 0 {
+			return false, nil
+		}
+		for username := range c.cursors {
+			reset(username)
+		}
+		return true, c.save()
+	}
+	if _, ok := c.cursors[username]; !ok {
+		return false, nil
+	}
+	reset(username)
+	return true, c.save()
+}
+
+func (c *TwitterCursors) save() error {
+	if c.path == "" {
+		return nil
+	}
+	return utils.SaveStateFile(c.path, c.cursors)
+}
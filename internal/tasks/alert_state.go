@@ -0,0 +1,143 @@
+package tasks
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+// AlertTransition is the outcome of observing an alert condition.
+type AlertTransition int
+
+const (
+	ALERT_UNCHANGED AlertTransition = iota
+	ALERT_FIRED                     // send the alert
+	ALERT_RESOLVED                  // send the resolved notice
+)
+
+// AlertTracker remembers which alerts are firing. Keys describe the condition itself
+// (e.g. "token/1/above/60000"), not the task, so tasks sharing a tracker never send the
+// same alert twice: the first one to observe a condition fires it, the others see it
+// already firing.
+type AlertTracker struct {
+	mu     sync.Mutex
+	states map[string]*alertState
+}
+
+type alertState struct {
+	firing   bool
+	notified bool // the current firing was announced, so its resolution is too
+	lastSent time.Time
+}
+
+func NewAlertTracker() *AlertTracker {
+	return &AlertTracker{states: make(map[string]*alertState)}
+}
+
+// Observe records the current state of the condition key. triggered means the value is past
+// the trigger level, cleared means it has moved back beyond the hysteresis band; in between
+// the alert keeps its state so a value hovering around the level does not flap.
+// A condition that triggers again within cooldown of the last alert fires silently and is
+// announced once the cooldown has passed, if it is still triggered by then.
+func (t *AlertTracker) Observe(key string, triggered, cleared bool, cooldown time.Duration, now time.Time) AlertTransition {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.states[key]
+	if !ok {
+		s = &alertState{}
+		t.states[key] = s
+	}
+
+	switch {
+	case !s.firing && triggered:
+		s.firing = true
+		s.notified = s.lastSent.IsZero() || now.Sub(s.lastSent) >= cooldown
+		if s.notified {
+			s.lastSent = now
+			return ALERT_FIRED
+		}
+	case s.firing && cleared:
+		s.firing = false
+		if s.notified {
+			return ALERT_RESOLVED
+		}
+	case s.firing && !s.notified && triggered && now.Sub(s.lastSent) >= cooldown:
+		s.notified = true
+		s.lastSent = now
+		return ALERT_FIRED
+	}
+	return ALERT_UNCHANGED
+}
+
+// Firing reports whether the alert key is currently firing.
+func (t *AlertTracker) Firing(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.states[key]
+	return ok && s.firing
+}
+
+// alertTrackers hands out one tracker per bot, so tasks reporting to the same bot share alert state.
+type alertTrackers struct {
+	mu       sync.Mutex
+	trackers map[string]*AlertTracker
+}
+
+func newAlertTrackers() *alertTrackers {
+	return &alertTrackers{trackers: make(map[string]*AlertTracker)}
+}
+
+func (a *alertTrackers) forBot(botName string) *AlertTracker {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	t, ok := a.trackers[botName]
+	if !ok {
+		t = NewAlertTracker()
+		a.trackers[botName] = t
+	}
+	return t
+}
+
+// aboveLevel evaluates "value >= level", clearing once value drops hysteresis (a fraction) below level.
+func aboveLevel(value, level, hysteresis float64) (triggered, cleared bool) {
+	return value >= level, value < level*(1-hysteresis)
+}
+
+// belowLevel evaluates "value <= level", clearing once value rises hysteresis (a fraction) above level.
+func belowLevel(value, level, hysteresis float64) (triggered, cleared bool) {
+	return value <= level, value > level*(1+hysteresis)
+}
+
+// alertNotice is one fired or resolved alert, shown as a markdown list item.
+type alertNotice struct {
+	severity string
+	line     string
+}
+
+// sendAlertNotices sends the notices as one message with the highest severity among them.
+// Resolved notices keep the alert's severity, so min_severity lets them through whenever
+// it let the alert through.
+func sendAlertNotices(notifier alter.Notifier, taskName, title string, notices []alertNotice, lastUpdated time.Time) {
+	severity := constant.SEVERITY_INFO
+	lines := make([]string, 0, len(notices))
+	for _, n := range notices {
+		if alter.SeverityRank(n.severity) > alter.SeverityRank(severity) {
+			severity = n.severity
+		}
+		lines = append(lines, n.line)
+	}
+
+	text := fmt.Sprintf("#### %s\n\n%s\n\n---\n**Last Updated**: %s", title, strings.Join(lines, "\n"), utils.FormatBJTime(lastUpdated))
+	if err := alter.Notify(notifier, alter.NewEvent(taskName, severity, title, text)); err != nil {
+		logger.Error("Error sending %s: %v", title, err)
+	} else {
+		logger.Info("Sent %s with %d alerts", title, len(notices))
+	}
+}
@@ -0,0 +1,91 @@
+package tasks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAlertTracker_Observe(t *testing.T) {
+	tracker := NewAlertTracker()
+	now := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	cooldown := 30 * time.Minute
+
+	steps := []struct {
+		name      string
+		after     time.Duration
+		value     float64
+		expected  AlertTransition
+		expectsOn bool
+	}{
+		{"below level", 0, 99, ALERT_UNCHANGED, false},
+		{"crosses level", time.Minute, 101, ALERT_FIRED, true},
+		{"stays above", time.Minute, 105, ALERT_UNCHANGED, true},
+		{"inside band", time.Minute, 99, ALERT_UNCHANGED, true},
+		{"leaves band", time.Minute, 97, ALERT_RESOLVED, false},
+		// fires again 4 minutes after the last alert, inside the cooldown
+		{"flaps within cooldown", time.Minute, 101, ALERT_UNCHANGED, true},
+		{"silent alert clears silently", time.Minute, 97, ALERT_UNCHANGED, false},
+		{"fires after cooldown", 30 * time.Minute, 101, ALERT_FIRED, true},
+	}
+	for _, s := range steps {
+		now = now.Add(s.after)
+		triggered, cleared := aboveLevel(s.value, 100, 0.02)
+		if got := tracker.Observe("level", triggered, cleared, cooldown, now); got != s.expected {
+			t.Errorf("%s: expected transition %d, got %d", s.name, s.expected, got)
+		}
+		if tracker.Firing("level") != s.expectsOn {
+			t.Errorf("%s: expected firing %v", s.name, s.expectsOn)
+		}
+	}
+}
+
+func TestAlertTracker_FiresAfterCooldownWhileStillTriggered(t *testing.T) {
+	tracker := NewAlertTracker()
+	now := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	cooldown := 30 * time.Minute
+
+	steps := []struct {
+		name     string
+		after    time.Duration
+		value    float64
+		expected AlertTransition
+	}{
+		{"crosses level", 0, 101, ALERT_FIRED},
+		{"leaves band", time.Minute, 97, ALERT_RESOLVED},
+		{"re-triggers within cooldown", time.Minute, 101, ALERT_UNCHANGED},
+		{"still within cooldown", 10 * time.Minute, 103, ALERT_UNCHANGED},
+		{"inside band after cooldown", 20 * time.Minute, 99, ALERT_UNCHANGED},
+		{"still triggered after cooldown", time.Minute, 102, ALERT_FIRED},
+		{"stays above", time.Minute, 105, ALERT_UNCHANGED},
+		{"announced alert resolves", time.Minute, 97, ALERT_RESOLVED},
+	}
+	for _, s := range steps {
+		now = now.Add(s.after)
+		triggered, cleared := aboveLevel(s.value, 100, 0.02)
+		if got := tracker.Observe("level", triggered, cleared, cooldown, now); got != s.expected {
+			t.Errorf("%s: expected transition %d, got %d", s.name, s.expected, got)
+		}
+	}
+}
+
+func TestAlertTrackers_ForBot(t *testing.T) {
+	trackers := newAlertTrackers()
+	if trackers.forBot("token") != trackers.forBot("token") {
+		t.Errorf("expected tasks of one bot to share a tracker")
+	}
+	if trackers.forBot("token") == trackers.forBot("dex-pair") {
+		t.Errorf("expected separate trackers per bot")
+	}
+}
+
+func TestBelowLevel(t *testing.T) {
+	if triggered, cleared := belowLevel(50, 50, 0.1); !triggered || cleared {
+		t.Errorf("expected 50 to trigger below 50")
+	}
+	if triggered, cleared := belowLevel(54, 50, 0.1); triggered || cleared {
+		t.Errorf("expected 54 to be inside the band")
+	}
+	if _, cleared := belowLevel(56, 50, 0.1); !cleared {
+		t.Errorf("expected 56 to clear")
+	}
+}
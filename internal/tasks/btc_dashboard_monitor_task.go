@@ -1,28 +1,29 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
-	"sync"
 	"time"
 
 	"github.com/ka1fe1/crypto-monitoring/internal/service"
 	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
-	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter/dingding"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
 )
 
 type BtcDashboardMonitorTask struct {
 	svc              service.BtcDashboardService
-	dingBot          *dingding.DingBot
-	ticker           *time.Ticker
-	stop             chan bool
+	notifier         alter.Notifier
+	zoneAlerts       bool
+	summary          bool
+	zones            map[string]btcZoneState
+	zoneStatePath    string
 	interval         time.Duration
 	quietHoursParams utils.QuietHoursParams
-	lastRunTime      time.Time
-	mu               sync.RWMutex
 }
 
-func NewBtcDashboardMonitorTask(svc service.BtcDashboardService, dingBot *dingding.DingBot, intervalSeconds int, quietHoursParams utils.QuietHoursParams) *BtcDashboardMonitorTask {
+func NewBtcDashboardMonitorTask(svc service.BtcDashboardService, notifier alter.Notifier, alerts BtcZoneAlertOptions, intervalSeconds int, quietHoursParams utils.QuietHoursParams) *BtcDashboardMonitorTask {
 	interval := time.Duration(intervalSeconds) * time.Second
 	if interval <= 0 {
 		interval = 43200 * time.Second // default to 12 hours
@@ -30,65 +31,56 @@ func NewBtcDashboardMonitorTask(svc service.BtcDashboardService, dingBot *dingdi
 
 	return &BtcDashboardMonitorTask{
 		svc:              svc,
-		dingBot:          dingBot,
-		stop:             make(chan bool),
+		notifier:         notifier,
+		zoneAlerts:       alerts.Enabled,
+		summary:          alerts.Summary,
+		zones:            loadBtcZoneStates(alerts.StatePath),
+		zoneStatePath:    alerts.StatePath,
 		interval:         interval,
 		quietHoursParams: quietHoursParams,
 	}
 }
 
-func (t *BtcDashboardMonitorTask) Start() {
-	t.ticker = time.NewTicker(t.interval)
-	go func() {
-		for {
-			select {
-			case <-t.ticker.C:
-				t.run()
-			case <-t.stop:
-				t.ticker.Stop()
-				return
-			}
-		}
-	}()
+func (t *BtcDashboardMonitorTask) Name() string {
+	return "BtcDashboardMonitorTask"
 }
 
-func (t *BtcDashboardMonitorTask) Stop() {
-	select {
-	case t.stop <- true:
-	default:
-	}
+func (t *BtcDashboardMonitorTask) Interval() time.Duration {
+	return t.interval
 }
 
-func (t *BtcDashboardMonitorTask) run() {
-	t.mu.RLock()
-	lastRun := t.lastRunTime
-	t.mu.RUnlock()
-
-	if !utils.ShouldExecTask(t.quietHoursParams, lastRun, t.interval) {
-		return
-	}
-
-	t.mu.Lock()
-	t.lastRunTime = time.Now()
-	t.mu.Unlock()
+func (t *BtcDashboardMonitorTask) QuietHours() utils.QuietHoursParams {
+	return t.quietHoursParams
+}
 
+func (t *BtcDashboardMonitorTask) Run(ctx context.Context) {
 	metrics, err := t.svc.FetchAndCalculateMetrics()
 	if err != nil {
 		logger.Error("BtcDashboardMonitorTask fetch metrics failed: %v", err)
 		return
 	}
 
+	if t.zoneAlerts {
+		now := time.Now()
+		if notices := t.checkZones(metrics, now); len(notices) > 0 {
+			sendAlertNotices(t.notifier, "BtcDashboardMonitorTask", fmt.Sprintf("%s BTC 周期区间切换", t.notifier.GetKeyword()), notices, now)
+		}
+	}
+	if !t.summary {
+		return
+	}
+
 	markdownReport := t.svc.GenerateMarkdownReport(metrics)
 	var title string
-	if t.dingBot.Keyword != "" {
-		title = fmt.Sprintf("%s BTC 宏观周期指标", t.dingBot.Keyword)
+	if t.notifier.GetKeyword() != "" {
+		title = fmt.Sprintf("%s BTC 宏观周期指标", t.notifier.GetKeyword())
 	} else {
 		title = "BTC 宏观周期指标"
 	}
 
-	err = t.dingBot.SendMarkdown(title, markdownReport, nil, false)
+	err = alter.Notify(t.notifier, alter.NewEvent("BtcDashboardMonitorTask", constant.SEVERITY_INFO, title, markdownReport))
 	if err != nil {
-		logger.Error("BtcDashboardMonitorTask failed sending dingtalk message: %v", err)
+		logger.Error("BtcDashboardMonitorTask failed sending notification: %v", err)
 	} else {
 		logger.Info("BtcDashboardMonitorTask sent markdown report successfully")
 	}
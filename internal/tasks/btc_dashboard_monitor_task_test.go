@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -75,10 +76,10 @@ func TestBtcDashboardMonitorTask_Run_Mock(t *testing.T) {
 	)
 
 	qh := utils.QuietHoursParams{Enabled: true, StartHour: 11, EndHour: 12, Behavior: constant.QUIET_HOURS_BEHAVIOR_PAUSE}
-	task := NewBtcDashboardMonitorTask(svc, bot, cfg.BtcDashboardMonitor.IntervalSeconds, qh)
+	task := NewBtcDashboardMonitorTask(svc, bot, BtcZoneAlertOptions{Summary: true}, cfg.BtcDashboardMonitor.IntervalSeconds, qh)
 
 	logger.Info("Running BtcDashboardMonitorTask (Mock Data) for testing...")
-	task.run()
+	task.Run(context.Background())
 
 	// Wait momentarily to ensure logs/requests complete in test context
 	time.Sleep(1 * time.Second)
@@ -123,10 +124,10 @@ func TestBtcDashboardMonitorTask_Run_Real(t *testing.T) {
 	svc := service.NewBtcDashboardService(bCli, mCli, aCli, bpCli)
 
 	qh := utils.QuietHoursParams{Enabled: true, StartHour: 11, EndHour: 12, Behavior: constant.QUIET_HOURS_BEHAVIOR_PAUSE}
-	task := NewBtcDashboardMonitorTask(svc, bot, cfg.BtcDashboardMonitor.IntervalSeconds, qh)
+	task := NewBtcDashboardMonitorTask(svc, bot, BtcZoneAlertOptions{Summary: true}, cfg.BtcDashboardMonitor.IntervalSeconds, qh)
 
 	logger.Info("Running BtcDashboardMonitorTask (Real API) for testing...")
-	task.run()
+	task.Run(context.Background())
 
 	time.Sleep(1 * time.Second)
 }
@@ -0,0 +1,75 @@
+package tasks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/internal/service"
+	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+// BtcZoneAlertOptions configures the zone transition alerts of a BtcDashboardMonitorTask.
+type BtcZoneAlertOptions struct {
+	Enabled   bool
+	Summary   bool   // send the full report on each run
+	StatePath string // last zone per indicator, kept across restarts
+}
+
+// btcZoneState is the zone an indicator was last seen in and since when.
+type btcZoneState struct {
+	Zone  string    `json:"zone"`
+	Since time.Time `json:"since"`
+}
+
+func loadBtcZoneStates(path string) map[string]btcZoneState {
+	states := make(map[string]btcZoneState)
+	if path != "" {
+		utils.LoadStateFile(path, &states)
+	}
+	return states
+}
+
+// checkZones returns a notice for every indicator that entered a new zone since the previous run.
+// The first zone seen for an indicator is only recorded.
+func (t *BtcDashboardMonitorTask) checkZones(metrics *service.BtcDashboardMetrics, now time.Time) []alertNotice {
+	var notices []alertNotice
+	dirty := false
+	for _, z := range metrics.Zones() {
+		prev, seen := t.zones[z.Indicator]
+		if seen && prev.Zone == z.Zone {
+			continue
+		}
+		t.zones[z.Indicator] = btcZoneState{Zone: z.Zone, Since: now}
+		dirty = true
+		if !seen {
+			continue
+		}
+		notices = append(notices, alertNotice{
+			severity: constant.SEVERITY_CRITICAL,
+			line: fmt.Sprintf("- **%s**: %s → ***%s*** (%.3f)，此前处于 %s 共 %s",
+				z.Label, prev.Zone, z.Zone, z.Value, prev.Zone, formatZoneDuration(now.Sub(prev.Since))),
+		})
+	}
+
+	if dirty && t.zoneStatePath != "" {
+		if err := utils.SaveStateFile(t.zoneStatePath, t.zones); err != nil {
+			logger.Error("Failed to save BTC zone state: %v", err)
+		}
+	}
+	return notices
+}
+
+// formatZoneDuration renders how long a zone lasted in days and hours, or minutes below an hour.
+func formatZoneDuration(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%d 天 %d 小时", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%d 小时", hours)
+	}
+	return fmt.Sprintf("%d 分钟", int(d/time.Minute))
+}
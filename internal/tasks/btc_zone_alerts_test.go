@@ -0,0 +1,85 @@
+package tasks
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ka1fe1/crypto-monitoring/internal/service"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+type fakeBtcDashboardService struct {
+	metrics service.BtcDashboardMetrics
+}
+
+func (f *fakeBtcDashboardService) FetchAndCalculateMetrics() (*service.BtcDashboardMetrics, error) {
+	m := f.metrics
+	return &m, nil
+}
+
+func (f *fakeBtcDashboardService) GenerateMarkdownReport(metrics *service.BtcDashboardMetrics) string {
+	return "report"
+}
+
+func TestBtcDashboardMonitorTask_ZoneAlerts(t *testing.T) {
+	svc := &fakeBtcDashboardService{metrics: service.BtcDashboardMetrics{CurrentPrice: 60000, WMA200: 30000, WMARatio: 2, Ahr999: 0.8, BalancedPrice: 40000, BPRatio: 1.5}}
+	notifier := &recordingNotifier{keyword: "btc"}
+	alerts := BtcZoneAlertOptions{Enabled: true, StatePath: filepath.Join(t.TempDir(), "zones.json")}
+	task := NewBtcDashboardMonitorTask(svc, notifier, alerts, 60, utils.QuietHoursParams{})
+
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 0 {
+		t.Fatalf("expected the first zones to be recorded silently, got %+v", events)
+	}
+
+	// a restart keeps the zones, so only ahr999 leaving 定投区间 alerts
+	task = NewBtcDashboardMonitorTask(svc, notifier, alerts, 60, utils.QuietHoursParams{})
+	svc.metrics.Ahr999 = 0.4
+	task.Run(context.Background())
+	events := notifier.take()
+	if len(events) != 1 {
+		t.Fatalf("expected one zone alert, got %+v", events)
+	}
+	if events[0].Severity != constant.SEVERITY_CRITICAL {
+		t.Errorf("expected a critical alert, got %s", events[0].Severity)
+	}
+	if !strings.Contains(events[0].Markdown, "**ahr999 定投指数**: 定投区间 → ***抄底区间*** (0.400)，此前处于 定投区间 共 0 分钟") {
+		t.Errorf("unexpected alert: %s", events[0].Markdown)
+	}
+
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 0 {
+		t.Fatalf("expected no alert while the zones stay, got %+v", events)
+	}
+}
+
+func TestBtcDashboardMonitorTask_ZoneAlertsSkipMissingData(t *testing.T) {
+	metrics := service.BtcDashboardMetrics{CurrentPrice: 60000, WMA200: 30000, WMARatio: 2, Ahr999: 0.8}
+	svc := &fakeBtcDashboardService{metrics: metrics}
+	notifier := &recordingNotifier{keyword: "btc"}
+	task := NewBtcDashboardMonitorTask(svc, notifier, BtcZoneAlertOptions{Enabled: true}, 60, utils.QuietHoursParams{})
+	task.Run(context.Background())
+	notifier.take()
+
+	// Binance returned no klines: price, 200WMA and ahr999 are all zero
+	svc.metrics = service.BtcDashboardMetrics{}
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 0 {
+		t.Fatalf("expected no zone alert on missing data, got %+v", events)
+	}
+	// only the weekly klines are missing
+	svc.metrics = service.BtcDashboardMetrics{CurrentPrice: 60000, Ahr999: 0.8}
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 0 {
+		t.Fatalf("expected no zone alert without the 200WMA, got %+v", events)
+	}
+
+	svc.metrics = metrics
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 0 {
+		t.Fatalf("expected no zone alert when the data comes back, got %+v", events)
+	}
+}
@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -8,62 +9,55 @@ import (
 	"github.com/ka1fe1/crypto-monitoring/internal/service"
 	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
-	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter/dingding"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
 )
 
 type DexPairAlterTask struct {
 	dexService       service.DexPairService
-	dingBot          *dingding.DingBot
-	ticker           *time.Ticker
-	stop             chan bool
+	notifier         alter.Notifier
 	contractAddrInfo map[string][]string
+	drain            DexDrainOptions
+	pairs            map[string]*dexPairHistory // "networkId/address" -> recent history
+	alerts           *AlertTracker
 	interval         time.Duration
 	quietHoursParams utils.QuietHoursParams
-	lastRunTime      time.Time
 }
 
-func NewDexPairAlterTask(dexService service.DexPairService, dingBot *dingding.DingBot, contractAddrInfo map[string][]string, intervalSeconds int, quietHoursParams utils.QuietHoursParams) *DexPairAlterTask {
+func NewDexPairAlterTask(dexService service.DexPairService, notifier alter.Notifier, contractAddrInfo map[string][]string, drain DexDrainOptions, intervalSeconds int, quietHoursParams utils.QuietHoursParams) *DexPairAlterTask {
 	interval := time.Duration(intervalSeconds) * time.Second
 	if interval <= 0 {
 		interval = 60 * time.Second
 	}
+	drain = defaultDrainOptions(drain)
 	return &DexPairAlterTask{
 		dexService:       dexService,
-		dingBot:          dingBot,
-		stop:             make(chan bool),
+		notifier:         notifier,
 		contractAddrInfo: contractAddrInfo,
+		drain:            drain,
+		pairs:            make(map[string]*dexPairHistory),
+		alerts:           drain.Tracker,
 		interval:         interval,
 		quietHoursParams: quietHoursParams,
 	}
-
 }
 
-func (t *DexPairAlterTask) Start() {
-	t.ticker = time.NewTicker(t.interval)
-	go func() {
-		for {
-			select {
-			case <-t.ticker.C:
-				t.run()
-			case <-t.stop:
-				t.ticker.Stop()
-				return
-			}
-		}
-	}()
+func (t *DexPairAlterTask) Name() string {
+	return "DexPairAlterTask"
 }
 
-func (t *DexPairAlterTask) Stop() {
-	t.stop <- true
+func (t *DexPairAlterTask) Interval() time.Duration {
+	return t.interval
 }
 
-func (t *DexPairAlterTask) run() {
-	if !utils.ShouldExecTask(t.quietHoursParams, t.lastRunTime, t.interval) {
-		return
-	}
-	t.lastRunTime = time.Now()
+func (t *DexPairAlterTask) QuietHours() utils.QuietHoursParams {
+	return t.quietHoursParams
+}
 
+func (t *DexPairAlterTask) Run(ctx context.Context) {
 	var allTexts []string
+	var fired, resolved []alertNotice
+	now := time.Now()
 
 	for networkId, addrs := range t.contractAddrInfo {
 		if len(addrs) == 0 {
@@ -76,10 +70,19 @@ func (t *DexPairAlterTask) run() {
 			continue
 		}
 
+		found := make(map[string]bool)
 		for _, info := range infos {
 			if info == nil {
 				continue
 			}
+			found[info.ContractAddress] = true
+			if t.drain.enabled() {
+				f, r := t.observePair(networkId, info, now)
+				fired, resolved = append(fired, f...), append(resolved, r...)
+			}
+			if !t.drain.Summary {
+				continue
+			}
 			text := fmt.Sprintf("### %s Price Alert\n\n"+
 				"- **Price**: $%.6f\n"+
 				"- **Liquidity**: $%s\n"+
@@ -91,6 +94,22 @@ func (t *DexPairAlterTask) run() {
 
 			allTexts = append(allTexts, text)
 		}
+
+		// only a successful request tells that a pair is missing
+		if t.drain.enabled() {
+			for _, addr := range addrs {
+				if !found[addr] {
+					fired = append(fired, t.observeMissing(networkId, addr, now)...)
+				}
+			}
+		}
+	}
+
+	if len(fired) > 0 {
+		sendAlertNotices(t.notifier, "DexPairAlterTask", fmt.Sprintf("%s Rug Alert", t.notifier.GetKeyword()), fired, now)
+	}
+	if len(resolved) > 0 {
+		sendAlertNotices(t.notifier, "DexPairAlterTask", fmt.Sprintf("%s Rug Alert Resolved", t.notifier.GetKeyword()), resolved, now)
 	}
 
 	if len(allTexts) == 0 {
@@ -98,13 +117,13 @@ func (t *DexPairAlterTask) run() {
 	}
 
 	// Aggregate messages
-	unifiedTitle := fmt.Sprintf("%s Price Alerts", t.dingBot.Keyword)
+	unifiedTitle := fmt.Sprintf("%s Price Alerts", t.notifier.GetKeyword())
 
 	unifiedText := fmt.Sprintf("#### %s\n\n%s", unifiedTitle, strings.Join(allTexts, "\n---\n"))
 
-	err := t.dingBot.SendMarkdown(unifiedTitle, unifiedText, nil, false)
+	err := alter.Notify(t.notifier, alter.NewEvent("DexPairAlterTask", constant.SEVERITY_INFO, unifiedTitle, unifiedText))
 	if err != nil {
-		logger.Error("Error sending dingtalk message: %v", err)
+		logger.Error("Error sending notification: %v", err)
 	} else {
 		logger.Info("Sent batch price alerts for %d pairs", len(allTexts))
 	}
@@ -0,0 +1,133 @@
+package tasks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/internal/service"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+// DexDrainOptions configures the rug-pull checks of a DexPairAlterTask. Zero values disable a check.
+type DexDrainOptions struct {
+	LiquidityDropPercent float64       // drop from the highest liquidity within Window
+	PriceDropPercent     float64       // drop from the highest price within Window
+	Window               time.Duration // defaults to 1 hour
+	MissingRuns          int           // consecutive successful fetches that do not return the pair
+	Severity             string        // defaults to critical
+	Cooldown             time.Duration
+	Summary              bool          // send the price/liquidity report on each run
+	Tracker              *AlertTracker // shared by the tasks of one bot, nil for a private tracker
+}
+
+func (o DexDrainOptions) enabled() bool {
+	return o.LiquidityDropPercent > 0 || o.PriceDropPercent > 0 || o.MissingRuns > 0
+}
+
+type dexPairSample struct {
+	at        time.Time
+	price     float64
+	liquidity float64
+}
+
+// dexPairHistory is the recent history of one pair, samples older than the window are dropped.
+type dexPairHistory struct {
+	name    string
+	samples []dexPairSample
+	missing int // consecutive runs without the pair
+}
+
+// observePair records a fetched pair and returns the drain alerts it trips. Drops re-arm silently
+// once the window has moved past the peak, the alert for a missing pair resolves when it is back.
+func (t *DexPairAlterTask) observePair(networkId string, info *service.DexPairInfo, now time.Time) (fired, resolved []alertNotice) {
+	key := networkId + "/" + info.ContractAddress
+	h, ok := t.pairs[key]
+	if !ok {
+		h = &dexPairHistory{}
+		t.pairs[key] = h
+	}
+	h.name = info.Name
+	h.missing = 0
+
+	severity := t.drain.Severity
+	label := fmt.Sprintf("- **%s** (`%s`)", info.Name, info.ContractAddress)
+	if t.drain.MissingRuns > 0 && t.alerts.Observe("dex/"+key+"/missing", false, true, t.drain.Cooldown, now) == ALERT_RESOLVED {
+		resolved = append(resolved, alertNotice{severity: severity, line: label + ": returned by CoinMarketCap again"})
+	}
+	// A pair returned without a quote has no price or liquidity, which is not a drain.
+	if info.Price == 0 && info.Liquidity == 0 {
+		return fired, resolved
+	}
+
+	h.samples = append(h.samples, dexPairSample{at: now, price: info.Price, liquidity: info.Liquidity})
+	start := now.Add(-t.drain.Window)
+	for len(h.samples) > 1 && h.samples[0].at.Before(start) {
+		h.samples = h.samples[1:]
+	}
+	var peakPrice, peakLiquidity float64
+	for _, s := range h.samples {
+		peakPrice = max(peakPrice, s.price)
+		peakLiquidity = max(peakLiquidity, s.liquidity)
+	}
+
+	window := formatWindow(t.drain.Window)
+
+	if t.drain.LiquidityDropPercent > 0 && peakLiquidity > 0 {
+		drop := (peakLiquidity - info.Liquidity) / peakLiquidity * 100
+		triggered := drop >= t.drain.LiquidityDropPercent
+		if t.alerts.Observe("dex/"+key+"/liquidity", triggered, !triggered, t.drain.Cooldown, now) == ALERT_FIRED {
+			fired = append(fired, alertNotice{severity: severity, line: fmt.Sprintf("%s: liquidity -%.1f%% within %s ($%s → $%s)",
+				label, drop, window, formatLiquidity(peakLiquidity), formatLiquidity(info.Liquidity))})
+		}
+	}
+	if t.drain.PriceDropPercent > 0 && peakPrice > 0 {
+		drop := (peakPrice - info.Price) / peakPrice * 100
+		triggered := drop >= t.drain.PriceDropPercent
+		if t.alerts.Observe("dex/"+key+"/price", triggered, !triggered, t.drain.Cooldown, now) == ALERT_FIRED {
+			fired = append(fired, alertNotice{severity: severity, line: fmt.Sprintf("%s: price -%.1f%% within %s ($%s → $%s)",
+				label, drop, window, utils.FormatPrice(peakPrice), utils.FormatPrice(info.Price))})
+		}
+	}
+	return fired, resolved
+}
+
+// observeMissing counts a run in which the pair was not returned although the request succeeded.
+func (t *DexPairAlterTask) observeMissing(networkId, addr string, now time.Time) []alertNotice {
+	key := networkId + "/" + addr
+	h, ok := t.pairs[key]
+	if !ok {
+		h = &dexPairHistory{name: addr}
+		t.pairs[key] = h
+	}
+	h.missing++
+
+	if t.drain.MissingRuns <= 0 || t.alerts.Observe("dex/"+key+"/missing", h.missing >= t.drain.MissingRuns, false, t.drain.Cooldown, now) != ALERT_FIRED {
+		return nil
+	}
+	line := fmt.Sprintf("- **%s** (`%s`): not returned by CoinMarketCap for %d runs, the pool may have been removed", h.name, addr, h.missing)
+	if n := len(h.samples); n > 0 {
+		line += fmt.Sprintf(", last liquidity $%s", formatLiquidity(h.samples[n-1].liquidity))
+	}
+	return []alertNotice{{severity: t.drain.Severity, line: line}}
+}
+
+func formatWindow(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%dm", int(d/time.Minute))
+}
+
+func defaultDrainOptions(o DexDrainOptions) DexDrainOptions {
+	if o.Window <= 0 {
+		o.Window = time.Hour
+	}
+	if o.Severity == "" {
+		o.Severity = constant.SEVERITY_CRITICAL
+	}
+	if o.Tracker == nil {
+		o.Tracker = NewAlertTracker()
+	}
+	return o
+}
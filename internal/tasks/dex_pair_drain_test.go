@@ -0,0 +1,110 @@
+package tasks
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/internal/service"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+type fakeDexPairService struct {
+	pairs map[string]service.DexPairInfo
+}
+
+func (f *fakeDexPairService) GetDexPairInfo(addrs []string, networkSlug, networkId string) ([]*service.DexPairInfo, error) {
+	var infos []*service.DexPairInfo
+	for _, addr := range addrs {
+		if p, ok := f.pairs[addr]; ok {
+			p.ContractAddress = addr
+			infos = append(infos, &p)
+		}
+	}
+	return infos, nil
+}
+
+func TestDexPairAlterTask_Drain(t *testing.T) {
+	svc := &fakeDexPairService{pairs: map[string]service.DexPairInfo{
+		"0xabc": {Name: "MOON/WETH", Price: 1, Liquidity: 1_000_000},
+	}}
+	notifier := &recordingNotifier{keyword: "dex-pair"}
+	drain := DexDrainOptions{LiquidityDropPercent: 50, PriceDropPercent: 80, MissingRuns: 2}
+	task := NewDexPairAlterTask(svc, notifier, map[string][]string{"1": {"0xabc"}}, drain, 60, utils.QuietHoursParams{})
+
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 0 {
+		t.Fatalf("expected no message without a summary, got %+v", events)
+	}
+
+	// liquidity pulled while the price holds
+	svc.pairs["0xabc"] = service.DexPairInfo{Name: "MOON/WETH", Price: 0.9, Liquidity: 300_000}
+	task.Run(context.Background())
+	events := notifier.take()
+	if len(events) != 1 || events[0].Severity != constant.SEVERITY_CRITICAL || !strings.Contains(events[0].Markdown, "liquidity -70.0% within 1h") {
+		t.Fatalf("expected a critical liquidity alert, got %+v", events)
+	}
+
+	// the price collapses too, the liquidity alert is still firing
+	svc.pairs["0xabc"] = service.DexPairInfo{Name: "MOON/WETH", Price: 0.1, Liquidity: 250_000}
+	task.Run(context.Background())
+	events = notifier.take()
+	if len(events) != 1 || !strings.Contains(events[0].Markdown, "price -90.0%") || strings.Contains(events[0].Markdown, "liquidity") {
+		t.Fatalf("expected only the price alert, got %+v", events)
+	}
+
+	// CMC stops returning the pair
+	delete(svc.pairs, "0xabc")
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 0 {
+		t.Fatalf("expected no alert after one missing run, got %+v", events)
+	}
+	task.Run(context.Background())
+	events = notifier.take()
+	if len(events) != 1 || !strings.Contains(events[0].Markdown, "not returned by CoinMarketCap for 2 runs") || !strings.Contains(events[0].Markdown, "MOON/WETH") {
+		t.Fatalf("expected a missing pair alert, got %+v", events)
+	}
+
+	svc.pairs["0xabc"] = service.DexPairInfo{Name: "MOON/WETH", Price: 0.1, Liquidity: 250_000}
+	task.Run(context.Background())
+	events = notifier.take()
+	if len(events) != 1 || !strings.Contains(events[0].Title, "Resolved") {
+		t.Fatalf("expected the pair to be reported back, got %+v", events)
+	}
+}
+
+func TestDexPairAlterTask_DrainWindow(t *testing.T) {
+	task := NewDexPairAlterTask(&fakeDexPairService{}, &recordingNotifier{}, nil, DexDrainOptions{LiquidityDropPercent: 50, Window: 30 * time.Minute}, 60, utils.QuietHoursParams{})
+	start := time.Now()
+
+	task.observePair("1", &service.DexPairInfo{ContractAddress: "0xabc", Liquidity: 1000}, start)
+	// a slow bleed over more than the window is not a drain
+	if fired, _ := task.observePair("1", &service.DexPairInfo{ContractAddress: "0xabc", Liquidity: 700}, start.Add(20*time.Minute)); len(fired) != 0 {
+		t.Fatalf("unexpected alert %+v", fired)
+	}
+	if fired, _ := task.observePair("1", &service.DexPairInfo{ContractAddress: "0xabc", Liquidity: 400}, start.Add(40*time.Minute)); len(fired) != 0 {
+		t.Fatalf("expected the first sample to have left the window, got %+v", fired)
+	}
+	if fired, _ := task.observePair("1", &service.DexPairInfo{ContractAddress: "0xabc", Liquidity: 300}, start.Add(45*time.Minute)); len(fired) != 1 {
+		t.Fatalf("expected 700 -> 300 within 30m to alert, got %+v", fired)
+	}
+}
+
+func TestDexPairAlterTask_DrainSkipsMissingQuote(t *testing.T) {
+	task := NewDexPairAlterTask(&fakeDexPairService{}, &recordingNotifier{}, nil, DexDrainOptions{LiquidityDropPercent: 50, PriceDropPercent: 50}, 60, utils.QuietHoursParams{})
+	start := time.Now()
+
+	task.observePair("1", &service.DexPairInfo{ContractAddress: "0xabc", Price: 1, Liquidity: 1000}, start)
+	// CoinMarketCap returned the pair without a quote
+	if fired, _ := task.observePair("1", &service.DexPairInfo{ContractAddress: "0xabc"}, start.Add(time.Minute)); len(fired) != 0 {
+		t.Fatalf("expected no alert for a pair without a quote, got %+v", fired)
+	}
+	if n := len(task.pairs["1/0xabc"].samples); n != 1 {
+		t.Errorf("expected the empty quote not to be recorded, got %d samples", n)
+	}
+	if fired, _ := task.observePair("1", &service.DexPairInfo{ContractAddress: "0xabc", Price: 0.95, Liquidity: 900}, start.Add(2*time.Minute)); len(fired) != 0 {
+		t.Errorf("expected no alert once the quote is back, got %+v", fired)
+	}
+}
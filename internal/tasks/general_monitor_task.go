@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
@@ -9,7 +10,7 @@ import (
 	"github.com/ka1fe1/crypto-monitoring/internal/service"
 	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
-	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter/dingding"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/polymarket"
 )
@@ -17,28 +18,27 @@ import (
 type GeneralMonitorTask struct {
 	tokenService      service.TokenService
 	polymarketService service.PolymarketMonitorService
-	dingBot           *dingding.DingBot
-	ticker            *time.Ticker
-	stop              chan bool
+	notifier          alter.Notifier
 	modules           []string
 	interval          time.Duration
 	quietHoursParams  utils.QuietHoursParams
-	lastRunTime       time.Time
 	tokenIds          []string
 	rwaTokenIds       []string
 	rwaTokenNames     map[string]string
 	marketIds         []string
+	tokenRules        *tokenRules
 }
 
 func NewGeneralMonitorTask(
 	tokenService service.TokenService,
 	polymarketService service.PolymarketMonitorService,
-	dingBot *dingding.DingBot,
+	notifier alter.Notifier,
 	modules []string,
 	tokenIds []string,
 	rwaTokenIds []string,
 	rwaTokenNames map[string]string,
 	marketIds []string,
+	tokenAlerts TokenPriceAlertOptions, // rules for the token_alerts module, high_low_days is left to TokenPriceMonitorTask
 	intervalSeconds int,
 	quietHoursParams utils.QuietHoursParams,
 ) *GeneralMonitorTask {
@@ -50,8 +50,7 @@ func NewGeneralMonitorTask(
 	return &GeneralMonitorTask{
 		tokenService:      tokenService,
 		polymarketService: polymarketService,
-		dingBot:           dingBot,
-		stop:              make(chan bool),
+		notifier:          notifier,
 		modules:           modules,
 		interval:          interval,
 		quietHoursParams:  quietHoursParams,
@@ -59,34 +58,23 @@ func NewGeneralMonitorTask(
 		rwaTokenIds:       rwaTokenIds,
 		rwaTokenNames:     rwaTokenNames,
 		marketIds:         marketIds,
+		tokenRules:        newTokenRules("GeneralMonitorTask", tokenAlerts.Rules, tokenAlerts.Tracker, tokenAlerts.NotifyResolved),
 	}
 }
 
-func (t *GeneralMonitorTask) Start() {
-	t.ticker = time.NewTicker(t.interval)
-	go func() {
-		for {
-			select {
-			case <-t.ticker.C:
-				t.run()
-			case <-t.stop:
-				t.ticker.Stop()
-				return
-			}
-		}
-	}()
+func (t *GeneralMonitorTask) Name() string {
+	return "GeneralMonitorTask"
 }
 
-func (t *GeneralMonitorTask) Stop() {
-	t.stop <- true
+func (t *GeneralMonitorTask) Interval() time.Duration {
+	return t.interval
 }
 
-func (t *GeneralMonitorTask) run() {
-	if !utils.ShouldExecTask(t.quietHoursParams, t.lastRunTime, t.interval) {
-		return
-	}
-	t.lastRunTime = time.Now()
+func (t *GeneralMonitorTask) QuietHours() utils.QuietHoursParams {
+	return t.quietHoursParams
+}
 
+func (t *GeneralMonitorTask) Run(ctx context.Context) {
 	var parts []string
 	var lastUpdated time.Time
 
@@ -103,6 +91,11 @@ func (t *GeneralMonitorTask) run() {
 		}
 	}
 
+	// 2. Token Alerts Module, sent on their own since alerts must not wait for the summary
+	if t.isModuleEnabled("token_alerts") && len(t.tokenRules.rules) > 0 {
+		t.runTokenAlerts()
+	}
+
 	// 3. Polymarket Module
 	if t.isModuleEnabled("polymarket") && len(t.marketIds) > 0 {
 		polyPart, updated, err := t.getPolymarketContent()
@@ -123,11 +116,11 @@ func (t *GeneralMonitorTask) run() {
 	}
 
 	// Aggregate messages
-	unifiedTitle := fmt.Sprintf("%s General Update", t.dingBot.Keyword)
+	unifiedTitle := fmt.Sprintf("%s General Update", t.notifier.GetKeyword())
 	unifiedText := fmt.Sprintf("## %s\n\n%s", unifiedTitle, strings.Join(parts, "\n\n---\n\n"))
 	unifiedText += fmt.Sprintf("\n\n---\n**Last Updated**: %s", utils.FormatBJTime(lastUpdated))
 
-	err := t.dingBot.SendMarkdown(unifiedTitle, unifiedText, nil, false)
+	err := alter.Notify(t.notifier, alter.NewEvent("GeneralMonitorTask", constant.SEVERITY_INFO, unifiedTitle, unifiedText))
 	if err != nil {
 		logger.Error("Error sending general monitor message: %v", err)
 	} else {
@@ -144,6 +137,18 @@ func (t *GeneralMonitorTask) isModuleEnabled(module string) bool {
 	return false
 }
 
+// runTokenAlerts evaluates the token price rules. The alert tracker is shared with the other
+// tasks of this bot, so a condition TokenPriceMonitorTask already reported is not sent again.
+func (t *GeneralMonitorTask) runTokenAlerts() {
+	prices, err := t.tokenService.GetTokenPrice(ruleTokenIDs(t.tokenRules.rules))
+	if err != nil {
+		logger.Error("Error in GeneralMonitorTask token_alerts: %v", err)
+		return
+	}
+	fired, resolved := t.tokenRules.evaluate(prices, time.Now())
+	t.tokenRules.send(t.notifier, fired, resolved, latestUpdate(prices))
+}
+
 func (t *GeneralMonitorTask) getTokenPriceContent() (string, time.Time, error) {
 	var allTokenIds []string
 	allTokenIds = append(allTokenIds, t.tokenIds...)
@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"context"
 	"log"
 	"strings"
 	"testing"
@@ -71,10 +72,11 @@ func TestGeneralMonitorTask_Run(t *testing.T) {
 		cfg.TokenPriceMonitor.RwaTokenIDs,
 		cfg.TokenPriceMonitor.RwaTokenNames,
 		cfg.PolymarketMonitor.MarketIDs,
+		TokenPriceAlertOptions{},
 		60,
 		utils.QuietHoursParams{Enabled: false},
 	)
 
 	// 6. Run Task
-	task.run()
+	task.Run(context.Background())
 }
@@ -7,253 +7,330 @@ import (
 	"github.com/ka1fe1/crypto-monitoring/internal/service"
 	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
-	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter/dingding"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alternative"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/bgeometrics"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/binance"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/cron"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/mempool"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/polymarket"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/tsdb"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/twitter"
 )
 
+// pauseQuietHours pauses a task during 00:00-endHour.
+func pauseQuietHours(endHour int) utils.QuietHoursParams {
+	return utils.QuietHoursParams{Enabled: true, StartHour: 0, EndHour: endHour, Behavior: constant.QUIET_HOURS_BEHAVIOR_PAUSE}
+}
+
+// throttleQuietHours slows a task down by multiplier during 00:00-endHour.
+func throttleQuietHours(endHour, multiplier int) utils.QuietHoursParams {
+	return utils.QuietHoursParams{Enabled: true, StartHour: 0, EndHour: endHour, Behavior: constant.QUIET_HOURS_BEHAVIOR_THROTTLE, ThrottleMultiplier: multiplier}
+}
+
+// quietHoursParams converts the task config, falling back to def when the task has none.
+// Invalid timezones, windows and dates are logged and ignored.
+func quietHoursParams(taskName string, qh *config.QuietHoursConfig, def utils.QuietHoursParams) utils.QuietHoursParams {
+	if qh == nil {
+		return def
+	}
+	params := utils.QuietHoursParams{
+		Behavior: qh.Behavior,
+	}
+	if qh.ThrottleMultiplier != nil {
+		params.ThrottleMultiplier = *qh.ThrottleMultiplier
+	}
+	if qh.Enabled != nil {
+		params.Enabled = *qh.Enabled
+	}
+	if qh.StartHour != nil {
+		params.StartHour = *qh.StartHour
+	}
+	if qh.EndHour != nil {
+		params.EndHour = *qh.EndHour
+	}
+
+	if qh.Timezone != "" {
+		loc, err := time.LoadLocation(qh.Timezone)
+		if err != nil {
+			logger.Error("Invalid quiet hours timezone %q for %s, using Beijing time: %v", qh.Timezone, taskName, err)
+		} else {
+			params.Location = loc
+		}
+	}
+
+	for i, w := range qh.Windows {
+		window, err := parseQuietWindow(w)
+		if err != nil {
+			logger.Error("Ignoring quiet hours window %d for %s: %v", i+1, taskName, err)
+			continue
+		}
+		params.Windows = append(params.Windows, window)
+	}
+	if len(qh.Windows) > 0 && len(params.Windows) == 0 {
+		logger.Warn("No valid quiet hours window for %s, using %02d:00-%02d:00", taskName, params.StartHour, params.EndHour)
+	}
+
+	for _, d := range qh.Holidays {
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			logger.Error("Ignoring quiet hours holiday %q for %s: %v", d, taskName, err)
+			continue
+		}
+		if params.Holidays == nil {
+			params.Holidays = make(map[string]struct{})
+		}
+		params.Holidays[d] = struct{}{}
+	}
+
+	return params
+}
+
+func parseQuietWindow(w config.QuietWindowConfig) (utils.QuietWindow, error) {
+	start, err := utils.ParseClock(w.Start)
+	if err != nil {
+		return utils.QuietWindow{}, err
+	}
+	end, err := utils.ParseClock(w.End)
+	if err != nil {
+		return utils.QuietWindow{}, err
+	}
+	days, err := utils.ParseWeekdays(w.Days)
+	if err != nil {
+		return utils.QuietWindow{}, err
+	}
+	return utils.QuietWindow{
+		Start:              start,
+		End:                end,
+		Days:               days,
+		Behavior:           w.Behavior,
+		ThrottleMultiplier: w.ThrottleMultiplier,
+	}, nil
+}
+
+// lookupNotifier returns the notifier named botName, warning when the task has to be skipped.
+// Events below minSeverity are dropped.
+func lookupNotifier(notifiers map[string]alter.Notifier, botName, minSeverity, taskName string) alter.Notifier {
+	n := notifiers[botName]
+	if n == nil {
+		logger.Warn("Warning: Bot %s not found for %s", botName, taskName)
+		return nil
+	}
+	if alter.SeverityRank(minSeverity) < 0 {
+		logger.Warn("Unknown min_severity %q for %s, sending every event", minSeverity, taskName)
+	}
+	return alter.WithMinSeverity(n, minSeverity)
+}
+
+// register adds t to the scheduler, on its cron schedule when one is configured.
+// A task with an invalid schedule is not started.
+func register(scheduler *Scheduler, t Task, schedule string, opts ...RegisterOption) {
+	if schedule != "" {
+		sched, err := cron.Parse(schedule)
+		if err != nil {
+			logger.Error("Not starting %s: %v", t.Name(), err)
+			return
+		}
+		opts = append(opts, WithSchedule(sched))
+	}
+	scheduler.Register(t, opts...)
+}
+
+// tokenPriceAlertOptions builds the rule options shared by TokenPriceMonitorTask and the token_alerts module.
+func tokenPriceAlertOptions(tp *config.TokenPriceMonitorConfig, tracker *AlertTracker) TokenPriceAlertOptions {
+	opts := TokenPriceAlertOptions{
+		Rules:          tp.Rules,
+		Summary:        len(tp.Rules) == 0,
+		NotifyResolved: true,
+		HistoryPath:    tp.HistoryPath,
+		Tracker:        tracker,
+	}
+	if tp.Summary != nil {
+		opts.Summary = *tp.Summary
+	}
+	if tp.NotifyResolved != nil {
+		opts.NotifyResolved = *tp.NotifyResolved
+	}
+	return opts
+}
+
+// InitTasks builds the enabled tasks and registers them on a scheduler, which the caller starts.
 func InitTasks(
 	cfg *config.Config,
-	dingBots map[string]*dingding.DingBot,
+	notifiers map[string]alter.Notifier,
 	dexService service.DexPairService,
 	tokenService service.TokenService,
 	openSeaService service.OpenSeaService,
 	polyClient *polymarket.Client,
 	twitterClient *twitter.TwitterClient,
-) {
-	// Create services
+	twitterCursors *service.TwitterCursors,
+	store *tsdb.Store,
+) *Scheduler {
+	scheduler := NewScheduler()
+
+	// Create services, everything the tasks fetch is recorded in the metric store when there is one
+	var recorder *service.MetricRecorder
+	if store != nil {
+		recorder = service.NewMetricRecorder(store)
+		register(scheduler, NewMetricStoreRetentionTask(store), "", RunOnStart())
+	}
+	dexService = service.RecordingDexPairService(dexService, recorder)
+	tokenService = service.RecordingTokenService(tokenService, recorder)
+	openSeaService = service.RecordingOpenSeaService(openSeaService, recorder)
 	twitterMonitorService := service.NewTwitterService(twitterClient)
-	polymarketService := service.NewPolymarketMonitorService(polyClient)
+	polymarketService := service.RecordingPolymarketMonitorService(service.NewPolymarketMonitorService(polyClient), recorder)
+	btcDashboardService := func(cfg *config.BtcDashboardMonitorConfig) service.BtcDashboardService {
+		return service.RecordingBtcDashboardService(newBtcDashboardService(cfg), recorder)
+	}
+	// alert state is shared by the tasks of a bot so they do not repeat each other's alerts
+	trackers := newAlertTrackers()
 
 	// 1. DexPairAlterTask
-	if cfg.DexPairAlter.IntervalSeconds > 0 {
-		dexBot := dingBots[cfg.DexPairAlter.BotName]
-		if dexBot != nil {
-			var qh utils.QuietHoursParams
-			if cfg.DexPairAlter.QuietHours != nil {
-				qh = utils.QuietHoursParams{
-					Enabled:            cfg.DexPairAlter.QuietHours.Enabled,
-					StartHour:          cfg.DexPairAlter.QuietHours.StartHour,
-					EndHour:            cfg.DexPairAlter.QuietHours.EndHour,
-					Behavior:           cfg.DexPairAlter.QuietHours.Behavior,
-					ThrottleMultiplier: cfg.DexPairAlter.QuietHours.ThrottleMultiplier,
-				}
-			} else {
-				// Default: Pause during 00:00-08:00
-				qh = utils.QuietHoursParams{Enabled: true, StartHour: 0, EndHour: 8, Behavior: constant.QUIET_HOURS_BEHAVIOR_PAUSE}
+	if cfg.DexPairAlter.IntervalSeconds > 0 || cfg.DexPairAlter.Schedule != "" {
+		if bot := lookupNotifier(notifiers, cfg.DexPairAlter.BotName, cfg.DexPairAlter.MinSeverity, "DexPairAlterTask"); bot != nil {
+			qh := quietHoursParams("DexPairAlterTask", cfg.DexPairAlter.QuietHours, pauseQuietHours(8))
+			d := cfg.DexPairAlter.Drain
+			drain := DexDrainOptions{
+				LiquidityDropPercent: d.LiquidityDropPercent,
+				PriceDropPercent:     d.PriceDropPercent,
+				Window:               time.Duration(d.WindowMinutes) * time.Minute,
+				MissingRuns:          d.MissingRuns,
+				Severity:             d.Severity,
+				Cooldown:             time.Duration(d.CooldownMinutes) * time.Minute,
+				Summary:              cfg.DexPairAlter.Summary == nil || *cfg.DexPairAlter.Summary,
+				Tracker:              trackers.forBot(cfg.DexPairAlter.BotName),
 			}
-			NewDexPairAlterTask(dexService, dexBot, cfg.DexPairAlter.ContractAddrInfo, cfg.DexPairAlter.IntervalSeconds, qh).Start()
-		} else {
-			logger.Warn("Warning: Bot %s not found for DexPairAlterTask", cfg.DexPairAlter.BotName)
+			register(scheduler, NewDexPairAlterTask(dexService, bot, cfg.DexPairAlter.ContractAddrInfo, drain, cfg.DexPairAlter.IntervalSeconds, qh), cfg.DexPairAlter.Schedule)
 		}
 	}
 
 	// 2. TokenPriceMonitorTask
-	if cfg.TokenPriceMonitor.IntervalSeconds > 0 {
-		tokenBot := dingBots[cfg.TokenPriceMonitor.BotName]
-		if tokenBot != nil {
-			var qh utils.QuietHoursParams
-			if cfg.TokenPriceMonitor.QuietHours != nil {
-				qh = utils.QuietHoursParams{
-					Enabled:            cfg.TokenPriceMonitor.QuietHours.Enabled,
-					StartHour:          cfg.TokenPriceMonitor.QuietHours.StartHour,
-					EndHour:            cfg.TokenPriceMonitor.QuietHours.EndHour,
-					Behavior:           cfg.TokenPriceMonitor.QuietHours.Behavior,
-					ThrottleMultiplier: cfg.TokenPriceMonitor.QuietHours.ThrottleMultiplier,
-				}
-			} else {
-				// Default: Pause during 00:00-08:00, Throttle
-				qh = utils.QuietHoursParams{Enabled: true, StartHour: 0, EndHour: 8, Behavior: constant.QUIET_HOURS_BEHAVIOR_THROTTLE, ThrottleMultiplier: 5}
-			}
-			NewTokenPriceMonitorTask(tokenService, tokenBot, cfg.TokenPriceMonitor.TokenIds, cfg.TokenPriceMonitor.RwaTokenIDs, cfg.TokenPriceMonitor.RwaTokenNames, cfg.TokenPriceMonitor.IntervalSeconds, qh).Start()
-		} else {
-			logger.Warn("Warning: Bot %s not found for TokenPriceMonitorTask", cfg.TokenPriceMonitor.BotName)
+	if cfg.TokenPriceMonitor.IntervalSeconds > 0 || cfg.TokenPriceMonitor.Schedule != "" {
+		if bot := lookupNotifier(notifiers, cfg.TokenPriceMonitor.BotName, cfg.TokenPriceMonitor.MinSeverity, "TokenPriceMonitorTask"); bot != nil {
+			qh := quietHoursParams("TokenPriceMonitorTask", cfg.TokenPriceMonitor.QuietHours, throttleQuietHours(8, 5))
+			tp := cfg.TokenPriceMonitor
+			alerts := tokenPriceAlertOptions(&tp, trackers.forBot(tp.BotName))
+			register(scheduler, NewTokenPriceMonitorTask(tokenService, bot, tp.TokenIds, tp.RwaTokenIDs, tp.RwaTokenNames, alerts, tp.IntervalSeconds, qh), tp.Schedule)
 		}
 	}
 
 	// 3. NFTFloorPriceMonitorTask
-	if cfg.NFTFloorPriceMonitor.IntervalSeconds > 0 {
-		nftBot := dingBots[cfg.NFTFloorPriceMonitor.BotName]
-		if nftBot != nil {
-			var qh utils.QuietHoursParams
-			if cfg.NFTFloorPriceMonitor.QuietHours != nil {
-				qh = utils.QuietHoursParams{
-					Enabled:            cfg.NFTFloorPriceMonitor.QuietHours.Enabled,
-					StartHour:          cfg.NFTFloorPriceMonitor.QuietHours.StartHour,
-					EndHour:            cfg.NFTFloorPriceMonitor.QuietHours.EndHour,
-					Behavior:           cfg.NFTFloorPriceMonitor.QuietHours.Behavior,
-					ThrottleMultiplier: cfg.NFTFloorPriceMonitor.QuietHours.ThrottleMultiplier,
-				}
-			} else {
-				// Default: Pause during 00:00-08:00
-				qh = utils.QuietHoursParams{Enabled: true, StartHour: 0, EndHour: 8, Behavior: constant.QUIET_HOURS_BEHAVIOR_PAUSE}
+	if cfg.NFTFloorPriceMonitor.IntervalSeconds > 0 || cfg.NFTFloorPriceMonitor.Schedule != "" {
+		if bot := lookupNotifier(notifiers, cfg.NFTFloorPriceMonitor.BotName, cfg.NFTFloorPriceMonitor.MinSeverity, "NFTFloorPriceMonitorTask"); bot != nil {
+			qh := quietHoursParams("NFTFloorPriceMonitorTask", cfg.NFTFloorPriceMonitor.QuietHours, pauseQuietHours(8))
+			nft := cfg.NFTFloorPriceMonitor
+			alerts := NFTFloorAlertOptions{
+				Alerts:      nft.Alerts,
+				Summary:     len(nft.Alerts) == 0,
+				HistoryPath: nft.HistoryPath,
+				Tracker:     trackers.forBot(nft.BotName),
 			}
-			NewNFTFloorPriceMonitorTask(openSeaService, nftBot, cfg.NFTFloorPriceMonitor.NFTCollections, cfg.NFTFloorPriceMonitor.IntervalSeconds, qh).Start()
-		} else {
-			logger.Warn("Warning: Bot %s not found for NFTFloorPriceMonitorTask", cfg.NFTFloorPriceMonitor.BotName)
+			if nft.Summary != nil {
+				alerts.Summary = *nft.Summary
+			}
+			register(scheduler, NewNFTFloorPriceMonitorTask(openSeaService, bot, nft.NFTCollections, alerts, nft.IntervalSeconds, qh), nft.Schedule)
 		}
 	}
 
 	// 4. PolymarketMonitorTask
-	if cfg.PolymarketMonitor.IntervalSeconds > 0 {
-		polyBot := dingBots[cfg.PolymarketMonitor.BotName]
-		if polyBot != nil {
-			var qh utils.QuietHoursParams
-			if cfg.PolymarketMonitor.QuietHours != nil {
-				qh = utils.QuietHoursParams{
-					Enabled:            cfg.PolymarketMonitor.QuietHours.Enabled,
-					StartHour:          cfg.PolymarketMonitor.QuietHours.StartHour,
-					EndHour:            cfg.PolymarketMonitor.QuietHours.EndHour,
-					Behavior:           cfg.PolymarketMonitor.QuietHours.Behavior,
-					ThrottleMultiplier: cfg.PolymarketMonitor.QuietHours.ThrottleMultiplier,
-				}
-			} else {
-				// Default: Pause during 00:00-08:00
-				qh = utils.QuietHoursParams{Enabled: true, StartHour: 0, EndHour: 8, Behavior: constant.QUIET_HOURS_BEHAVIOR_PAUSE}
+	if cfg.PolymarketMonitor.IntervalSeconds > 0 || cfg.PolymarketMonitor.Schedule != "" {
+		if bot := lookupNotifier(notifiers, cfg.PolymarketMonitor.BotName, cfg.PolymarketMonitor.MinSeverity, "PolymarketMonitorTask"); bot != nil {
+			qh := quietHoursParams("PolymarketMonitorTask", cfg.PolymarketMonitor.QuietHours, pauseQuietHours(8))
+			pm := cfg.PolymarketMonitor
+			alerts := PolymarketAlertOptions{
+				Rules:        pm.Rules,
+				Summary:      len(pm.Rules) == 0,
+				NotifyClosed: pm.NotifyClosed == nil || *pm.NotifyClosed,
+				StatePath:    pm.StatePath,
+				Tracker:      trackers.forBot(pm.BotName),
 			}
-			NewPolymarketMonitorTask(polymarketService, polyBot, cfg.PolymarketMonitor.MarketIDs, cfg.PolymarketMonitor.IntervalSeconds, qh).Start()
-		} else {
-			logger.Warn("Warning: Bot %s not found for PolymarketMonitorTask", cfg.PolymarketMonitor.BotName)
+			if pm.Summary != nil {
+				alerts.Summary = *pm.Summary
+			}
+			register(scheduler, NewPolymarketMonitorTask(polymarketService, bot, pm.MarketIDs, alerts, pm.IntervalSeconds, qh), pm.Schedule)
 		}
 	}
 
 	// 5. TwitterMonitorTask
-	if cfg.TwitterMonitor.IntervalSeconds > 0 {
-		twitterBot := dingBots[cfg.TwitterMonitor.BotName]
-		if twitterBot != nil {
-			var qh utils.QuietHoursParams
-			if cfg.TwitterMonitor.QuietHours != nil {
-				qh = utils.QuietHoursParams{
-					Enabled:            cfg.TwitterMonitor.QuietHours.Enabled,
-					StartHour:          cfg.TwitterMonitor.QuietHours.StartHour,
-					EndHour:            cfg.TwitterMonitor.QuietHours.EndHour,
-					Behavior:           cfg.TwitterMonitor.QuietHours.Behavior,
-					ThrottleMultiplier: cfg.TwitterMonitor.QuietHours.ThrottleMultiplier,
-				}
-			} else {
-				// Default: Pause during 00:00-07:00
-				qh = utils.QuietHoursParams{Enabled: true, StartHour: 0, EndHour: 7, Behavior: constant.QUIET_HOURS_BEHAVIOR_PAUSE}
-			}
-			NewTwitterMonitorTask(twitterMonitorService, twitterBot, cfg.TwitterMonitor.Usernames, cfg.TwitterMonitor.Keywords, cfg.TwitterMonitor.WithinTime, cfg.TwitterMonitor.IntervalSeconds, qh).Start()
-		} else {
-			logger.Warn("Warning: Bot %s not found for TwitterMonitorTask", cfg.TwitterMonitor.BotName)
+	if cfg.TwitterMonitor.IntervalSeconds > 0 || cfg.TwitterMonitor.Schedule != "" {
+		if bot := lookupNotifier(notifiers, cfg.TwitterMonitor.BotName, cfg.TwitterMonitor.MinSeverity, "TwitterMonitorTask"); bot != nil {
+			qh := quietHoursParams("TwitterMonitorTask", cfg.TwitterMonitor.QuietHours, pauseQuietHours(7))
+			register(scheduler, NewTwitterMonitorTask(twitterMonitorService, bot, cfg.TwitterMonitor.Usernames, cfg.TwitterMonitor.Keywords, cfg.TwitterMonitor.WithinTime, twitterCursors, cfg.TwitterMonitor.IntervalSeconds, qh), cfg.TwitterMonitor.Schedule, RunOnStart())
 		}
 	}
 
 	// 6. GeneralMonitorTask
-	if cfg.GeneralMonitor.IntervalSeconds > 0 {
-		generalBot := dingBots[cfg.GeneralMonitor.BotName]
-		if generalBot != nil {
-			var qh utils.QuietHoursParams
-			if cfg.GeneralMonitor.QuietHours != nil {
-				qh = utils.QuietHoursParams{
-					Enabled:            cfg.GeneralMonitor.QuietHours.Enabled,
-					StartHour:          cfg.GeneralMonitor.QuietHours.StartHour,
-					EndHour:            cfg.GeneralMonitor.QuietHours.EndHour,
-					Behavior:           cfg.GeneralMonitor.QuietHours.Behavior,
-					ThrottleMultiplier: cfg.GeneralMonitor.QuietHours.ThrottleMultiplier,
-				}
-			} else {
-				// Default: Pause during 00:00-07:00
-				qh = utils.QuietHoursParams{Enabled: true, StartHour: 0, EndHour: 8, Behavior: constant.QUIET_HOURS_BEHAVIOR_THROTTLE, ThrottleMultiplier: 5}
-			}
-
-			NewGeneralMonitorTask(
+	if cfg.GeneralMonitor.IntervalSeconds > 0 || cfg.GeneralMonitor.Schedule != "" {
+		if bot := lookupNotifier(notifiers, cfg.GeneralMonitor.BotName, cfg.GeneralMonitor.MinSeverity, "GeneralMonitorTask"); bot != nil {
+			qh := quietHoursParams("GeneralMonitorTask", cfg.GeneralMonitor.QuietHours, throttleQuietHours(8, 5))
+			register(scheduler, NewGeneralMonitorTask(
 				tokenService,
 				polymarketService,
-				generalBot,
+				bot,
 				cfg.GeneralMonitor.Modules,
 				cfg.TokenPriceMonitor.TokenIDs,
 				cfg.TokenPriceMonitor.RwaTokenIDs,
 				cfg.TokenPriceMonitor.RwaTokenNames,
 				cfg.PolymarketMonitor.MarketIDs,
+				tokenPriceAlertOptions(&cfg.TokenPriceMonitor, trackers.forBot(cfg.GeneralMonitor.BotName)),
 				cfg.GeneralMonitor.IntervalSeconds,
 				qh,
-			).Start()
-		} else {
-			logger.Warn("Warning: Bot %s not found for GeneralMonitorTask", cfg.GeneralMonitor.BotName)
+			), cfg.GeneralMonitor.Schedule)
 		}
 	}
 
-	// 7. PolymarketDailyReportTask
-	if cfg.PolymarketReport.IntervalSeconds > 0 && cfg.PolymarketReport.AddressListFile != "" && cfg.PolymarketReport.OutputDir != "" {
-		var qh utils.QuietHoursParams
-		if cfg.PolymarketReport.QuietHours != nil {
-			qh = utils.QuietHoursParams{
-				Enabled:            cfg.PolymarketReport.QuietHours.Enabled,
-				StartHour:          cfg.PolymarketReport.QuietHours.StartHour,
-				EndHour:            cfg.PolymarketReport.QuietHours.EndHour,
-				Behavior:           cfg.PolymarketReport.QuietHours.Behavior,
-				ThrottleMultiplier: cfg.PolymarketReport.QuietHours.ThrottleMultiplier,
-			}
-		} else {
-			// Default: Pause during 00:00-08:00
-			qh = utils.QuietHoursParams{Enabled: true, StartHour: 0, EndHour: 8, Behavior: constant.QUIET_HOURS_BEHAVIOR_PAUSE}
-		}
-
-		task := NewPolymarketDailyReportTask(cfg, polyClient, cfg.PolymarketReport.IntervalSeconds, qh)
-		interval := time.Duration(cfg.PolymarketReport.IntervalSeconds) * time.Second
-		go func() {
-			// Run immediately once on startup
-			task.Run()
-
-			// Then run at configured interval
-			ticker := time.NewTicker(interval)
-			defer ticker.Stop()
-			for range ticker.C {
-				task.Run()
-			}
-		}()
+	// 7. PolymarketDailyReportTask, the notifier is optional
+	if (cfg.PolymarketReport.IntervalSeconds > 0 || cfg.PolymarketReport.Schedule != "") && cfg.PolymarketReport.AddressListFile != "" && cfg.PolymarketReport.OutputDir != "" {
+		qh := quietHoursParams("PolymarketDailyReportTask", cfg.PolymarketReport.QuietHours, pauseQuietHours(8))
+		register(scheduler, NewPolymarketDailyReportTask(cfg, polyClient, alter.WithMinSeverity(notifiers[cfg.PolymarketReport.BotName], cfg.PolymarketReport.MinSeverity), cfg.PolymarketReport.IntervalSeconds, qh), cfg.PolymarketReport.Schedule, RunOnStart())
 	}
 
 	// 8. BtcDashboardMonitorTask
-	if cfg.BtcDashboardMonitor.IntervalSeconds > 0 {
-		binApi, memApi, altApi := "https://api.binance.com", "https://mempool.space", "https://api.alternative.me"
-		if cfg.BtcDashboardMonitor.BinanceApiUrl != "" {
-			binApi = cfg.BtcDashboardMonitor.BinanceApiUrl
-		}
-		if cfg.BtcDashboardMonitor.MempoolApiUrl != "" {
-			memApi = cfg.BtcDashboardMonitor.MempoolApiUrl
-		}
-		if cfg.BtcDashboardMonitor.AlternativeApiUrl != "" {
-			altApi = cfg.BtcDashboardMonitor.AlternativeApiUrl
-		}
-		bgApi := cfg.BtcDashboardMonitor.BgeometricsApiUrl
-		bgKey := cfg.BtcDashboardMonitor.BgeometricsApiKey
-		var bgOpts []bgeometrics.Option
-		if cfg.BtcDashboardMonitor.BgeometricsTimeout > 0 {
-			bgOpts = append(bgOpts, bgeometrics.WithTimeout(time.Duration(cfg.BtcDashboardMonitor.BgeometricsTimeout)*time.Second))
+	if cfg.BtcDashboardMonitor.IntervalSeconds > 0 || cfg.BtcDashboardMonitor.Schedule != "" {
+		if bot := lookupNotifier(notifiers, cfg.BtcDashboardMonitor.BotName, cfg.BtcDashboardMonitor.MinSeverity, "BtcDashboardMonitorTask"); bot != nil {
+			qh := quietHoursParams("BtcDashboardMonitorTask", cfg.BtcDashboardMonitor.QuietHours, pauseQuietHours(8))
+			bm := cfg.BtcDashboardMonitor
+			alerts := BtcZoneAlertOptions{
+				Enabled:   bm.ZoneAlerts == nil || *bm.ZoneAlerts,
+				Summary:   bm.Summary == nil || *bm.Summary,
+				StatePath: bm.StatePath,
+			}
+			register(scheduler, NewBtcDashboardMonitorTask(btcDashboardService(&bm), bot, alerts, bm.IntervalSeconds, qh), bm.Schedule)
 		}
-		btcDashboardService := service.NewBtcDashboardService(
-			binance.NewClient(binApi),
-			mempool.NewClient(memApi),
-			alternative.NewClient(altApi),
-			bgeometrics.NewClient(bgApi, bgKey, bgOpts...),
-		)
+	}
 
-		btcBot := dingBots[cfg.BtcDashboardMonitor.BotName]
-		if btcBot != nil {
-			var qh utils.QuietHoursParams
-			if cfg.BtcDashboardMonitor.QuietHours != nil {
-				qh = utils.QuietHoursParams{
-					Enabled:            cfg.BtcDashboardMonitor.QuietHours.Enabled,
-					StartHour:          cfg.BtcDashboardMonitor.QuietHours.StartHour,
-					EndHour:            cfg.BtcDashboardMonitor.QuietHours.EndHour,
-					Behavior:           cfg.BtcDashboardMonitor.QuietHours.Behavior,
-					ThrottleMultiplier: cfg.BtcDashboardMonitor.QuietHours.ThrottleMultiplier,
-				}
-			} else {
-				// Default: Pause during 00:00-08:00
-				qh = utils.QuietHoursParams{Enabled: true, StartHour: 0, EndHour: 8, Behavior: constant.QUIET_HOURS_BEHAVIOR_PAUSE}
-			}
-			NewBtcDashboardMonitorTask(btcDashboardService, btcBot, cfg.BtcDashboardMonitor.IntervalSeconds, qh).Start()
-		} else {
-			logger.Warn("Warning: Bot %s not found for BtcDashboardMonitorTask", cfg.BtcDashboardMonitor.BotName)
+	// 9. RuleEvaluatorTask
+	if (cfg.RuleEvaluator.IntervalSeconds > 0 || cfg.RuleEvaluator.Schedule != "") && len(cfg.RuleEvaluator.Rules) > 0 {
+		if bot := lookupNotifier(notifiers, cfg.RuleEvaluator.BotName, cfg.RuleEvaluator.MinSeverity, "RuleEvaluatorTask"); bot != nil {
+			qh := quietHoursParams("RuleEvaluatorTask", cfg.RuleEvaluator.QuietHours, pauseQuietHours(8))
+			metrics := service.NewMetricsService(btcDashboardService(&cfg.BtcDashboardMonitor), tokenService, openSeaService, polymarketService)
+			notifyResolved := cfg.RuleEvaluator.NotifyResolved == nil || *cfg.RuleEvaluator.NotifyResolved
+			register(scheduler, NewRuleEvaluatorTask(metrics, bot, cfg.RuleEvaluator.Rules, notifyResolved, trackers.forBot(cfg.RuleEvaluator.BotName), cfg.RuleEvaluator.IntervalSeconds, qh), cfg.RuleEvaluator.Schedule)
 		}
 	}
+
+	return scheduler
+}
+
+func newBtcDashboardService(cfg *config.BtcDashboardMonitorConfig) service.BtcDashboardService {
+	binApi, memApi, altApi := "https://api.binance.com", "https://mempool.space", "https://api.alternative.me"
+	if cfg.BinanceApiUrl != "" {
+		binApi = cfg.BinanceApiUrl
+	}
+	if cfg.MempoolApiUrl != "" {
+		memApi = cfg.MempoolApiUrl
+	}
+	if cfg.AlternativeApiUrl != "" {
+		altApi = cfg.AlternativeApiUrl
+	}
+	var bgOpts []bgeometrics.Option
+	if cfg.BgeometricsTimeout > 0 {
+		bgOpts = append(bgOpts, bgeometrics.WithTimeout(time.Duration(cfg.BgeometricsTimeout)*time.Second))
+	}
+	return service.NewBtcDashboardService(
+		binance.NewClient(binApi),
+		mempool.NewClient(memApi),
+		alternative.NewClient(altApi),
+		bgeometrics.NewClient(cfg.BgeometricsApiUrl, cfg.BgeometricsApiKey, bgOpts...),
+	)
 }
@@ -0,0 +1,38 @@
+package tasks
+
+import (
+	"context"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/tsdb"
+)
+
+// MetricStoreRetentionTask drops the samples and rollup buckets of the metric store that are past
+// their retention.
+type MetricStoreRetentionTask struct {
+	store *tsdb.Store
+}
+
+func NewMetricStoreRetentionTask(store *tsdb.Store) *MetricStoreRetentionTask {
+	return &MetricStoreRetentionTask{store: store}
+}
+
+func (t *MetricStoreRetentionTask) Name() string {
+	return "MetricStoreRetentionTask"
+}
+
+func (t *MetricStoreRetentionTask) Interval() time.Duration {
+	return time.Hour
+}
+
+func (t *MetricStoreRetentionTask) QuietHours() utils.QuietHoursParams {
+	return utils.QuietHoursParams{}
+}
+
+func (t *MetricStoreRetentionTask) Run(ctx context.Context) {
+	if err := t.store.Prune(time.Now()); err != nil {
+		logger.Error("Failed to prune metric store: %v", err)
+	}
+}
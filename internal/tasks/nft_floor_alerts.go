@@ -0,0 +1,156 @@
+package tasks
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/config"
+	"github.com/ka1fe1/crypto-monitoring/internal/service"
+	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+// NFTFloorAlertOptions configures the floor change alerts of an NFTFloorPriceMonitorTask.
+type NFTFloorAlertOptions struct {
+	Alerts      []config.NFTFloorAlertConfig
+	Summary     bool          // send every collection's floor on each run
+	HistoryPath string        // floor samples, needed to find the baseline of each window
+	Tracker     *AlertTracker // shared by the tasks of one bot, nil for a private tracker
+}
+
+type nftFloorAlert struct {
+	collection    string
+	window        time.Duration
+	label         string
+	nativePercent float64
+	usdPercent    float64
+	severity      string
+	cooldown      time.Duration
+}
+
+// compileNFTFloorAlerts parses the alert windows, invalid alerts are logged and skipped.
+func compileNFTFloorAlerts(alerts []config.NFTFloorAlertConfig) []nftFloorAlert {
+	var compiled []nftFloorAlert
+	for _, a := range alerts {
+		window, err := utils.ParseWindow(a.Window)
+		if err != nil {
+			logger.Error("NFTFloorPriceMonitorTask: skipping alert for %q: %v", a.Collection, err)
+			continue
+		}
+		severity := a.Severity
+		if severity == "" {
+			severity = constant.SEVERITY_WARNING
+		}
+		compiled = append(compiled, nftFloorAlert{
+			collection:    a.Collection,
+			window:        window,
+			label:         strings.TrimSpace(a.Window),
+			nativePercent: a.NativePercent,
+			usdPercent:    a.UsdPercent,
+			severity:      severity,
+			cooldown:      time.Duration(a.CooldownMinutes) * time.Minute,
+		})
+	}
+	return compiled
+}
+
+// evaluateFloorAlerts compares the floor with its baseline, the last sample recorded before the start
+// of each window. Both currencies are shown so a USD move caused by the token price (e.g. ETH)
+// is not mistaken for a collection-specific dump.
+func (t *NFTFloorPriceMonitorTask) evaluateFloorAlerts(info service.NFTFloorPriceInfo, now time.Time) []alertNotice {
+	var notices []alertNotice
+	for _, a := range t.alerts {
+		if a.collection != "" && a.collection != info.CollectionSlug {
+			continue
+		}
+		base, ok := t.history.baseline(info.CollectionSlug, now.Add(-a.window), a.window)
+		if !ok || base.Floor <= 0 {
+			continue
+		}
+
+		key := fmt.Sprintf("nft/%s/%s", info.CollectionSlug, a.label)
+		nativeChange := (info.FloorPrice - base.Floor) / base.Floor * 100
+		nativeHit := a.nativePercent > 0 && math.Abs(nativeChange) >= a.nativePercent
+		nativeFired := t.tracker.Observe(key+"/native", nativeHit, !nativeHit, a.cooldown, now) == ALERT_FIRED
+
+		hasUsd := base.FloorUSD > 0 && info.FloorPriceUSD > 0
+		var usdChange float64
+		usdFired := false
+		if hasUsd {
+			usdChange = (info.FloorPriceUSD - base.FloorUSD) / base.FloorUSD * 100
+			usdHit := a.usdPercent > 0 && math.Abs(usdChange) >= a.usdPercent
+			usdFired = t.tracker.Observe(key+"/usd", usdHit, !usdHit, a.cooldown, now) == ALERT_FIRED
+		}
+		if !nativeFired && !usdFired {
+			continue
+		}
+
+		line := fmt.Sprintf("- **%s** %s: floor %+.1f%% (%s → %s %s)", info.CollectionSlug, a.label,
+			nativeChange, utils.FormatPrice(base.Floor), utils.FormatPrice(info.FloorPrice), info.FloorPriceSymbol)
+		if hasUsd {
+			line += fmt.Sprintf(", USD %+.1f%% ($%s → $%s)", usdChange, utils.FormatPrice(base.FloorUSD), utils.FormatPrice(info.FloorPriceUSD))
+		}
+		if usdFired && !nativeHit {
+			tokenChange := ((1+usdChange/100)/(1+nativeChange/100) - 1) * 100
+			line += fmt.Sprintf("\n  mostly the %s price (%+.1f%%), the floor itself moved %+.1f%%", info.FloorPriceSymbol, tokenChange, nativeChange)
+		}
+		notices = append(notices, alertNotice{severity: a.severity, line: line})
+	}
+	return notices
+}
+
+type nftFloorSample struct {
+	At       time.Time `json:"at"`
+	Floor    float64   `json:"floor"`
+	FloorUSD float64   `json:"floor_usd,omitempty"`
+}
+
+// nftFloorHistory keeps recent floor samples per collection in a JSON file so windows survive restarts.
+type nftFloorHistory struct {
+	path    string
+	samples map[string][]nftFloorSample // slug -> samples, oldest first
+}
+
+func loadNFTFloorHistory(path string) *nftFloorHistory {
+	h := &nftFloorHistory{path: path}
+	utils.LoadStateFile(path, &h.samples)
+	if h.samples == nil {
+		h.samples = make(map[string][]nftFloorSample)
+	}
+	return h
+}
+
+func (h *nftFloorHistory) record(slug string, s nftFloorSample) {
+	h.samples[slug] = append(h.samples[slug], s)
+}
+
+// baseline returns the last sample at or before start. A sample older than maxGap before start
+// is too stale to stand for the window start, e.g. after the service was down for days.
+func (h *nftFloorHistory) baseline(slug string, start time.Time, maxGap time.Duration) (nftFloorSample, bool) {
+	samples := h.samples[slug]
+	for i := len(samples) - 1; i >= 0; i-- {
+		if !samples[i].At.After(start) {
+			return samples[i], start.Sub(samples[i].At) <= maxGap
+		}
+	}
+	return nftFloorSample{}, false
+}
+
+// prune drops samples older than keep, except the newest of them which is still the baseline of the longest window.
+func (h *nftFloorHistory) prune(now time.Time, keep time.Duration) {
+	oldest := now.Add(-keep)
+	for slug, samples := range h.samples {
+		i := 0
+		for i+1 < len(samples) && samples[i+1].At.Before(oldest) {
+			i++
+		}
+		h.samples[slug] = samples[i:]
+	}
+}
+
+func (h *nftFloorHistory) save() error {
+	return utils.SaveStateFile(h.path, h.samples)
+}
@@ -0,0 +1,98 @@
+package tasks
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/config"
+	"github.com/ka1fe1/crypto-monitoring/internal/service"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+)
+
+type fakeOpenSeaService struct {
+	floors []service.NFTFloorPriceInfo
+}
+
+func (f *fakeOpenSeaService) GetNFTFloorPrices(slugs []string, convertToUsd bool) ([]service.NFTFloorPriceInfo, error) {
+	return f.floors, nil
+}
+
+func TestNFTFloorPriceMonitorTask_Alerts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nft.json")
+	h := loadNFTFloorHistory(path)
+	now := time.Now()
+	h.record("punks", nftFloorSample{At: now.Add(-26 * time.Hour), Floor: 50, FloorUSD: 150000})
+	h.record("punks", nftFloorSample{At: now.Add(-25 * time.Hour), Floor: 40, FloorUSD: 120000})
+	h.record("punks", nftFloorSample{At: now.Add(-2 * time.Hour), Floor: 41, FloorUSD: 110000})
+	h.record("apes", nftFloorSample{At: now.Add(-25 * time.Hour), Floor: 10, FloorUSD: 30000})
+	if err := h.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := &fakeOpenSeaService{floors: []service.NFTFloorPriceInfo{
+		// collection-specific dump: the floor in ETH fell
+		{CollectionSlug: "punks", FloorPrice: 30, FloorPriceSymbol: "ETH", FloorPriceUSD: 90000},
+		// ETH fell 20%, the floor in ETH did not move
+		{CollectionSlug: "apes", FloorPrice: 10, FloorPriceSymbol: "ETH", FloorPriceUSD: 24000},
+	}}
+	notifier := &recordingNotifier{keyword: "nft"}
+	alerts := NFTFloorAlertOptions{
+		Alerts: []config.NFTFloorAlertConfig{
+			{Window: "24h", NativePercent: 15, UsdPercent: 15},
+			{Collection: "apes", Window: "7d", NativePercent: 10},
+			{Window: "1w"},
+		},
+		HistoryPath: path,
+	}
+	task := NewNFTFloorPriceMonitorTask(svc, notifier, []string{"punks", "apes"}, alerts, 60, utils.QuietHoursParams{})
+	if len(task.alerts) != 2 {
+		t.Fatalf("expected the invalid window to be skipped, got %d alerts", len(task.alerts))
+	}
+
+	task.Run(context.Background())
+	events := notifier.take()
+	if len(events) != 1 {
+		t.Fatalf("expected one floor alert message without summary, got %+v", events)
+	}
+	text := events[0].Markdown
+	// the baseline is the last sample before the window start, 40 ETH and not 50 ETH
+	if !strings.Contains(text, "**punks** 24h: floor -25.0% (40.00 → 30.00 ETH), USD -25.0%") {
+		t.Errorf("unexpected punks alert: %s", text)
+	}
+	if !strings.Contains(text, "**apes** 24h: floor +0.0%") || !strings.Contains(text, "mostly the ETH price (-20.0%)") {
+		t.Errorf("expected the apes USD move to be attributed to ETH: %s", text)
+	}
+
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 0 {
+		t.Fatalf("expected no repeated alert, got %+v", events)
+	}
+
+	reloaded := loadNFTFloorHistory(path)
+	if n := len(reloaded.samples["punks"]); n != 5 {
+		t.Errorf("expected 5 punks samples after two runs, got %d", n)
+	}
+}
+
+func TestNFTFloorHistory_Baseline(t *testing.T) {
+	h := loadNFTFloorHistory(filepath.Join(t.TempDir(), "nft.json"))
+	now := time.Now()
+	h.record("punks", nftFloorSample{At: now.Add(-50 * time.Hour), Floor: 40})
+
+	if _, ok := h.baseline("punks", now.Add(-time.Hour), time.Hour); ok {
+		t.Errorf("expected a stale sample not to be a baseline")
+	}
+	if b, ok := h.baseline("punks", now.Add(-24*time.Hour), 48*time.Hour); !ok || b.Floor != 40 {
+		t.Errorf("expected the sample as the 24h baseline, got %+v %v", b, ok)
+	}
+
+	h.record("punks", nftFloorSample{At: now.Add(-30 * time.Hour), Floor: 41})
+	h.record("punks", nftFloorSample{At: now, Floor: 42})
+	h.prune(now, 24*time.Hour)
+	if n := len(h.samples["punks"]); n != 2 || h.samples["punks"][0].Floor != 41 {
+		t.Errorf("expected the newest sample before the cutoff to be kept, got %+v", h.samples["punks"])
+	}
+}
@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -9,66 +10,69 @@ import (
 
 	"github.com/ka1fe1/crypto-monitoring/internal/service"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
-	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter/dingding"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
 )
 
 type NFTFloorPriceMonitorTask struct {
 	openSeaService   service.OpenSeaService
-	dingBot          *dingding.DingBot
-	ticker           *time.Ticker
-	stop             chan bool
+	notifier         alter.Notifier
 	collections      []string // Slugs from config
+	alerts           []nftFloorAlert
+	summary          bool
+	history          *nftFloorHistory // only loaded when there are alerts
+	keepHistory      time.Duration
+	tracker          *AlertTracker
 	interval         time.Duration
 	quietHoursParams utils.QuietHoursParams
-	lastRunTime      time.Time
 }
 
-func NewNFTFloorPriceMonitorTask(openSeaService service.OpenSeaService, dingBot *dingding.DingBot, collections []string, intervalSeconds int, quietHoursParams utils.QuietHoursParams) *NFTFloorPriceMonitorTask {
+func NewNFTFloorPriceMonitorTask(openSeaService service.OpenSeaService, notifier alter.Notifier, collections []string, alerts NFTFloorAlertOptions, intervalSeconds int, quietHoursParams utils.QuietHoursParams) *NFTFloorPriceMonitorTask {
 	interval := time.Duration(intervalSeconds) * time.Second
 	if interval <= 0 {
 		interval = 3600 * time.Second // Default to 1 hour
 	}
 
-	return &NFTFloorPriceMonitorTask{
+	t := &NFTFloorPriceMonitorTask{
 		openSeaService:   openSeaService,
-		dingBot:          dingBot,
-		stop:             make(chan bool),
+		notifier:         notifier,
 		collections:      collections,
+		alerts:           compileNFTFloorAlerts(alerts.Alerts),
+		summary:          alerts.Summary,
+		tracker:          alerts.Tracker,
 		interval:         interval,
 		quietHoursParams: quietHoursParams,
 	}
+	if t.tracker == nil {
+		t.tracker = NewAlertTracker()
+	}
+	for _, a := range t.alerts {
+		// a baseline may be up to one window older than the window start
+		t.keepHistory = max(t.keepHistory, 2*a.window)
+	}
+	if len(t.alerts) > 0 && alerts.HistoryPath != "" {
+		t.history = loadNFTFloorHistory(alerts.HistoryPath)
+	}
+	return t
+}
 
+func (t *NFTFloorPriceMonitorTask) Name() string {
+	return "NFTFloorPriceMonitorTask"
 }
 
-func (t *NFTFloorPriceMonitorTask) Start() {
-	t.ticker = time.NewTicker(t.interval)
-	go func() {
-		for {
-			select {
-			case <-t.ticker.C:
-				t.run()
-			case <-t.stop:
-				t.ticker.Stop()
-				return
-			}
-		}
-	}()
+func (t *NFTFloorPriceMonitorTask) Interval() time.Duration {
+	return t.interval
 }
 
-func (t *NFTFloorPriceMonitorTask) Stop() {
-	t.stop <- true
+func (t *NFTFloorPriceMonitorTask) QuietHours() utils.QuietHoursParams {
+	return t.quietHoursParams
 }
 
-func (t *NFTFloorPriceMonitorTask) run() {
+func (t *NFTFloorPriceMonitorTask) Run(ctx context.Context) {
 	if len(t.collections) == 0 {
 		return
 	}
 
-	if !utils.ShouldExecTask(t.quietHoursParams, t.lastRunTime, t.interval) {
-		return
-	}
-	t.lastRunTime = time.Now()
-
 	// Fetch prices with USD conversion enabled
 	prices, err := t.openSeaService.GetNFTFloorPrices(t.collections, true)
 	if err != nil {
@@ -76,6 +80,26 @@ func (t *NFTFloorPriceMonitorTask) run() {
 		return
 	}
 
+	if t.history != nil {
+		now := time.Now()
+		var notices []alertNotice
+		for _, info := range prices {
+			notices = append(notices, t.evaluateFloorAlerts(info, now)...)
+			t.history.record(info.CollectionSlug, nftFloorSample{At: now, Floor: info.FloorPrice, FloorUSD: info.FloorPriceUSD})
+		}
+		t.history.prune(now, t.keepHistory)
+		if err := t.history.save(); err != nil {
+			logger.Error("Failed to save NFT floor history: %v", err)
+		}
+		if len(notices) > 0 {
+			sendAlertNotices(t.notifier, "NFTFloorPriceMonitorTask", fmt.Sprintf("%s Floor Alert", t.notifier.GetKeyword()), notices, now)
+		}
+	}
+
+	if !t.summary {
+		return
+	}
+
 	var allTexts []string
 
 	for _, info := range prices {
@@ -98,14 +122,14 @@ func (t *NFTFloorPriceMonitorTask) run() {
 	}
 
 	// Aggregate messages
-	unifiedTitle := fmt.Sprintf("%s floor price", t.dingBot.Keyword)
+	unifiedTitle := fmt.Sprintf("%s floor price", t.notifier.GetKeyword())
 
 	unifiedText := fmt.Sprintf("#### %s\n\n%s", unifiedTitle, strings.Join(allTexts, "\n---\n"))
 	unifiedText += fmt.Sprintf("\n\n---\n**Last Updated**: %s", utils.FormatBJTime(time.Now()))
 
-	err = t.dingBot.SendMarkdown(unifiedTitle, unifiedText, nil, false)
+	err = alter.Notify(t.notifier, alter.NewEvent("NFTFloorPriceMonitorTask", constant.SEVERITY_INFO, unifiedTitle, unifiedText))
 	if err != nil {
-		logger.Error("Error sending dingtalk message for NFT alerts: %v", err)
+		logger.Error("Error sending notification for NFT alerts: %v", err)
 	} else {
 		logger.Info("Sent batch NFT floor price alerts for %d collections", len(allTexts))
 	}
@@ -0,0 +1,41 @@
+package tasks
+
+import (
+	"sync"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
+)
+
+// recordingNotifier keeps the events sent by a task instead of delivering them.
+type recordingNotifier struct {
+	keyword string
+	mu      sync.Mutex
+	events  []alter.Event
+}
+
+func (r *recordingNotifier) GetKeyword() string {
+	return r.keyword
+}
+
+func (r *recordingNotifier) SendText(content string, mentions []string, mentionAll bool) error {
+	return r.SendEvent(alter.Event{Markdown: content})
+}
+
+func (r *recordingNotifier) SendMarkdown(title, text string, mentions []string, mentionAll bool) error {
+	return r.SendEvent(alter.Event{Title: title, Markdown: text})
+}
+
+func (r *recordingNotifier) SendEvent(e alter.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+	return nil
+}
+
+func (r *recordingNotifier) take() []alter.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := r.events
+	r.events = nil
+	return events
+}
@@ -1,13 +1,18 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/ka1fe1/crypto-monitoring/config"
+	"github.com/ka1fe1/crypto-monitoring/internal/service"
 	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/markdown"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/polymarket"
 )
@@ -15,12 +20,12 @@ import (
 type PolymarketDailyReportTask struct {
 	cfg              *config.PolymarketReportConfig
 	client           *polymarket.Client
+	notifier         alter.Notifier // optional, receives the report table
 	interval         time.Duration
 	quietHoursParams utils.QuietHoursParams
-	lastRunTime      time.Time
 }
 
-func NewPolymarketDailyReportTask(cfg *config.Config, pmClient *polymarket.Client, intervalSeconds int, quietHoursParams utils.QuietHoursParams) *PolymarketDailyReportTask {
+func NewPolymarketDailyReportTask(cfg *config.Config, pmClient *polymarket.Client, notifier alter.Notifier, intervalSeconds int, quietHoursParams utils.QuietHoursParams) *PolymarketDailyReportTask {
 	interval := time.Duration(intervalSeconds) * time.Second
 	if interval <= 0 {
 		interval = 24 * 3600 * time.Second // Default to 24 hours
@@ -29,6 +34,7 @@ func NewPolymarketDailyReportTask(cfg *config.Config, pmClient *polymarket.Clien
 	return &PolymarketDailyReportTask{
 		cfg:              &cfg.PolymarketReport,
 		client:           pmClient,
+		notifier:         notifier,
 		interval:         interval,
 		quietHoursParams: quietHoursParams,
 	}
@@ -38,12 +44,15 @@ func (t *PolymarketDailyReportTask) Name() string {
 	return "PolymarketDailyReportTask"
 }
 
-func (t *PolymarketDailyReportTask) Run() {
-	if !utils.ShouldExecTask(t.quietHoursParams, t.lastRunTime, t.interval) {
-		return
-	}
-	t.lastRunTime = time.Now()
+func (t *PolymarketDailyReportTask) Interval() time.Duration {
+	return t.interval
+}
+
+func (t *PolymarketDailyReportTask) QuietHours() utils.QuietHoursParams {
+	return t.quietHoursParams
+}
 
+func (t *PolymarketDailyReportTask) Run(ctx context.Context) {
 	logger.Info("Starting PolymarketDailyReportTask")
 
 	// 1. Read Addresses
@@ -64,6 +73,7 @@ func (t *PolymarketDailyReportTask) Run() {
 	}
 
 	var reportData []markdown.TraderReportData
+	var traders []service.PolymarketTraderSnapshot
 
 	// 2. Iterate and Fetch Data
 	for _, entry := range entries {
@@ -92,12 +102,15 @@ func (t *PolymarketDailyReportTask) Run() {
 
 		// Fetch Current Positions (using proxyWallet)
 		cp, err := t.client.GetCurrentPositionsForUser(proxyWallet)
-		var positionsStr string
+		var positionsStr, positionsErr string
+		var positions []polymarket.Position
 		if err != nil {
 			logger.Error("Failed to get current positions for address %s: %v", addr, err)
 			positionsStr = "Error fetching data"
+			positionsErr = err.Error()
 		} else {
 			if cp != nil && len(*cp) > 0 {
+				positions = *cp
 				var lines []string
 				for _, p := range *cp {
 					line := fmt.Sprintf("- %s \\| %s \\| init: %.4f(%.2f) \\| current: %.4f(%.2f) \\| cash pnl: %.2f \\| to win: %.2f \\| redeemable: %v",
@@ -113,11 +126,13 @@ func (t *PolymarketDailyReportTask) Run() {
 		// Fetch User Activity
 		activity, err := t.client.GetUserActivity(proxyWallet)
 		lastActiveTime := "N/A"
+		var lastActive *time.Time
 		if err != nil {
 			logger.Error("Failed to get user activity for address %s: %v", addr, err)
 		} else if len(activity) > 0 {
-			ts := activity[0].Timestamp
-			lastActiveTime = utils.FormatRelativeTime(time.Unix(ts, 0))
+			ts := time.Unix(activity[0].Timestamp, 0)
+			lastActiveTime = utils.FormatRelativeTime(ts)
+			lastActive = &ts
 		}
 
 		totalVal := 0.0
@@ -145,16 +160,74 @@ func (t *PolymarketDailyReportTask) Run() {
 			LastActiveTime:   lastActiveTime,
 			CurrentPositions: positionsStr,
 		})
+		rankNum, _ := strconv.Atoi(rank)
+		traders = append(traders, service.PolymarketTraderSnapshot{
+			Name:           entry.Name,
+			Address:        addr,
+			ProxyAddr:      proxyWallet,
+			Volume:         vol,
+			Rank:           rankNum,
+			Pnl:            pnl,
+			PositionValue:  totalVal,
+			LastActive:     lastActive,
+			Positions:      positions,
+			PositionsError: positionsErr,
+		})
 
 		// Optional: avoid rate limits
-		time.Sleep(500 * time.Millisecond)
+		select {
+		case <-time.After(500 * time.Millisecond):
+		case <-ctx.Done():
+			logger.Warn("PolymarketDailyReportTask canceled after %d of %d addresses", len(reportData), len(entries))
+			return
+		}
 	}
 
-	// 3. Write Output
-	if err := markdown.WriteReportTable(t.cfg.OutputDir, reportData); err != nil {
+	// 3. Write Output, the markdown table and the snapshot share the timestamp of the run
+	now := time.Now()
+	if err := markdown.WriteReportTableAt(t.cfg.OutputDir, reportData, now); err != nil {
 		logger.Error("Failed to write daily report: %v", err)
 		return
 	}
+	diff := t.saveSnapshot(&service.PolymarketReportSnapshot{GeneratedAt: now, Traders: traders})
+
+	// 4. Notify
+	if t.notifier != nil {
+		title := fmt.Sprintf("%s Polymarket Daily Report", t.notifier.GetKeyword())
+		content := fmt.Sprintf("### %s\n\n%s", title, markdown.FormatReportTable(reportData))
+		if err := alter.Notify(t.notifier, alter.NewEvent(t.Name(), constant.SEVERITY_INFO, title, content)); err != nil {
+			logger.Error("Error sending notification: %v", err)
+		}
+		if diff != nil {
+			title := fmt.Sprintf("%s Polymarket Report Diff", t.notifier.GetKeyword())
+			content := fmt.Sprintf("### %s\n\n%s", title, service.FormatPolymarketReportDiff(diff))
+			if err := alter.Notify(t.notifier, alter.NewEvent(t.Name(), constant.SEVERITY_INFO, title, content)); err != nil {
+				logger.Error("Error sending notification: %v", err)
+			}
+		}
+	}
 
 	logger.Info("PolymarketDailyReportTask completed successfully. Processed %d addresses.", len(reportData))
 }
+
+// saveSnapshot stores the structured form of the run and writes the diff against the previous
+// one. It returns the diff, nil on the first run or when the previous snapshot is unreadable.
+func (t *PolymarketDailyReportTask) saveSnapshot(snapshot *service.PolymarketReportSnapshot) *service.PolymarketReportDiff {
+	store := service.NewPolymarketReportStore(t.cfg.OutputDir)
+	prev, err := store.Latest()
+	if err != nil {
+		logger.Error("Failed to load previous report snapshot: %v", err)
+	}
+	if err := store.Save(snapshot); err != nil {
+		logger.Error("Failed to write report snapshot: %v", err)
+	}
+	if prev == nil {
+		return nil
+	}
+
+	diff := service.DiffPolymarketReports(prev, snapshot)
+	if err := store.SaveDiff(diff); err != nil {
+		logger.Error("Failed to write report diff: %v", err)
+	}
+	return diff
+}
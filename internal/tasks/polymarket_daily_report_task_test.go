@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -28,8 +29,8 @@ func TestPolymarketDailyReportTask_Run(t *testing.T) {
 	}
 
 	var qh utils.QuietHoursParams
-	task := NewPolymarketDailyReportTask(testCfg, polyClient, 86400, qh)
-	task.Run()
+	task := NewPolymarketDailyReportTask(testCfg, polyClient, nil, 86400, qh)
+	task.Run(context.Background())
 
 	// Verify a report file was generated
 	outputDir := testCfg.PolymarketReport.OutputDir
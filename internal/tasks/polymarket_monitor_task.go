@@ -1,77 +1,80 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/ka1fe1/crypto-monitoring/config"
 	"github.com/ka1fe1/crypto-monitoring/internal/service"
 	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
-	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter/dingding"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/polymarket"
 )
 
+const polymarketMarketURL = "https://polymarket.com/market/"
+
 type PolymarketMonitorTask struct {
 	service          service.PolymarketMonitorService
-	dingBot          *dingding.DingBot
-	ticker           *time.Ticker
-	stop             chan bool
+	notifier         alter.Notifier
 	marketIDs        []string
+	rules            []config.PolymarketRuleConfig
+	summary          bool
+	notifyClosed     bool
+	tracker          *AlertTracker
+	state            *polymarketState
+	statePath        string
+	stateDirty       bool
 	interval         time.Duration
 	quietHoursParams utils.QuietHoursParams
-	lastRunTime      time.Time
 }
 
-func NewPolymarketMonitorTask(service service.PolymarketMonitorService, dingBot *dingding.DingBot, marketIDs []string, intervalSeconds int, quietHoursParams utils.QuietHoursParams) *PolymarketMonitorTask {
+func NewPolymarketMonitorTask(service service.PolymarketMonitorService, notifier alter.Notifier, marketIDs []string, alerts PolymarketAlertOptions, intervalSeconds int, quietHoursParams utils.QuietHoursParams) *PolymarketMonitorTask {
 	interval := time.Duration(intervalSeconds) * time.Second
 	if interval <= 0 {
 		interval = 3600 * time.Second
 	}
 
+	if alerts.Tracker == nil {
+		alerts.Tracker = NewAlertTracker()
+	}
+
 	return &PolymarketMonitorTask{
 		service:          service,
-		dingBot:          dingBot,
-		stop:             make(chan bool),
+		notifier:         notifier,
 		marketIDs:        marketIDs,
+		rules:            alerts.Rules,
+		summary:          alerts.Summary,
+		notifyClosed:     alerts.NotifyClosed,
+		tracker:          alerts.Tracker,
+		state:            loadPolymarketState(alerts.StatePath),
+		statePath:        alerts.StatePath,
 		interval:         interval,
 		quietHoursParams: quietHoursParams,
 	}
+}
 
+func (t *PolymarketMonitorTask) Name() string {
+	return "PolymarketMonitorTask"
 }
 
-func (t *PolymarketMonitorTask) Start() {
-	t.ticker = time.NewTicker(t.interval)
-	logger.Info("Starting Polymarket Monitor Task with interval %v, monitoring %d markets", t.interval, len(t.marketIDs))
-	go func() {
-		for {
-			select {
-			case <-t.ticker.C:
-				t.run()
-			case <-t.stop:
-				t.ticker.Stop()
-				return
-			}
-		}
-	}()
+func (t *PolymarketMonitorTask) Interval() time.Duration {
+	return t.interval
 }
 
-func (t *PolymarketMonitorTask) Stop() {
-	t.stop <- true
+func (t *PolymarketMonitorTask) QuietHours() utils.QuietHoursParams {
+	return t.quietHoursParams
 }
 
-func (t *PolymarketMonitorTask) run() {
+func (t *PolymarketMonitorTask) Run(ctx context.Context) {
 	if len(t.marketIDs) == 0 {
 		return
 	}
 
-	if !utils.ShouldExecTask(t.quietHoursParams, t.lastRunTime, t.interval) {
-		logger.Info("Skipping Polymarket Monitor Task for %s in quiet hours", t.dingBot.Keyword)
-		return
-	}
-	t.lastRunTime = time.Now()
-
 	markets, err := t.service.GetMarketDetails(t.marketIDs)
 	if err != nil {
 		logger.Error("Error fetching Polymarket details: %v", err)
@@ -81,16 +84,17 @@ func (t *PolymarketMonitorTask) run() {
 		return
 	}
 
-	if len(markets) == 0 {
+	t.checkAlerts(markets)
+	if !t.summary {
 		return
 	}
 
-	content := t.formatMarkets(markets)
+	content, buttons := t.formatMarkets(markets)
 	if content == "" {
 		return
 	}
 
-	title := fmt.Sprintf("%s Polymarket Monitor", t.dingBot.Keyword)
+	title := fmt.Sprintf("%s Polymarket Monitor", t.notifier.GetKeyword())
 	// We need to construct the full markdown like before
 	fullContent := fmt.Sprintf("## %s\n\n --- \n\n%s\n\n---\n**Last Updated**: %s",
 		title,
@@ -98,16 +102,42 @@ func (t *PolymarketMonitorTask) run() {
 		utils.FormatBJTime(time.Now()),
 	)
 
-	err = t.dingBot.SendMarkdown(title, fullContent, nil, false)
+	event := alter.NewEvent("PolymarketMonitorTask", constant.SEVERITY_INFO, title, fullContent).WithButtons(buttons...)
+	err = alter.Notify(t.notifier, event)
 	if err != nil {
-		logger.Error("Error sending DingTalk message for Polymarket monitor: %v", err)
+		logger.Error("Error sending notification for Polymarket monitor: %v", err)
 	} else {
 		logger.Info("Sent Polymarket monitor update for %d markets", len(markets))
 	}
 }
 
-func (t *PolymarketMonitorTask) formatMarkets(markets []polymarket.MarketDetail) string {
+// checkAlerts sends the rule alerts of the open markets and the close notices.
+func (t *PolymarketMonitorTask) checkAlerts(markets []polymarket.MarketDetail) {
+	now := time.Now()
+	var notices, closed []alertNotice
+	for _, m := range markets {
+		if m.Closed {
+			if n, ok := t.closedNotice(m); ok && t.notifyClosed {
+				closed = append(closed, n)
+			}
+			continue
+		}
+		notices = append(notices, t.evaluateMarketRules(m, now)...)
+	}
+	t.saveState()
+
+	if len(notices) > 0 {
+		sendAlertNotices(t.notifier, "PolymarketMonitorTask", fmt.Sprintf("%s Polymarket Alert", t.notifier.GetKeyword()), notices, now)
+	}
+	if len(closed) > 0 {
+		sendAlertNotices(t.notifier, "PolymarketMonitorTask", fmt.Sprintf("%s Polymarket Closed", t.notifier.GetKeyword()), closed, now)
+	}
+}
+
+// formatMarkets renders the open markets as markdown and returns an "Open market" button per market.
+func (t *PolymarketMonitorTask) formatMarkets(markets []polymarket.MarketDetail) (string, []alter.Button) {
 	var texts []string
+	var buttons []alter.Button
 	for _, market := range markets {
 		if market.Closed {
 			continue
@@ -136,8 +166,23 @@ func (t *PolymarketMonitorTask) formatMarkets(markets []polymarket.MarketDetail)
 			utils.FormatPrice(market.OneHourPriceChange*100),
 		)
 		texts = append(texts, text)
+
+		if market.Slug != "" {
+			buttons = append(buttons, alter.Button{Title: "Open market: " + truncateRunes(market.Question, 30), URL: polymarketMarketURL + market.Slug})
+		}
+	}
+	if len(buttons) == 1 {
+		buttons[0].Title = "Open market"
+	}
+	return strings.Join(texts, "\n\n---\n\n"), buttons
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
 	}
-	return strings.Join(texts, "\n\n---\n\n")
+	return string(r[:n]) + "…"
 }
 
 func (t *PolymarketMonitorTask) getClosedStr(closed bool) string {
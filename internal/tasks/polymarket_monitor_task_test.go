@@ -1,6 +1,8 @@
 package tasks
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/ka1fe1/crypto-monitoring/internal/service"
@@ -35,8 +37,24 @@ func TestPolymarketMonitorTask_Run(t *testing.T) {
 
 	qh := utils.QuietHoursParams{Enabled: true, StartHour: 0, EndHour: 7, Behavior: constant.QUIET_HOURS_BEHAVIOR_PAUSE}
 	polyService := service.NewPolymarketMonitorService(client)
-	task := NewPolymarketMonitorTask(polyService, bot, marketIDs, cfg.PolymarketMonitor.IntervalSeconds, qh)
+	task := NewPolymarketMonitorTask(polyService, bot, marketIDs, PolymarketAlertOptions{Summary: true}, cfg.PolymarketMonitor.IntervalSeconds, qh)
 
 	// Manually trigger run to test logic and notification
-	task.run()
+	task.Run(context.Background())
+}
+
+func TestPolymarketMonitorTask_FormatMarkets(t *testing.T) {
+	task := &PolymarketMonitorTask{}
+	markets := []polymarket.MarketDetail{
+		{Question: "Will BTC hit $100k?", Slug: "will-btc-hit-100k", OutcomePrices: map[string]float64{"Yes": 0.4, "No": 0.6}},
+		{Question: "Closed market", Slug: "closed", Closed: true},
+	}
+
+	content, buttons := task.formatMarkets(markets)
+	if strings.Contains(content, "](") {
+		t.Errorf("expected no inline links, got %s", content)
+	}
+	if len(buttons) != 1 || buttons[0].Title != "Open market" || buttons[0].URL != "https://polymarket.com/market/will-btc-hit-100k" {
+		t.Errorf("unexpected buttons: %+v", buttons)
+	}
 }
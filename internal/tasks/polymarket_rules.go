@@ -0,0 +1,201 @@
+package tasks
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/config"
+	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/polymarket"
+)
+
+// PolymarketAlertOptions configures the probability swing alerts of a PolymarketMonitorTask.
+type PolymarketAlertOptions struct {
+	Rules        []config.PolymarketRuleConfig
+	Summary      bool          // send every market on each run
+	NotifyClosed bool          // send the final outcome when a market closes
+	StatePath    string        // move references and announced closes, kept across restarts
+	Tracker      *AlertTracker // shared by the tasks of one bot, nil for a private tracker
+}
+
+// polymarketState is persisted so a restart neither resets move references nor repeats close notices.
+type polymarketState struct {
+	References map[string]float64 `json:"references"` // "id/outcome/move/points" -> probability at the last move alert
+	Closed     map[string]bool    `json:"closed"`     // markets whose close was announced
+	Resolved   map[string]bool    `json:"resolved"`   // markets whose final outcome was announced
+}
+
+func loadPolymarketState(path string) *polymarketState {
+	s := &polymarketState{}
+	if path != "" {
+		utils.LoadStateFile(path, s)
+	}
+	if s.References == nil {
+		s.References = make(map[string]float64)
+	}
+	if s.Closed == nil {
+		s.Closed = make(map[string]bool)
+	}
+	if s.Resolved == nil {
+		s.Resolved = make(map[string]bool)
+	}
+	return s
+}
+
+// evaluateMarketRules returns the rule conditions an open market tripped since the previous run.
+func (t *PolymarketMonitorTask) evaluateMarketRules(m polymarket.MarketDetail, now time.Time) []alertNotice {
+	var notices []alertNotice
+	for _, r := range t.rules {
+		if r.MarketID != "" && r.MarketID != m.ID {
+			continue
+		}
+		outcome, price, ok := outcomePrice(m, r.Outcome)
+		if !ok {
+			continue
+		}
+		severity := r.Severity
+		if severity == "" {
+			severity = constant.SEVERITY_WARNING
+		}
+		cooldown := time.Duration(r.CooldownMinutes) * time.Minute
+		prefix := fmt.Sprintf("- %s **%s** ***%.1f%%***", marketLink(m), outcome, price*100)
+		key := fmt.Sprintf("polymarket/%s/%s", m.ID, strings.ToLower(outcome))
+
+		check := func(kind string, triggered, cleared bool, format string, args ...interface{}) {
+			if t.tracker.Observe(key+"/"+kind, triggered, cleared, cooldown, now) == ALERT_FIRED {
+				notices = append(notices, alertNotice{severity: severity, line: prefix + " " + fmt.Sprintf(format, args...)})
+			}
+		}
+
+		if r.Above > 0 {
+			triggered, cleared := aboveLevel(price, r.Above, 0)
+			check(fmt.Sprintf("above/%g", r.Above), triggered, cleared, "above %.0f%%", r.Above*100)
+		}
+		if r.Below > 0 {
+			triggered, cleared := belowLevel(price, r.Below, 0)
+			check(fmt.Sprintf("below/%g", r.Below), triggered, cleared, "below %.0f%%", r.Below*100)
+		}
+		if change1h, change24h, ok := outcomeChanges(m, outcome); ok {
+			if r.Change1h > 0 {
+				hit := math.Abs(change1h*100) >= r.Change1h
+				check(fmt.Sprintf("change_1h/%g", r.Change1h), hit, !hit, "moved %+.1f points in 1h", change1h*100)
+			}
+			if r.Change24h > 0 {
+				hit := math.Abs(change24h*100) >= r.Change24h
+				check(fmt.Sprintf("change_24h/%g", r.Change24h), hit, !hit, "moved %+.1f points in 24h", change24h*100)
+			}
+		}
+		if r.MovePoints > 0 {
+			refKey := fmt.Sprintf("%s/%s/move/%g", m.ID, strings.ToLower(outcome), r.MovePoints)
+			ref, seen := t.state.References[refKey]
+			switch {
+			case !seen:
+				t.state.References[refKey] = price
+				t.stateDirty = true
+			case math.Abs(price-ref)*100 >= r.MovePoints:
+				notices = append(notices, alertNotice{severity: severity, line: prefix + fmt.Sprintf(" moved %+.1f points since %.1f%%", (price-ref)*100, ref*100)})
+				t.state.References[refKey] = price
+				t.stateDirty = true
+			}
+		}
+	}
+	return notices
+}
+
+// closedNotice announces a market's close once, with the final or leading outcome. A market
+// that closes before it resolves is announced again once an outcome reaches 99%.
+func (t *PolymarketMonitorTask) closedNotice(m polymarket.MarketDetail) (alertNotice, bool) {
+	if !m.Closed || m.ID == "" || t.state.Resolved[m.ID] {
+		return alertNotice{}, false
+	}
+
+	winner, best := "", -1.0
+	names := make([]string, 0, len(m.OutcomePrices))
+	for name := range m.OutcomePrices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if p := m.OutcomePrices[name]; p > best {
+			winner, best = name, p
+		}
+	}
+	resolved := winner != "" && best >= 0.99
+	if t.state.Closed[m.ID] && !resolved {
+		return alertNotice{}, false
+	}
+
+	line := fmt.Sprintf("- %s closed", marketLink(m))
+	switch {
+	case resolved && t.state.Closed[m.ID]:
+		line = fmt.Sprintf("- %s resolved **%s**", marketLink(m), winner)
+	case resolved:
+		line += fmt.Sprintf(", resolved **%s**", winner)
+	case winner != "":
+		line += fmt.Sprintf(", not resolved yet, **%s** leads at %.1f%%", winner, best*100)
+	}
+	if m.ClosedTime != "" {
+		line += fmt.Sprintf(" (%s)", m.ClosedTime)
+	}
+
+	t.state.Closed[m.ID] = true
+	if resolved {
+		t.state.Resolved[m.ID] = true
+	}
+	t.stateDirty = true
+	return alertNotice{severity: constant.SEVERITY_WARNING, line: line}, true
+}
+
+func (t *PolymarketMonitorTask) saveState() {
+	if !t.stateDirty || t.statePath == "" {
+		return
+	}
+	if err := utils.SaveStateFile(t.statePath, t.state); err != nil {
+		logger.Error("Failed to save Polymarket monitor state: %v", err)
+		return
+	}
+	t.stateDirty = false
+}
+
+// outcomePrice finds an outcome case-insensitively, an empty name selects the first outcome.
+func outcomePrice(m polymarket.MarketDetail, name string) (string, float64, bool) {
+	if name == "" {
+		if len(m.Outcomes) == 0 {
+			return "", 0, false
+		}
+		name = m.Outcomes[0]
+	}
+	for outcome, price := range m.OutcomePrices {
+		if strings.EqualFold(outcome, name) {
+			return outcome, price, true
+		}
+	}
+	return "", 0, false
+}
+
+// outcomeChanges returns the 1h and 24h changes of an outcome. Polymarket reports them for the
+// first outcome, in a two-outcome market the other one moves by the opposite amount.
+func outcomeChanges(m polymarket.MarketDetail, outcome string) (change1h, change24h float64, ok bool) {
+	if len(m.Outcomes) == 0 {
+		return 0, 0, false
+	}
+	if strings.EqualFold(m.Outcomes[0], outcome) {
+		return m.OneHourPriceChange, m.OneDayPriceChange, true
+	}
+	if len(m.Outcomes) == 2 {
+		return -m.OneHourPriceChange, -m.OneDayPriceChange, true
+	}
+	return 0, 0, false
+}
+
+func marketLink(m polymarket.MarketDetail) string {
+	if m.Slug == "" {
+		return "**" + m.Question + "**"
+	}
+	return fmt.Sprintf("[%s](%s%s)", m.Question, polymarketMarketURL, m.Slug)
+}
@@ -0,0 +1,120 @@
+package tasks
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ka1fe1/crypto-monitoring/config"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/polymarket"
+)
+
+type fakePolymarketService struct {
+	markets []polymarket.MarketDetail
+}
+
+func (f *fakePolymarketService) GetMarketDetails(ids []string) ([]polymarket.MarketDetail, error) {
+	return f.markets, nil
+}
+
+func binaryMarket(yes, change1h float64) polymarket.MarketDetail {
+	return polymarket.MarketDetail{
+		ID:                 "12345",
+		Question:           "Will it rain?",
+		Slug:               "will-it-rain",
+		Outcomes:           []string{"Yes", "No"},
+		OutcomePrices:      map[string]float64{"Yes": yes, "No": 1 - yes},
+		OneHourPriceChange: change1h,
+	}
+}
+
+func TestPolymarketMonitorTask_Rules(t *testing.T) {
+	svc := &fakePolymarketService{markets: []polymarket.MarketDetail{binaryMarket(0.5, 0)}}
+	notifier := &recordingNotifier{keyword: "prediction"}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	alerts := PolymarketAlertOptions{
+		Rules: []config.PolymarketRuleConfig{
+			{Above: 0.8},
+			{MarketID: "12345", Outcome: "no", Change1h: 10},
+			{MovePoints: 15},
+		},
+		NotifyClosed: true,
+		StatePath:    statePath,
+	}
+	task := NewPolymarketMonitorTask(svc, notifier, []string{"12345"}, alerts, 60, utils.QuietHoursParams{})
+
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 0 {
+		t.Fatalf("expected no message without a swing, got %+v", events)
+	}
+
+	// +12 points in the last hour: the No side moved -12 points, not yet 15 points since the reference
+	svc.markets[0] = binaryMarket(0.62, 0.12)
+	task.Run(context.Background())
+	events := notifier.take()
+	if len(events) != 1 || !strings.Contains(events[0].Markdown, "**No** ***38.0%*** moved -12.0 points in 1h") {
+		t.Fatalf("expected the 1h swing alert, got %+v", events)
+	}
+	if !strings.Contains(events[0].Markdown, "[Will it rain?](https://polymarket.com/market/will-it-rain)") {
+		t.Errorf("expected a market link: %s", events[0].Markdown)
+	}
+
+	// a restart keeps the move reference of 50%
+	task = NewPolymarketMonitorTask(svc, notifier, []string{"12345"}, alerts, 60, utils.QuietHoursParams{})
+	svc.markets[0] = binaryMarket(0.85, 0.02)
+	task.Run(context.Background())
+	events = notifier.take()
+	if len(events) != 1 || !strings.Contains(events[0].Markdown, "above 80%") || !strings.Contains(events[0].Markdown, "moved +35.0 points since 50.0%") {
+		t.Fatalf("expected the level and move alerts, got %+v", events)
+	}
+
+	closed := binaryMarket(1, 0)
+	closed.Closed = true
+	svc.markets[0] = closed
+	task.Run(context.Background())
+	events = notifier.take()
+	if len(events) != 1 || !strings.Contains(events[0].Markdown, "closed, resolved **Yes**") {
+		t.Fatalf("expected a close notice with the outcome, got %+v", events)
+	}
+
+	task = NewPolymarketMonitorTask(svc, notifier, []string{"12345"}, alerts, 60, utils.QuietHoursParams{})
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 0 {
+		t.Fatalf("expected the close to be announced once, got %+v", events)
+	}
+}
+
+func TestPolymarketMonitorTask_ClosedThenResolved(t *testing.T) {
+	pending := binaryMarket(0.7, 0)
+	pending.Closed = true
+	svc := &fakePolymarketService{markets: []polymarket.MarketDetail{pending}}
+	notifier := &recordingNotifier{keyword: "prediction"}
+	alerts := PolymarketAlertOptions{NotifyClosed: true, StatePath: filepath.Join(t.TempDir(), "state.json")}
+	task := NewPolymarketMonitorTask(svc, notifier, []string{"12345"}, alerts, 60, utils.QuietHoursParams{})
+
+	task.Run(context.Background())
+	events := notifier.take()
+	if len(events) != 1 || !strings.Contains(events[0].Markdown, "closed, not resolved yet, **Yes** leads at 70.0%") {
+		t.Fatalf("expected a close notice without an outcome, got %+v", events)
+	}
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 0 {
+		t.Fatalf("expected the close to be announced once, got %+v", events)
+	}
+
+	resolved := binaryMarket(0, 0)
+	resolved.Closed = true
+	svc.markets[0] = resolved
+	task = NewPolymarketMonitorTask(svc, notifier, []string{"12345"}, alerts, 60, utils.QuietHoursParams{})
+	task.Run(context.Background())
+	events = notifier.take()
+	if len(events) != 1 || !strings.Contains(events[0].Markdown, "[Will it rain?](https://polymarket.com/market/will-it-rain) resolved **No**") {
+		t.Fatalf("expected a resolution notice, got %+v", events)
+	}
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 0 {
+		t.Fatalf("expected the resolution to be announced once, got %+v", events)
+	}
+}
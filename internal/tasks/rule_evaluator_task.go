@@ -0,0 +1,157 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/config"
+	"github.com/ka1fe1/crypto-monitoring/internal/service"
+	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/expr"
+)
+
+// RuleEvaluatorTask evaluates user expressions over the metric namespace of service.MetricsService
+// and alerts when one becomes true, with a resolved notice when it turns false again.
+type RuleEvaluatorTask struct {
+	metrics          service.MetricsService
+	notifier         alter.Notifier
+	rules            []expressionRule
+	metricNames      []string // union of the metrics the rules reference
+	alerts           *AlertTracker
+	notifyResolved   bool
+	interval         time.Duration
+	quietHoursParams utils.QuietHoursParams
+}
+
+type expressionRule struct {
+	name     string
+	expr     *expr.Expr
+	message  string
+	severity string
+	cooldown time.Duration
+}
+
+// NewRuleEvaluatorTask compiles the rules, rules that do not parse are logged and skipped.
+// tracker may be shared with the other tasks of the bot, nil creates a private one.
+func NewRuleEvaluatorTask(metrics service.MetricsService, notifier alter.Notifier, rules []config.ExpressionRuleConfig, notifyResolved bool, tracker *AlertTracker, intervalSeconds int, quietHoursParams utils.QuietHoursParams) *RuleEvaluatorTask {
+	interval := time.Duration(intervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = 300 * time.Second
+	}
+	if tracker == nil {
+		tracker = NewAlertTracker()
+	}
+
+	t := &RuleEvaluatorTask{
+		metrics:          metrics,
+		notifier:         notifier,
+		alerts:           tracker,
+		notifyResolved:   notifyResolved,
+		interval:         interval,
+		quietHoursParams: quietHoursParams,
+	}
+
+	seen := make(map[string]bool)
+	for _, r := range rules {
+		name := r.Name
+		if name == "" {
+			name = r.Expr
+		}
+		e, err := expr.Parse(r.Expr)
+		if err != nil {
+			logger.Error("RuleEvaluatorTask: invalid rule %q, skipping: %v", name, err)
+			continue
+		}
+		severity := r.Severity
+		if severity == "" {
+			severity = constant.SEVERITY_WARNING
+		}
+		t.rules = append(t.rules, expressionRule{
+			name:     name,
+			expr:     e,
+			message:  r.Message,
+			severity: severity,
+			cooldown: time.Duration(r.CooldownMinutes) * time.Minute,
+		})
+		for _, m := range e.Metrics() {
+			if !seen[m] {
+				seen[m] = true
+				t.metricNames = append(t.metricNames, m)
+			}
+		}
+	}
+	return t
+}
+
+func (t *RuleEvaluatorTask) Name() string {
+	return "RuleEvaluatorTask"
+}
+
+func (t *RuleEvaluatorTask) Interval() time.Duration {
+	return t.interval
+}
+
+func (t *RuleEvaluatorTask) QuietHours() utils.QuietHoursParams {
+	return t.quietHoursParams
+}
+
+func (t *RuleEvaluatorTask) Run(ctx context.Context) {
+	if len(t.rules) == 0 {
+		return
+	}
+
+	values, err := t.metrics.Collect(t.metricNames)
+	if err != nil {
+		// partial results are still useful, rules missing a metric are skipped below
+		logger.Warn("RuleEvaluatorTask: some metrics are unavailable: %v", err)
+	}
+
+	now := time.Now()
+	var fired, resolved []alertNotice
+	for _, r := range t.rules {
+		ok, err := r.expr.Eval(values)
+		if err != nil {
+			// keep the rule's state, a failed fetch says nothing about the condition
+			logger.Warn("RuleEvaluatorTask: rule %q not evaluated: %v", r.name, err)
+			continue
+		}
+		switch t.alerts.Observe("expr/"+r.expr.String(), ok, !ok, r.cooldown, now) {
+		case ALERT_FIRED:
+			fired = append(fired, alertNotice{severity: r.severity, line: formatRuleLine(r, values, true)})
+		case ALERT_RESOLVED:
+			if t.notifyResolved {
+				resolved = append(resolved, alertNotice{severity: r.severity, line: formatRuleLine(r, values, false)})
+			}
+		}
+	}
+
+	if len(fired) > 0 {
+		sendAlertNotices(t.notifier, "RuleEvaluatorTask", fmt.Sprintf("%s Rule Alert", t.notifier.GetKeyword()), fired, now)
+	}
+	if len(resolved) > 0 {
+		sendAlertNotices(t.notifier, "RuleEvaluatorTask", fmt.Sprintf("%s Rule Resolved", t.notifier.GetKeyword()), resolved, now)
+	}
+}
+
+// formatRuleLine shows the rule with the current values of its metrics.
+func formatRuleLine(r expressionRule, values expr.Metrics, withMessage bool) string {
+	var current []string
+	for _, m := range r.expr.Metrics() {
+		if v, ok := values[m]; ok {
+			current = append(current, fmt.Sprintf("%s = %s", m, utils.FormatPrice(v)))
+		}
+	}
+	line := fmt.Sprintf("- **%s**: `%s`", r.name, r.expr.String())
+	if len(current) > 0 {
+		line += fmt.Sprintf(" (%s)", strings.Join(current, ", "))
+	}
+	if withMessage && r.message != "" {
+		line += "\n  " + r.message
+	}
+	return line
+}
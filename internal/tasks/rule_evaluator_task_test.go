@@ -0,0 +1,69 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ka1fe1/crypto-monitoring/config"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+type fakeMetricsService struct {
+	values    map[string]float64
+	err       error
+	requested []string
+}
+
+func (f *fakeMetricsService) Collect(names []string) (map[string]float64, error) {
+	f.requested = names
+	return f.values, f.err
+}
+
+func TestRuleEvaluatorTask_Run(t *testing.T) {
+	svc := &fakeMetricsService{values: map[string]float64{"btc.ahr999": 0.6, "fgi.value": 15, "polymarket.12345.yes": 0.5}}
+	notifier := &recordingNotifier{keyword: "rules"}
+	rules := []config.ExpressionRuleConfig{
+		{Name: "bottom", Expr: "btc.ahr999 < 0.45 && fgi.value < 20", Message: "Consider buying", Severity: constant.SEVERITY_CRITICAL},
+		{Expr: `polymarket["12345"].yes > 0.8`},
+		{Name: "broken", Expr: "btc.ahr999 <"},
+	}
+	task := NewRuleEvaluatorTask(svc, notifier, rules, true, nil, 60, utils.QuietHoursParams{})
+
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 0 {
+		t.Fatalf("expected no alert, got %+v", events)
+	}
+	if strings.Join(svc.requested, ",") != "btc.ahr999,fgi.value,polymarket.12345.yes" {
+		t.Errorf("unexpected requested metrics %v", svc.requested)
+	}
+
+	svc.values["btc.ahr999"] = 0.42
+	task.Run(context.Background())
+	events := notifier.take()
+	if len(events) != 1 || events[0].Severity != constant.SEVERITY_CRITICAL {
+		t.Fatalf("expected one critical alert, got %+v", events)
+	}
+	if !strings.Contains(events[0].Markdown, "**bottom**") || !strings.Contains(events[0].Markdown, "btc.ahr999 = 0.42") || !strings.Contains(events[0].Markdown, "Consider buying") {
+		t.Errorf("unexpected alert text: %s", events[0].Markdown)
+	}
+
+	// a failed fetch keeps the state instead of resolving
+	svc.values, svc.err = map[string]float64{}, errors.New("btc dashboard: timeout")
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 0 {
+		t.Fatalf("expected no message while metrics are missing, got %+v", events)
+	}
+
+	svc.values, svc.err = map[string]float64{"btc.ahr999": 0.5, "fgi.value": 15, "polymarket.12345.yes": 0.85}, nil
+	task.Run(context.Background())
+	events = notifier.take()
+	if len(events) != 2 || !strings.Contains(events[0].Title, "Rule Alert") || !strings.Contains(events[1].Title, "Rule Resolved") {
+		t.Fatalf("expected the polymarket alert and the bottom resolved notice, got %+v", events)
+	}
+	if !strings.Contains(events[0].Markdown, `polymarket["12345"].yes > 0.8`) || events[1].Severity != constant.SEVERITY_CRITICAL {
+		t.Errorf("unexpected events: %+v", events)
+	}
+}
@@ -0,0 +1,179 @@
+package tasks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/cron"
+)
+
+// RegisterOption customizes how a task is scheduled.
+type RegisterOption func(*scheduledTask)
+
+// RunOnStart runs the task once as soon as the scheduler starts instead of waiting a full interval.
+func RunOnStart() RegisterOption {
+	return func(s *scheduledTask) {
+		s.runOnStart = true
+	}
+}
+
+// WithSchedule runs the task at the times of a cron schedule instead of every Interval().
+// RunOnStart is ignored for scheduled tasks so they only fire at the configured times.
+func WithSchedule(schedule *cron.Schedule) RegisterOption {
+	return func(s *scheduledTask) {
+		s.schedule = schedule
+	}
+}
+
+type scheduledTask struct {
+	task       Task
+	runOnStart bool
+	schedule   *cron.Schedule
+	lastRun    time.Time
+}
+
+// Scheduler runs every registered task on its own ticker and applies its quiet hours.
+type Scheduler struct {
+	mu     sync.Mutex
+	tasks  []*scheduledTask
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Register adds a task. Tasks registered after Start are not scheduled.
+func (s *Scheduler) Register(t Task, opts ...RegisterOption) {
+	st := &scheduledTask{task: t}
+	for _, opt := range opts {
+		opt(st)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks = append(s.tasks, st)
+}
+
+// Tasks returns the registered tasks.
+func (s *Scheduler) Tasks() []Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := make([]Task, 0, len(s.tasks))
+	for _, st := range s.tasks {
+		tasks = append(tasks, st.task)
+	}
+	return tasks
+}
+
+// Start launches one goroutine per registered task. They stop when ctx is canceled or Stop is called.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, st := range s.tasks {
+		if st.schedule != nil {
+			logger.Info("Starting %s with schedule %s", st.task.Name(), st.schedule)
+		} else {
+			logger.Info("Starting %s with interval %v", st.task.Name(), st.task.Interval())
+		}
+		s.wg.Add(1)
+		go func(st *scheduledTask) {
+			defer s.wg.Done()
+			s.loop(ctx, st)
+		}(st)
+	}
+}
+
+// Stop cancels all tasks and waits for the running ones to return.
+// It gives up when ctx ends first and returns ctx.Err().
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	finished := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, st *scheduledTask) {
+	if st.schedule != nil {
+		s.cronLoop(ctx, st)
+		return
+	}
+
+	if st.runOnStart {
+		s.runOnce(ctx, st)
+	}
+
+	ticker := time.NewTicker(st.task.Interval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.runOnce(ctx, st)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Scheduler) cronLoop(ctx context.Context, st *scheduledTask) {
+	for {
+		next := st.schedule.Next(time.Now())
+		if next.IsZero() {
+			logger.Warn("Schedule %s of %s never fires again", st.schedule, st.task.Name())
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			s.runOnce(ctx, st)
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, st *scheduledTask) {
+	if ctx.Err() != nil {
+		return
+	}
+	if !utils.ShouldExecTask(st.task.QuietHours(), st.lastRun, st.task.Interval()) {
+		logger.Debug("Skipping %s in quiet hours", st.task.Name())
+		return
+	}
+	st.lastRun = time.Now()
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("%s panicked: %v", st.task.Name(), r)
+		}
+	}()
+	st.task.Run(ctx)
+}
@@ -0,0 +1,125 @@
+package tasks
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/cron"
+)
+
+type countingTask struct {
+	runs     atomic.Int32
+	interval time.Duration
+	blockCtx bool // Run blocks until ctx is canceled
+}
+
+func (t *countingTask) Name() string                       { return "countingTask" }
+func (t *countingTask) Interval() time.Duration            { return t.interval }
+func (t *countingTask) QuietHours() utils.QuietHoursParams { return utils.QuietHoursParams{} }
+
+func (t *countingTask) Run(ctx context.Context) {
+	t.runs.Add(1)
+	if t.blockCtx {
+		<-ctx.Done()
+	}
+}
+
+func TestScheduler_RunsTasks(t *testing.T) {
+	ticking := &countingTask{interval: 10 * time.Millisecond}
+	onStart := &countingTask{interval: time.Hour}
+
+	s := NewScheduler()
+	s.Register(ticking)
+	s.Register(onStart, RunOnStart())
+	s.Start(context.Background())
+
+	time.Sleep(55 * time.Millisecond)
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	if n := ticking.runs.Load(); n < 2 {
+		t.Errorf("expected the ticking task to run several times, got %d", n)
+	}
+	if n := onStart.runs.Load(); n != 1 {
+		t.Errorf("expected the run-on-start task to run once, got %d", n)
+	}
+
+	after := ticking.runs.Load()
+	time.Sleep(30 * time.Millisecond)
+	if ticking.runs.Load() != after {
+		t.Errorf("expected no runs after Stop")
+	}
+}
+
+func TestScheduler_StopCancelsRunningTask(t *testing.T) {
+	blocking := &countingTask{interval: time.Hour, blockCtx: true}
+
+	s := NewScheduler()
+	s.Register(blocking, RunOnStart())
+	s.Start(context.Background())
+
+	time.Sleep(10 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatalf("Stop did not cancel the running task: %v", err)
+	}
+}
+
+type stuckTask struct {
+	countingTask
+	release chan struct{}
+}
+
+func (t *stuckTask) Run(ctx context.Context) {
+	<-t.release // ignores ctx
+}
+
+func TestScheduler_StopTimeout(t *testing.T) {
+	stuck := &stuckTask{countingTask: countingTask{interval: time.Hour}, release: make(chan struct{})}
+	defer close(stuck.release)
+
+	s := NewScheduler()
+	s.Register(stuck, RunOnStart())
+	s.Start(context.Background())
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Stop(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestScheduler_CronSchedule(t *testing.T) {
+	everySecond, err := cron.Parse("* * * * * *")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	yearly, err := cron.Parse("@yearly")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	frequent := &countingTask{interval: time.Hour}
+	rare := &countingTask{interval: time.Hour}
+
+	s := NewScheduler()
+	s.Register(frequent, WithSchedule(everySecond))
+	// RunOnStart is ignored for scheduled tasks
+	s.Register(rare, WithSchedule(yearly), RunOnStart())
+	s.Start(context.Background())
+
+	time.Sleep(1100 * time.Millisecond)
+	s.Stop(context.Background())
+
+	if n := frequent.runs.Load(); n < 1 {
+		t.Errorf("expected the every-second task to run, got %d runs", n)
+	}
+	if n := rare.runs.Load(); n != 0 {
+		t.Errorf("expected the yearly task not to run on start, got %d runs", n)
+	}
+}
@@ -0,0 +1,20 @@
+package tasks
+
+import (
+	"context"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+)
+
+// Task is a periodic monitoring job driven by the Scheduler.
+type Task interface {
+	// Name identifies the task in logs.
+	Name() string
+	// Run performs a single execution. It should return early once ctx is canceled.
+	Run(ctx context.Context)
+	// Interval is the time between two runs.
+	Interval() time.Duration
+	// QuietHours controls whether a due run is skipped or throttled.
+	QuietHours() utils.QuietHoursParams
+}
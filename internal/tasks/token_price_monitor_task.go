@@ -1,31 +1,42 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/ka1fe1/crypto-monitoring/config"
 	"github.com/ka1fe1/crypto-monitoring/internal/service"
 	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
-	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter/dingding"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
 )
 
 type TokenPriceMonitorTask struct {
 	tokenService     service.TokenService
-	dingBot          *dingding.DingBot
-	ticker           *time.Ticker
-	stop             chan bool
+	notifier         alter.Notifier
 	tokenIds         []string
 	rwaTokenIds      []string
 	rwaTokenNames    map[string]string
+	rules            *tokenRules
+	summary          bool // send every token's price on each run
 	interval         time.Duration
 	quietHoursParams utils.QuietHoursParams
-	lastRunTime      time.Time
 }
 
-func NewTokenPriceMonitorTask(tokenService service.TokenService, dingBot *dingding.DingBot, tokenIdsStr string, rwaTokenIds []string, rwaTokenNames map[string]string, intervalSeconds int, quietHoursParams utils.QuietHoursParams) *TokenPriceMonitorTask {
+// TokenPriceAlertOptions configures the price alert rules of a TokenPriceMonitorTask.
+type TokenPriceAlertOptions struct {
+	Rules          []config.TokenAlertRuleConfig
+	Summary        bool          // send every token's price on each run
+	NotifyResolved bool          // send a notice when a fired rule clears
+	HistoryPath    string        // daily highs/lows, only loaded when a rule uses high_low_days
+	Tracker        *AlertTracker // shared by the tasks of one bot, nil for a private tracker
+}
+
+func NewTokenPriceMonitorTask(tokenService service.TokenService, notifier alter.Notifier, tokenIdsStr string, rwaTokenIds []string, rwaTokenNames map[string]string, alerts TokenPriceAlertOptions, intervalSeconds int, quietHoursParams utils.QuietHoursParams) *TokenPriceMonitorTask {
 	interval := time.Duration(intervalSeconds) * time.Second
 	if interval <= 0 {
 		interval = 60 * time.Second
@@ -41,51 +52,48 @@ func NewTokenPriceMonitorTask(tokenService service.TokenService, dingBot *dingdi
 		}
 	}
 
-	return &TokenPriceMonitorTask{
+	t := &TokenPriceMonitorTask{
 		tokenService:     tokenService,
-		dingBot:          dingBot,
-		stop:             make(chan bool),
+		notifier:         notifier,
 		tokenIds:         tokenIds,
 		rwaTokenIds:      rwaTokenIds,
 		rwaTokenNames:    rwaTokenNames,
+		rules:            newTokenRules("TokenPriceMonitorTask", alerts.Rules, alerts.Tracker, alerts.NotifyResolved),
+		summary:          alerts.Summary,
 		interval:         interval,
 		quietHoursParams: quietHoursParams,
 	}
-
+	if t.rules.historyDays > 0 && alerts.HistoryPath != "" {
+		t.rules.history = loadTokenPriceHistory(alerts.HistoryPath)
+	}
+	return t
 }
 
-func (t *TokenPriceMonitorTask) Start() {
-	t.ticker = time.NewTicker(t.interval)
-	go func() {
-		for {
-			select {
-			case <-t.ticker.C:
-				t.run()
-			case <-t.stop:
-				t.ticker.Stop()
-				return
-			}
-		}
-	}()
+func (t *TokenPriceMonitorTask) Name() string {
+	return "TokenPriceMonitorTask"
 }
 
-func (t *TokenPriceMonitorTask) Stop() {
-	t.stop <- true
+func (t *TokenPriceMonitorTask) Interval() time.Duration {
+	return t.interval
 }
 
-func (t *TokenPriceMonitorTask) run() {
-	if len(t.tokenIds) == 0 && len(t.rwaTokenIds) == 0 {
-		return
-	}
+func (t *TokenPriceMonitorTask) QuietHours() utils.QuietHoursParams {
+	return t.quietHoursParams
+}
 
-	if !utils.ShouldExecTask(t.quietHoursParams, t.lastRunTime, t.interval) {
+func (t *TokenPriceMonitorTask) Run(ctx context.Context) {
+	if len(t.tokenIds) == 0 && len(t.rwaTokenIds) == 0 && len(t.rules.rules) == 0 {
 		return
 	}
-	t.lastRunTime = time.Now()
 
 	var allTokenIds []string
 	allTokenIds = append(allTokenIds, t.tokenIds...)
 	allTokenIds = append(allTokenIds, t.rwaTokenIds...)
+	for _, id := range ruleTokenIDs(t.rules.rules) {
+		if !slices.Contains(allTokenIds, id) {
+			allTokenIds = append(allTokenIds, id)
+		}
+	}
 
 	prices, err := t.tokenService.GetTokenPrice(allTokenIds)
 	if err != nil {
@@ -97,6 +105,17 @@ func (t *TokenPriceMonitorTask) run() {
 		return
 	}
 
+	if len(t.rules.rules) > 0 {
+		now := time.Now()
+		fired, resolved := t.rules.evaluate(prices, now)
+		t.rules.send(t.notifier, fired, resolved, latestUpdate(prices))
+		t.rules.recordHistory(prices, now)
+	}
+
+	if !t.summary {
+		return
+	}
+
 	var cnyPrices map[string]utils.TokenInfo
 	hasPaxg := false
 	for _, id := range t.tokenIds {
@@ -120,19 +139,29 @@ func (t *TokenPriceMonitorTask) run() {
 	}
 
 	// Aggregate messages
-	unifiedTitle := fmt.Sprintf("%s Price Alerts", t.dingBot.Keyword)
+	unifiedTitle := fmt.Sprintf("%s Price Alerts", t.notifier.GetKeyword())
 
 	unifiedText := fmt.Sprintf("#### %s\n\n%s", unifiedTitle, formatted)
 	unifiedText += fmt.Sprintf("\n\n---\n**Last Updated**: %s", utils.FormatBJTime(lastUpdated))
 
-	err = t.dingBot.SendMarkdown(unifiedTitle, unifiedText, nil, false)
+	err = alter.Notify(t.notifier, alter.NewEvent("TokenPriceMonitorTask", constant.SEVERITY_INFO, unifiedTitle, unifiedText))
 	if err != nil {
-		logger.Error("Error sending dingtalk message: %v", err)
+		logger.Error("Error sending notification: %v", err)
 	} else {
 		logger.Info("Sent batch token price alerts")
 	}
 }
 
+func latestUpdate(prices map[string]utils.TokenInfo) time.Time {
+	var latest time.Time
+	for _, p := range prices {
+		if p.LastUpdated.After(latest) {
+			latest = p.LastUpdated
+		}
+	}
+	return latest
+}
+
 // formatTokenPricesDetailed returns the format used by TokenPriceMonitorTask
 func (t *TokenPriceMonitorTask) formatTokenPricesDetailed(prices map[string]utils.TokenInfo, cnyPrices map[string]utils.TokenInfo, tokenIds []string, rwaTokenIds []string, rwaTokenNames map[string]string) (string, time.Time) {
 	var parts []string
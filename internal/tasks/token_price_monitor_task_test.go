@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"context"
 	"strings"
 	"testing"
 
@@ -39,11 +40,11 @@ func TestTokenPriceMonitorTask_Run(t *testing.T) {
 	qh := utils.QuietHoursParams{Enabled: false, StartHour: 0, EndHour: 7, Behavior: constant.QUIET_HOURS_BEHAVIOR_PAUSE}
 
 	// Create task with a short interval for testing, though we call run() manually
-	task := NewTokenPriceMonitorTask(tokenSvc, bot, tokenIdsStr, cfg.TokenPriceMonitor.RwaTokenIDs, cfg.TokenPriceMonitor.RwaTokenNames, 60, qh)
+	task := NewTokenPriceMonitorTask(tokenSvc, bot, tokenIdsStr, cfg.TokenPriceMonitor.RwaTokenIDs, cfg.TokenPriceMonitor.RwaTokenNames, TokenPriceAlertOptions{Summary: true}, 60, qh)
 
 	// Manually trigger run to test logic and notification
 	// This will call the real API and send a real DingTalk message is configured
-	task.run()
+	task.Run(context.Background())
 }
 
 func TestFormatTokenPricesDetailed_Paxg(t *testing.T) {
@@ -0,0 +1,222 @@
+package tasks
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/config"
+	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+// tokenRules evaluates price alert rules against a shared AlertTracker. Keys only depend on
+// the condition, so tasks using the same tracker (one per bot) report each alert once.
+type tokenRules struct {
+	taskName       string
+	rules          []config.TokenAlertRuleConfig
+	alerts         *AlertTracker
+	notifyResolved bool
+	history        *tokenPriceHistory // nil disables high_low_days conditions
+	historyDays    int
+}
+
+func newTokenRules(taskName string, rules []config.TokenAlertRuleConfig, alerts *AlertTracker, notifyResolved bool) *tokenRules {
+	if alerts == nil {
+		alerts = NewAlertTracker()
+	}
+	r := &tokenRules{taskName: taskName, rules: rules, alerts: alerts, notifyResolved: notifyResolved}
+	for _, rule := range rules {
+		if rule.HighLowDays > r.historyDays {
+			r.historyDays = rule.HighLowDays
+		}
+	}
+	return r
+}
+
+// evaluate returns the conditions that fired and resolved since the previous run. A condition
+// that stays true does not alert again until it has cleared past the rule's hysteresis band.
+func (r *tokenRules) evaluate(prices map[string]utils.TokenInfo, now time.Time) (fired, resolved []alertNotice) {
+	for _, rule := range r.rules {
+		p, ok := prices[rule.TokenID]
+		if !ok {
+			continue
+		}
+		severity := rule.Severity
+		if severity == "" {
+			severity = constant.SEVERITY_WARNING
+		}
+		cooldown := time.Duration(rule.CooldownMinutes) * time.Minute
+		band := rule.HysteresisPercent / 100
+
+		// resolvedFormat is empty for one-shot conditions such as new highs, they re-arm silently
+		check := func(key string, triggered, cleared bool, resolvedFormat, format string, args ...interface{}) {
+			price := fmt.Sprintf("- **%s**: ***$%s*** ", p.Symbol, utils.FormatPrice(p.Price))
+			switch r.alerts.Observe("token/"+rule.TokenID+"/"+key, triggered, cleared, cooldown, now) {
+			case ALERT_FIRED:
+				fired = append(fired, alertNotice{severity: severity, line: price + fmt.Sprintf(format, args...)})
+			case ALERT_RESOLVED:
+				if r.notifyResolved && resolvedFormat != "" {
+					resolved = append(resolved, alertNotice{severity: severity, line: price + fmt.Sprintf(resolvedFormat, args...)})
+				}
+			}
+		}
+
+		if rule.Above > 0 {
+			triggered, cleared := aboveLevel(p.Price, rule.Above, band)
+			level := utils.FormatPrice(rule.Above)
+			check(fmt.Sprintf("above/%g", rule.Above), triggered, cleared, "back below $%s", "above $%s", level)
+		}
+		if rule.Below > 0 {
+			triggered, cleared := belowLevel(p.Price, rule.Below, band)
+			level := utils.FormatPrice(rule.Below)
+			check(fmt.Sprintf("below/%g", rule.Below), triggered, cleared, "back above $%s", "below $%s", level)
+		}
+		if rule.Change1h > 0 {
+			triggered, cleared := aboveLevel(math.Abs(p.PercentChange1h), rule.Change1h, band)
+			check(fmt.Sprintf("change_1h/%g", rule.Change1h), triggered, cleared, "1h move back to %+.2f%%", "moved %+.2f%% in 1h", p.PercentChange1h)
+		}
+		if rule.Change24h > 0 {
+			triggered, cleared := aboveLevel(math.Abs(p.PercentChange24h), rule.Change24h, band)
+			check(fmt.Sprintf("change_24h/%g", rule.Change24h), triggered, cleared, "24h move back to %+.2f%%", "moved %+.2f%% in 24h", p.PercentChange24h)
+		}
+		if rule.HighLowDays > 0 && r.history != nil {
+			high, low, ok := r.history.extremes(rule.TokenID, now, rule.HighLowDays)
+			if ok {
+				_, clearedHigh := aboveLevel(p.Price, high, band)
+				check(fmt.Sprintf("high/%d", rule.HighLowDays), p.Price > high, clearedHigh, "", "new %d-day high (previous $%s)", rule.HighLowDays, utils.FormatPrice(high))
+				_, clearedLow := belowLevel(p.Price, low, band)
+				check(fmt.Sprintf("low/%d", rule.HighLowDays), p.Price < low, clearedLow, "", "new %d-day low (previous $%s)", rule.HighLowDays, utils.FormatPrice(low))
+			}
+		}
+	}
+	return fired, resolved
+}
+
+// send reports the fired conditions as one message with the highest severity among them,
+// followed by the resolved ones. Resolved notices keep the alert's severity so min_severity
+// filters let them through whenever the alert itself got through.
+func (r *tokenRules) send(notifier alter.Notifier, fired, resolved []alertNotice, lastUpdated time.Time) {
+	if len(fired) > 0 {
+		title := fmt.Sprintf("%s Price Alert", notifier.GetKeyword())
+		sendAlertNotices(notifier, r.taskName, title, fired, lastUpdated)
+	}
+	if len(resolved) > 0 {
+		title := fmt.Sprintf("%s Price Alert Resolved", notifier.GetKeyword())
+		sendAlertNotices(notifier, r.taskName, title, resolved, lastUpdated)
+	}
+}
+
+// recordHistory adds the prices of rule tokens to the daily high/low history.
+func (r *tokenRules) recordHistory(prices map[string]utils.TokenInfo, now time.Time) {
+	if r.history == nil {
+		return
+	}
+	for _, id := range ruleTokenIDs(r.rules) {
+		if p, ok := prices[id]; ok {
+			r.history.record(id, p.Price, now)
+		}
+	}
+	r.history.prune(now, r.historyDays+1)
+	if err := r.history.save(); err != nil {
+		logger.Error("Failed to save token price history: %v", err)
+	}
+}
+
+type dailyRange struct {
+	High float64 `json:"high"`
+	Low  float64 `json:"low"`
+}
+
+// tokenPriceHistory keeps the daily high and low of each token in a JSON file,
+// so N-day highs and lows survive restarts. Days are Beijing calendar days.
+type tokenPriceHistory struct {
+	path string
+	days map[string]map[string]dailyRange // token id -> "2006-01-02" -> range
+}
+
+var historyLocation = time.FixedZone("CST", 8*3600)
+
+func loadTokenPriceHistory(path string) *tokenPriceHistory {
+	h := &tokenPriceHistory{path: path}
+	utils.LoadStateFile(path, &h.days)
+	if h.days == nil {
+		h.days = make(map[string]map[string]dailyRange)
+	}
+	return h
+}
+
+func (h *tokenPriceHistory) record(tokenID string, price float64, now time.Time) {
+	day := now.In(historyLocation).Format("2006-01-02")
+	days, ok := h.days[tokenID]
+	if !ok {
+		days = make(map[string]dailyRange)
+		h.days[tokenID] = days
+	}
+	r, ok := days[day]
+	if !ok {
+		r = dailyRange{High: price, Low: price}
+	}
+	r.High = math.Max(r.High, price)
+	r.Low = math.Min(r.Low, price)
+	days[day] = r
+}
+
+// extremes returns the highest high and lowest low over the last n days including today.
+// ok is false until the history reaches back n days, so a fresh install does not report every price as a new high.
+func (h *tokenPriceHistory) extremes(tokenID string, now time.Time, n int) (high, low float64, ok bool) {
+	days := h.days[tokenID]
+	if len(days) == 0 {
+		return 0, 0, false
+	}
+	local := now.In(historyLocation)
+	first := local.AddDate(0, 0, -n).Format("2006-01-02")
+
+	covered := false
+	low = math.Inf(1)
+	for day, r := range days {
+		if day <= first {
+			covered = true
+			continue
+		}
+		high = math.Max(high, r.High)
+		low = math.Min(low, r.Low)
+	}
+	if !covered || math.IsInf(low, 1) {
+		return 0, 0, false
+	}
+	return high, low, true
+}
+
+// prune drops days older than keep days.
+func (h *tokenPriceHistory) prune(now time.Time, keep int) {
+	oldest := now.In(historyLocation).AddDate(0, 0, -keep).Format("2006-01-02")
+	for _, days := range h.days {
+		for day := range days {
+			if day < oldest {
+				delete(days, day)
+			}
+		}
+	}
+}
+
+func (h *tokenPriceHistory) save() error {
+	return utils.SaveStateFile(h.path, h.days)
+}
+
+// ruleTokenIDs returns the token ids referenced by rules, sorted and without duplicates.
+func ruleTokenIDs(rules []config.TokenAlertRuleConfig) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, r := range rules {
+		if r.TokenID != "" && !seen[r.TokenID] {
+			seen[r.TokenID] = true
+			ids = append(ids, r.TokenID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
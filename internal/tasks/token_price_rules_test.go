@@ -0,0 +1,191 @@
+package tasks
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/config"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+type fakeTokenService struct {
+	prices map[string]utils.TokenInfo
+}
+
+func (f *fakeTokenService) GetTokenPrice(ids []string, convert ...string) (map[string]utils.TokenInfo, error) {
+	out := make(map[string]utils.TokenInfo)
+	for _, id := range ids {
+		if p, ok := f.prices[id]; ok {
+			out[id] = p
+		}
+	}
+	return out, nil
+}
+
+func TestTokenPriceMonitorTask_Rules(t *testing.T) {
+	svc := &fakeTokenService{prices: map[string]utils.TokenInfo{
+		"1": {Symbol: "BTC", Price: 59000, PercentChange1h: 0.5, PercentChange24h: 1},
+	}}
+	notifier := &recordingNotifier{keyword: "token"}
+	rules := []config.TokenAlertRuleConfig{
+		{TokenID: "1", Above: 60000, Change1h: 3, Severity: constant.SEVERITY_CRITICAL},
+		{TokenID: "1", Below: 50000},
+	}
+	task := NewTokenPriceMonitorTask(svc, notifier, "", nil, nil, TokenPriceAlertOptions{Rules: rules}, 60, utils.QuietHoursParams{})
+
+	// Nothing tripped, no summary
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 0 {
+		t.Fatalf("expected no message, got %+v", events)
+	}
+
+	// Crossing above alerts once
+	svc.prices["1"] = utils.TokenInfo{Symbol: "BTC", Price: 61000, PercentChange1h: 0.5}
+	task.Run(context.Background())
+	events := notifier.take()
+	if len(events) != 1 || !strings.Contains(events[0].Markdown, "above $60000.00") || events[0].Severity != constant.SEVERITY_CRITICAL {
+		t.Fatalf("expected one critical above alert, got %+v", events)
+	}
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 0 {
+		t.Fatalf("expected no repeat while the price stays above, got %+v", events)
+	}
+
+	// A sharp 1h move and a drop below alert together
+	svc.prices["1"] = utils.TokenInfo{Symbol: "BTC", Price: 49000, PercentChange1h: -4.2}
+	task.Run(context.Background())
+	events = notifier.take()
+	if len(events) != 1 {
+		t.Fatalf("expected one aggregated alert, got %+v", events)
+	}
+	if !strings.Contains(events[0].Markdown, "moved -4.20% in 1h") || !strings.Contains(events[0].Markdown, "below $50000.00") {
+		t.Errorf("unexpected alert text: %s", events[0].Markdown)
+	}
+}
+
+func TestTokenPriceMonitorTask_Summary(t *testing.T) {
+	svc := &fakeTokenService{prices: map[string]utils.TokenInfo{"1": {Symbol: "BTC", Price: 59000}}}
+	notifier := &recordingNotifier{keyword: "token"}
+	task := NewTokenPriceMonitorTask(svc, notifier, "1", nil, nil, TokenPriceAlertOptions{Summary: true}, 60, utils.QuietHoursParams{})
+
+	task.Run(context.Background())
+	events := notifier.take()
+	if len(events) != 1 || events[0].Severity != constant.SEVERITY_INFO || !strings.Contains(events[0].Markdown, "BTC") {
+		t.Fatalf("expected the periodic summary, got %+v", events)
+	}
+}
+
+func TestTokenPriceHistory_Extremes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	h := loadTokenPriceHistory(path)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, historyLocation)
+
+	h.record("1", 100, now.AddDate(0, 0, -2))
+	if _, _, ok := h.extremes("1", now, 3); ok {
+		t.Errorf("expected no extremes before the history covers 3 days")
+	}
+
+	h.record("1", 90, now.AddDate(0, 0, -3))
+	h.record("1", 120, now.AddDate(0, 0, -1))
+	h.record("1", 80, now.AddDate(0, 0, -1))
+	high, low, ok := h.extremes("1", now, 3)
+	if !ok || high != 120 || low != 80 {
+		t.Errorf("expected high 120 and low 80, got %v %v %v", high, low, ok)
+	}
+
+	if err := h.save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	reloaded := loadTokenPriceHistory(path)
+	reloaded.prune(now, 2)
+	if _, ok := reloaded.days["1"][now.AddDate(0, 0, -3).Format("2006-01-02")]; ok {
+		t.Errorf("expected old days to be pruned")
+	}
+	if _, _, ok := reloaded.extremes("1", now, 1); ok {
+		t.Errorf("expected no extremes without data inside the window")
+	}
+}
+
+func TestTokenPriceMonitorTask_NewHigh(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	now := time.Now()
+	h := loadTokenPriceHistory(path)
+	for d := 1; d <= 7; d++ {
+		h.record("1", 100-float64(d), now.AddDate(0, 0, -d))
+	}
+	if err := h.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := &fakeTokenService{prices: map[string]utils.TokenInfo{"1": {Symbol: "BTC", Price: 105}}}
+	notifier := &recordingNotifier{keyword: "token"}
+	rules := []config.TokenAlertRuleConfig{{TokenID: "1", HighLowDays: 5}}
+	task := NewTokenPriceMonitorTask(svc, notifier, "", nil, nil, TokenPriceAlertOptions{Rules: rules, HistoryPath: path}, 60, utils.QuietHoursParams{})
+
+	task.Run(context.Background())
+	events := notifier.take()
+	if len(events) != 1 || !strings.Contains(events[0].Markdown, "new 5-day high") {
+		t.Fatalf("expected a new high alert, got %+v", events)
+	}
+}
+
+func TestTokenPriceMonitorTask_ResolvedWithHysteresis(t *testing.T) {
+	svc := &fakeTokenService{prices: map[string]utils.TokenInfo{"1": {Symbol: "BTC", Price: 61000}}}
+	notifier := &recordingNotifier{keyword: "token"}
+	rules := []config.TokenAlertRuleConfig{{TokenID: "1", Above: 60000, HysteresisPercent: 1, Severity: constant.SEVERITY_CRITICAL}}
+	task := NewTokenPriceMonitorTask(svc, notifier, "", nil, nil, TokenPriceAlertOptions{Rules: rules, NotifyResolved: true}, 60, utils.QuietHoursParams{})
+
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 1 {
+		t.Fatalf("expected the above alert, got %+v", events)
+	}
+
+	// Inside the 1% band: neither resolved nor fired again
+	for _, price := range []float64{59500, 60100, 59500} {
+		svc.prices["1"] = utils.TokenInfo{Symbol: "BTC", Price: price}
+		task.Run(context.Background())
+		if events := notifier.take(); len(events) != 0 {
+			t.Fatalf("expected no message at %v, got %+v", price, events)
+		}
+	}
+
+	svc.prices["1"] = utils.TokenInfo{Symbol: "BTC", Price: 59000}
+	task.Run(context.Background())
+	events := notifier.take()
+	if len(events) != 1 || !strings.Contains(events[0].Title, "Resolved") || !strings.Contains(events[0].Markdown, "back below $60000.00") {
+		t.Fatalf("expected a resolved notice, got %+v", events)
+	}
+	if events[0].Severity != constant.SEVERITY_CRITICAL {
+		t.Errorf("expected the resolved notice to keep the alert severity, got %s", events[0].Severity)
+	}
+}
+
+func TestTokenAlerts_SharedAcrossTasks(t *testing.T) {
+	svc := &fakeTokenService{prices: map[string]utils.TokenInfo{constant.PAXG_TOKEN_ID: {Symbol: "PAXG", Price: 3100}}}
+	notifier := &recordingNotifier{keyword: "token"}
+	alerts := TokenPriceAlertOptions{
+		Rules:          []config.TokenAlertRuleConfig{{TokenID: constant.PAXG_TOKEN_ID, Above: 3000}},
+		NotifyResolved: true,
+		Tracker:        NewAlertTracker(),
+	}
+	tokenTask := NewTokenPriceMonitorTask(svc, notifier, "", nil, nil, alerts, 60, utils.QuietHoursParams{})
+	generalTask := NewGeneralMonitorTask(svc, nil, notifier, []string{"token_alerts"}, nil, nil, nil, nil, alerts, 60, utils.QuietHoursParams{})
+
+	tokenTask.Run(context.Background())
+	generalTask.Run(context.Background())
+	if events := notifier.take(); len(events) != 1 {
+		t.Fatalf("expected PAXG to be reported once by the two tasks, got %+v", events)
+	}
+
+	svc.prices[constant.PAXG_TOKEN_ID] = utils.TokenInfo{Symbol: "PAXG", Price: 2900}
+	generalTask.Run(context.Background())
+	tokenTask.Run(context.Background())
+	events := notifier.take()
+	if len(events) != 1 || !strings.Contains(events[0].Title, "Resolved") {
+		t.Fatalf("expected one resolved notice, got %+v", events)
+	}
+}
@@ -0,0 +1,89 @@
+package tasks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ka1fe1/crypto-monitoring/internal/service"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/twitter"
+)
+
+type fakeTwitterService struct {
+	tweets  []twitter.Tweet
+	lastIDs []string // lastID of every call
+}
+
+func (f *fakeTwitterService) FetchNewTweets(username string, withinTime string, lastID string, keywords []string) ([]twitter.Tweet, string, error) {
+	f.lastIDs = append(f.lastIDs, lastID)
+	if len(f.tweets) == 0 {
+		return nil, "", nil
+	}
+	return f.tweets, f.tweets[0].ID, nil
+}
+
+func TestTwitterMonitorTask_CursorsSurviveRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "twitter.json")
+	svc := &fakeTwitterService{tweets: []twitter.Tweet{{ID: "102", AuthorName: "CZ", URL: "https://x.com/cz_binance/status/102"}, {ID: "101"}}}
+	notifier := &recordingNotifier{keyword: "x"}
+
+	task := NewTwitterMonitorTask(svc, notifier, []string{"cz_binance"}, nil, "6h", service.NewTwitterCursors(path), 60, utils.QuietHoursParams{})
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 1 {
+		t.Fatalf("expected one notification, got %+v", events)
+	}
+
+	// after a restart the search continues after 102 and tweets returned again are not re-sent
+	task = NewTwitterMonitorTask(svc, notifier, []string{"cz_binance"}, nil, "6h", service.NewTwitterCursors(path), 60, utils.QuietHoursParams{})
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 0 {
+		t.Fatalf("expected sent tweets to be skipped, got %+v", events)
+	}
+	if got := svc.lastIDs; len(got) != 2 || got[0] != "" || got[1] != "102" {
+		t.Errorf("expected the second search to start after 102, got %v", got)
+	}
+
+	cursors := service.NewTwitterCursors(path)
+	if found, err := cursors.Reset("cz_binance", false); !found || err != nil {
+		t.Fatalf("Reset: %v, %v", found, err)
+	}
+	if c := service.NewTwitterCursors(path).Get("cz_binance"); c.LastID != "" || len(c.SentIDs) != 2 {
+		t.Errorf("expected the reset to be saved with the sent tweets kept, got %+v", c)
+	}
+
+	// the search starts over within_time, the tweets sent before are still skipped
+	task = NewTwitterMonitorTask(svc, notifier, []string{"cz_binance"}, nil, "6h", service.NewTwitterCursors(path), 60, utils.QuietHoursParams{})
+	task.Run(context.Background())
+	if events := notifier.take(); len(events) != 0 || svc.lastIDs[2] != "" {
+		t.Fatalf("expected a fresh search without re-sent tweets, got %+v after %v", events, svc.lastIDs)
+	}
+
+	cursors = service.NewTwitterCursors(path)
+	if found, err := cursors.Reset("", true); !found || err != nil {
+		t.Fatalf("Reset: %v, %v", found, err)
+	}
+	if c := service.NewTwitterCursors(path).Get("cz_binance"); c.LastID != "" || len(c.SentIDs) != 0 {
+		t.Errorf("expected the sent tweets to be dropped, got %+v", c)
+	}
+}
+
+func TestTwitterCursors_NullStateFile(t *testing.T) {
+	for _, content := range []string{`null`, `{"cz_binance": null}`} {
+		path := filepath.Join(t.TempDir(), "twitter.json")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cursors := service.NewTwitterCursors(path)
+		if cur := cursors.Get("cz_binance"); cur.LastID != "" {
+			t.Errorf("%s: expected no cursor, got %+v", content, cur)
+		}
+		if err := cursors.Advance("cz_binance", "102", []string{"102"}); err != nil {
+			t.Fatalf("%s: Advance: %v", content, err)
+		}
+		if cur := cursors.Get("cz_binance"); cur.LastID != "102" {
+			t.Errorf("%s: expected the cursor to advance, got %+v", content, cur)
+		}
+	}
+}
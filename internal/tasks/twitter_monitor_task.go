@@ -1,45 +1,45 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
-	"sync"
+	"slices"
 	"time"
 
 	"github.com/ka1fe1/crypto-monitoring/internal/service"
 	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils"
-	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter/dingding"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/twitter"
 )
 
 type TwitterMonitorTask struct {
 	twitterMonitorService service.TwitterService
-	dingBot               *dingding.DingBot
-	ticker                *time.Ticker
-	stop                  chan bool
+	notifier              alter.Notifier
 	usernames             []string
 	interval              time.Duration
-	lastTweetIDs          map[string]string
-	lastTweetLock         sync.RWMutex
+	cursors               *service.TwitterCursors
 	quietHoursParams      utils.QuietHoursParams
-	lastRunTime           time.Time
 	keywords              map[string][]string
 	withinTime            string
 }
 
-func NewTwitterMonitorTask(twitterMonitorService service.TwitterService, dingBot *dingding.DingBot, usernames []string, keywords map[string][]string, withinTime string, intervalSeconds int, quietHoursParams utils.QuietHoursParams) *TwitterMonitorTask {
+func NewTwitterMonitorTask(twitterMonitorService service.TwitterService, notifier alter.Notifier, usernames []string, keywords map[string][]string, withinTime string, cursors *service.TwitterCursors, intervalSeconds int, quietHoursParams utils.QuietHoursParams) *TwitterMonitorTask {
 	interval := time.Duration(intervalSeconds) * time.Second
 	if interval <= 0 {
 		interval = 600 * time.Second // Default 10 minutes
 	}
+	if cursors == nil {
+		cursors = service.NewTwitterCursors("")
+	}
 
 	return &TwitterMonitorTask{
 		twitterMonitorService: twitterMonitorService,
-		dingBot:               dingBot,
-		stop:                  make(chan bool),
+		notifier:              notifier,
 		usernames:             usernames,
 		interval:              interval,
-		lastTweetIDs:          make(map[string]string),
+		cursors:               cursors,
 		quietHoursParams:      quietHoursParams,
 		keywords:              keywords,
 		withinTime:            withinTime,
@@ -47,71 +47,65 @@ func NewTwitterMonitorTask(twitterMonitorService service.TwitterService, dingBot
 
 }
 
-func (t *TwitterMonitorTask) Start() {
-	t.ticker = time.NewTicker(t.interval)
-	logger.Info("Starting Twitter Monitor Task with interval %v, monitoring %d accounts", t.interval, len(t.usernames))
-
-	// Run immediately on start
-	go t.run()
-
-	go func() {
-		for {
-			select {
-			case <-t.ticker.C:
-				t.run()
-			case <-t.stop:
-				t.ticker.Stop()
-				return
-			}
-		}
-	}()
+func (t *TwitterMonitorTask) Name() string {
+	return "TwitterMonitorTask"
 }
 
-func (t *TwitterMonitorTask) Stop() {
-	t.stop <- true
+func (t *TwitterMonitorTask) Interval() time.Duration {
+	return t.interval
 }
 
-func (t *TwitterMonitorTask) run() {
-	if len(t.usernames) == 0 {
-		return
-	}
+func (t *TwitterMonitorTask) QuietHours() utils.QuietHoursParams {
+	return t.quietHoursParams
+}
 
-	// Check for Quiet Hours
-	if !utils.ShouldExecTask(t.quietHoursParams, t.lastRunTime, t.interval) {
-		logger.Info("Skipping Twitter Monitor Task for %s in quiet hours", t.dingBot.Keyword)
+func (t *TwitterMonitorTask) Run(ctx context.Context) {
+	if len(t.usernames) == 0 {
 		return
 	}
-	t.lastRunTime = time.Now()
 
 	for _, username := range t.usernames {
+		if ctx.Err() != nil {
+			return
+		}
 		t.monitorUser(username)
 	}
 }
 
 func (t *TwitterMonitorTask) monitorUser(username string) {
-	t.lastTweetLock.RLock()
-	lastID := t.lastTweetIDs[username]
-	t.lastTweetLock.RUnlock()
+	cursor := t.cursors.Get(username)
 
-	newTweets, newestID, err := t.twitterMonitorService.FetchNewTweets(username, t.withinTime, lastID, t.keywords[username])
+	newTweets, newestID, err := t.twitterMonitorService.FetchNewTweets(username, t.withinTime, cursor.LastID, t.keywords[username])
 	if err != nil {
 		logger.Error("Error searching tweets for %s: %v", username, err)
 		return
 	}
 
-	if len(newTweets) > 0 {
-		t.notifyTweets(username, newTweets)
+	// a search window overlapping the previous one may return tweets that were already sent
+	var unsent []twitter.Tweet
+	var sentIDs []string
+	for _, tweet := range newTweets {
+		if !slices.Contains(cursor.SentIDs, tweet.ID) {
+			unsent = append(unsent, tweet)
+			sentIDs = append(sentIDs, tweet.ID)
+		}
+	}
 
-		t.lastTweetLock.Lock()
-		t.lastTweetIDs[username] = newestID
-		t.lastTweetLock.Unlock()
+	if len(unsent) > 0 {
+		t.notifyTweets(username, unsent)
+	}
+	if newestID == "" && len(sentIDs) == 0 {
+		return
+	}
+	if err := t.cursors.Advance(username, newestID, sentIDs); err != nil {
+		logger.Error("Failed to save Twitter cursor for %s: %v", username, err)
 	}
 }
 
 func (t *TwitterMonitorTask) notifyTweets(username string, tweets []twitter.Tweet) {
-	title := fmt.Sprintf("%s [%s - %s] New Tweets", t.dingBot.Keyword, tweets[0].AuthorName, username)
+	title := fmt.Sprintf("%s [%s - %s] New Tweets", t.notifier.GetKeyword(), tweets[0].AuthorName, username)
 
-	content := t.formatTweets(tweets)
+	content, buttons := t.formatTweets(tweets)
 
 	allTexts := fmt.Sprintf("## %s\n\n --- \n\n%s\n\n---\n**Last Updated**: %s",
 		title,
@@ -119,16 +113,19 @@ func (t *TwitterMonitorTask) notifyTweets(username string, tweets []twitter.Twee
 		utils.FormatBJTime(time.Now()),
 	)
 
-	err := t.dingBot.SendMarkdown(title, allTexts, nil, false)
+	event := alter.NewEvent("TwitterMonitorTask", constant.SEVERITY_INFO, title, allTexts).WithButtons(buttons...)
+	err := alter.Notify(t.notifier, event)
 	if err != nil {
-		logger.Error("Error sending DingTalk notification for %s: %v", username, err)
+		logger.Error("Error sending notification for %s: %v", username, err)
 	} else {
 		logger.Info("Notified %d new tweets for %s", len(tweets), username)
 	}
 }
 
-func (t *TwitterMonitorTask) formatTweets(tweets []twitter.Tweet) string {
+// formatTweets renders the tweets as markdown and returns one "View on Twitter" button per tweet.
+func (t *TwitterMonitorTask) formatTweets(tweets []twitter.Tweet) (string, []alter.Button) {
 	var content string
+	var buttons []alter.Button
 
 	// Display newest first (presumed sorted)
 	for i := 0; i < len(tweets); i++ {
@@ -137,17 +134,26 @@ func (t *TwitterMonitorTask) formatTweets(tweets []twitter.Tweet) string {
 		if tweet.IsReply {
 			isReplyStr = "Yes"
 		}
-		content += fmt.Sprintf("- Type: %s | IsReply: %s\n", tweet.Type, isReplyStr)
+		index := ""
+		if len(tweets) > 1 {
+			index = fmt.Sprintf("**#%d** ", i+1)
+		}
+		content += fmt.Sprintf("- %sType: %s | IsReply: %s\n", index, tweet.Type, isReplyStr)
 		content += fmt.Sprintf("- %s\n", tweet.Text)
 		if tweet.InReplyToUserName != "" {
 			content += fmt.Sprintf("- InReplyTo: %s\n", tweet.InReplyToUserName)
 		}
-		content += fmt.Sprintf("- [View on Twitter](%s)\n", tweet.URL)
 		content += fmt.Sprintf("- %s\n", utils.FormatRelativeTime(tweet.CreatedAt))
 
+		buttonTitle := "View on Twitter"
+		if len(tweets) > 1 {
+			buttonTitle = fmt.Sprintf("View on Twitter #%d", i+1)
+		}
+		buttons = append(buttons, alter.Button{Title: buttonTitle, URL: tweet.URL})
+
 		if i < len(tweets)-1 {
 			content += "--- \n\n"
 		}
 	}
-	return content
+	return content, buttons
 }
@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"context"
 	"testing"
 
 	"github.com/ka1fe1/crypto-monitoring/internal/service"
@@ -40,12 +41,12 @@ func TestTwitterMonitorTask_Run(t *testing.T) {
 	}
 
 	qh := utils.QuietHoursParams{Enabled: true, StartHour: 11, EndHour: 12, Behavior: constant.QUIET_HOURS_BEHAVIOR_PAUSE}
-	task := NewTwitterMonitorTask(twitterSvc, bot, usernames, cfg.TwitterMonitor.Keywords, cfg.TwitterMonitor.WithinTime, cfg.TwitterMonitor.IntervalSeconds, qh)
+	task := NewTwitterMonitorTask(twitterSvc, bot, usernames, cfg.TwitterMonitor.Keywords, cfg.TwitterMonitor.WithinTime, nil, cfg.TwitterMonitor.IntervalSeconds, qh)
 
 	// Manually trigger run to test logic and notification
 	// First run initializes the lastTweetIDs map
 	logger.Info("Running first time to initialize...")
-	task.run()
+	task.Run(context.Background())
 
 	// time.Sleep(60 * time.Second)
 
@@ -5,10 +5,12 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/rand"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -19,11 +21,14 @@ const (
 	BaseURL = "wss://api.binance.com/sapi/wss"
 )
 
+var errNotConnected = errors.New("binance websocket is not connected")
+
 // CmsSubscriber handles WebSocket subscriptions for Binance announcements.
 type CmsSubscriber struct {
 	apiKey    string
 	apiSecret string
 	conn      *websocket.Conn
+	mu        sync.Mutex // guards conn, Close is called from the pinger and on shutdown
 	done      chan struct{}
 	proxyURL  string
 }
@@ -95,7 +100,10 @@ func (s *CmsSubscriber) Connect(topics []string) error {
 	if err != nil {
 		return err
 	}
+	s.mu.Lock()
 	s.conn = c
+	s.done = make(chan struct{})
+	s.mu.Unlock()
 
 	return nil
 }
@@ -117,9 +125,12 @@ func generateRandomString(n int) string {
 }
 
 func (s *CmsSubscriber) listen(handler func([]byte)) {
-	defer close(s.done)
+	s.mu.Lock()
+	conn, done := s.conn, s.done
+	s.mu.Unlock()
+	defer close(done)
 	for {
-		_, message, err := s.conn.ReadMessage()
+		_, message, err := conn.ReadMessage()
 		if err != nil {
 			logger.Error("read: %v", err)
 			return
@@ -145,7 +156,7 @@ func (s *CmsSubscriber) Subscribe(topics []string) error {
 		"command": "SUBSCRIBE",
 		"value":   topicStr,
 	}
-	return s.conn.WriteJSON(msg)
+	return s.writeJSON(msg)
 }
 
 // Unsubscribe sends an unsubscription message.
@@ -165,60 +176,93 @@ func (s *CmsSubscriber) Unsubscribe(topics []string) error {
 		"command": "UNSUBSCRIBE",
 		"value":   topicStr,
 	}
-	return s.conn.WriteJSON(msg)
+	return s.writeJSON(msg)
 }
 
 // Ping sends a ping message to the server.
 func (s *CmsSubscriber) Ping() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return errNotConnected
+	}
 	return s.conn.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(10*time.Second))
 }
 
+func (s *CmsSubscriber) writeJSON(msg interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return errNotConnected
+	}
+	return s.conn.WriteJSON(msg)
+}
+
+// Close sends a close frame and closes the connection.
 func (s *CmsSubscriber) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if s.conn != nil {
+		s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
 		s.conn.Close()
+		s.conn = nil
 	}
 }
 
-// Run maintains the connection and handles messages.
+// Run maintains the connection and handles messages until ctx is canceled,
+// then it closes the connection and returns.
 func (s *CmsSubscriber) Run(ctx context.Context, topics []string, handler func([]byte)) {
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			if err := s.Connect(topics); err != nil {
-				logger.Warn("Failed to connect: %v, retrying in 5s...", err)
-				time.Sleep(5 * time.Second)
-				continue
-			}
+	for ctx.Err() == nil {
+		if err := s.Connect(topics); err != nil {
+			logger.Warn("Failed to connect: %v, retrying in 5s...", err)
+			sleepCtx(ctx, 5*time.Second)
+			continue
+		}
+
+		s.mu.Lock()
+		done := s.done
+		s.mu.Unlock()
 
-			// Start Pinger
+		// Start Pinger, it also closes the connection on shutdown to unblock the read loop
+		go func() {
 			ticker := time.NewTicker(30 * time.Second)
-			go func() {
-				defer ticker.Stop()
-				for {
-					select {
-					case <-ctx.Done():
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					s.Close()
+					return
+				case <-done:
+					return
+				case <-ticker.C:
+					if err := s.Ping(); err != nil {
+						logger.Error("Ping failed: %v", err)
+						// Let the read loop see the closed connection and reconnect
+						s.Close()
 						return
-					case <-s.done:
-						return
-					case <-ticker.C:
-						if err := s.Ping(); err != nil {
-							logger.Error("Ping failed: %v", err)
-							// Trigger reconnect by closing connection if ping fails multiple times?
-							// For now, let read loop handle error if connection drops
-							s.Close()
-							return
-						}
 					}
 				}
-			}()
+			}
+		}()
 
-			logger.Info("Connected to Binance CEX")
-			s.listen(handler)
-			logger.Info("Connection lost, reconnecting...")
-			s.Close()
-			time.Sleep(1 * time.Second)
+		logger.Info("Connected to Binance CEX")
+		s.listen(handler)
+		s.Close()
+		if ctx.Err() != nil {
+			logger.Info("Binance CEX connection closed")
+			return
 		}
+		logger.Info("Connection lost, reconnecting...")
+		sleepCtx(ctx, time.Second)
+	}
+}
+
+// sleepCtx waits for d or until ctx is canceled.
+func sleepCtx(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
 	}
 }
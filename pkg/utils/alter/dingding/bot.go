@@ -12,10 +12,20 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/markdown"
 )
 
 const (
 	BaseURL = "https://oapi.dingtalk.com/robot/send"
+
+	// ERRCODE_RATE_LIMITED is returned when a robot sends more than 20 messages per minute.
+	ERRCODE_RATE_LIMITED = 410100
+
+	// MAX_MARKDOWN_BYTES is the largest markdown body DingTalk accepts, longer messages are split.
+	MAX_MARKDOWN_BYTES = 20000
 )
 
 type DingBot struct {
@@ -24,6 +34,8 @@ type DingBot struct {
 	Keyword string
 	BaseURL string
 	Client  *http.Client
+
+	queue *sendQueue // nil until StartQueue, messages are then sent synchronously
 }
 
 func NewDingBot(token, secret, keyword string) *DingBot {
@@ -36,6 +48,10 @@ func NewDingBot(token, secret, keyword string) *DingBot {
 	}
 }
 
+func (bot *DingBot) GetKeyword() string {
+	return bot.Keyword
+}
+
 type At struct {
 	AtMobiles []string `json:"atMobiles"`
 	IsAtAll   bool     `json:"isAtAll"`
@@ -58,11 +74,62 @@ type MarkdownMessage struct {
 	At At `json:"at"`
 }
 
+type ActionCardButton struct {
+	Title     string `json:"title"`
+	ActionURL string `json:"actionURL"`
+}
+
+// ActionCardMessage is a markdown card with a single button (SingleTitle/SingleURL) or several buttons (Btns).
+type ActionCardMessage struct {
+	MsgType    string `json:"msgtype"`
+	ActionCard struct {
+		Title          string             `json:"title"`
+		Text           string             `json:"text"`
+		BtnOrientation string             `json:"btnOrientation"` // "0" vertical, "1" horizontal
+		SingleTitle    string             `json:"singleTitle,omitempty"`
+		SingleURL      string             `json:"singleURL,omitempty"`
+		Btns           []ActionCardButton `json:"btns,omitempty"`
+	} `json:"actionCard"`
+}
+
+type FeedCardLink struct {
+	Title      string `json:"title"`
+	MessageURL string `json:"messageURL"`
+	PicURL     string `json:"picURL"`
+}
+
+// FeedCardMessage is a list of linked items, each with a title and an optional picture.
+type FeedCardMessage struct {
+	MsgType  string `json:"msgtype"`
+	FeedCard struct {
+		Links []FeedCardLink `json:"links"`
+	} `json:"feedCard"`
+}
+
 type Response struct {
 	ErrCode int    `json:"errcode"`
 	ErrMsg  string `json:"errmsg"`
 }
 
+// APIError is returned when DingTalk rejects a message or answers with a server error.
+type APIError struct {
+	StatusCode int
+	ErrCode    int
+	ErrMsg     string
+}
+
+func (e *APIError) Error() string {
+	if e.ErrCode == 0 {
+		return fmt.Sprintf("dingtalk http error: %d %s", e.StatusCode, e.ErrMsg)
+	}
+	return fmt.Sprintf("dingtalk api error: %s (code: %d)", e.ErrMsg, e.ErrCode)
+}
+
+// RateLimited reports whether the robot exceeded its 20 messages per minute.
+func (e *APIError) RateLimited() bool {
+	return e.ErrCode == ERRCODE_RATE_LIMITED
+}
+
 func (bot *DingBot) sign(t int64) string {
 	if bot.Secret == "" {
 		return ""
@@ -107,19 +174,92 @@ func (bot *DingBot) send(msg interface{}) error {
 		return fmt.Errorf("failed to read response body: %w", err)
 	}
 
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return &APIError{StatusCode: resp.StatusCode, ErrMsg: strings.TrimSpace(string(respBody))}
+	}
+
 	var dingResp Response
 	if err := json.Unmarshal(respBody, &dingResp); err != nil {
 		return fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
 	if dingResp.ErrCode != 0 {
-		return fmt.Errorf("dingtalk api error: %s (code: %d)", dingResp.ErrMsg, dingResp.ErrCode)
+		return &APIError{StatusCode: resp.StatusCode, ErrCode: dingResp.ErrCode, ErrMsg: dingResp.ErrMsg}
 	}
 
 	return nil
 }
 
 func (bot *DingBot) SendText(content string, atMobiles []string, isAtAll bool) error {
+	return bot.dispatch(bot.textMessage(content, atMobiles, isAtAll), PRIORITY_NORMAL)
+}
+
+func (bot *DingBot) SendMarkdown(title, text string, atMobiles []string, isAtAll bool) error {
+	return bot.SendMarkdownFrom(0, title, text, atMobiles, isAtAll)
+}
+
+// SendMarkdownFrom sends the parts of a split markdown message after the first skip ones.
+func (bot *DingBot) SendMarkdownFrom(skip int, title, text string, atMobiles []string, isAtAll bool) error {
+	return bot.sendMarkdownParts(skip, title, text, atMobiles, isAtAll, nil, PRIORITY_NORMAL)
+}
+
+// SendEvent sends the event as an ActionCard when it has buttons and as markdown otherwise.
+// Warning and critical events take the high priority lane of the send queue.
+func (bot *DingBot) SendEvent(e alter.Event) error {
+	return bot.SendEventFrom(0, e)
+}
+
+// SendEventFrom sends the parts of a split event after the first skip ones.
+func (bot *DingBot) SendEventFrom(skip int, e alter.Event) error {
+	priority := PRIORITY_NORMAL
+	if e.Severity == constant.SEVERITY_WARNING || e.Severity == constant.SEVERITY_CRITICAL {
+		priority = PRIORITY_HIGH
+	}
+
+	return bot.sendMarkdownParts(skip, e.Title, e.Markdown, nil, false, e.Buttons, priority)
+}
+
+// sendMarkdownParts sends text as markdown, or as an ActionCard when there are buttons.
+// Text above MAX_MARKDOWN_BYTES is split on section boundaries and sent in order as numbered
+// parts "(1/n)"; mentions go with the first part and buttons with the last one. The first skip
+// parts are not sent again, a failure after some parts returns an *alter.PartialSendError.
+func (bot *DingBot) sendMarkdownParts(skip int, title, text string, atMobiles []string, isAtAll bool, buttons []alter.Button, priority int) error {
+	// Leave room for the keyword and the part header added below.
+	budget := MAX_MARKDOWN_BYTES - len(bot.Keyword) - len(title) - 64
+	parts := markdown.Split(text, budget)
+
+	for i, part := range parts {
+		if i < skip {
+			continue
+		}
+		partTitle := title
+		if len(parts) > 1 {
+			partTitle = fmt.Sprintf("%s (%d/%d)", title, i+1, len(parts))
+			if i == 0 {
+				part = fmt.Sprintf("**(%d/%d)**\n\n%s", i+1, len(parts), part)
+			} else {
+				part = fmt.Sprintf("#### %s\n\n%s", partTitle, part)
+				atMobiles, isAtAll = nil, false
+			}
+		}
+
+		var msg interface{}
+		if i == len(parts)-1 && len(buttons) > 0 {
+			msg = bot.actionCardMessage(partTitle, part, buttons)
+		} else {
+			msg = bot.markdownMessage(partTitle, part, atMobiles, isAtAll)
+		}
+		if err := bot.dispatch(msg, priority); err != nil {
+			if len(parts) > 1 {
+				return &alter.PartialSendError{Sent: i, Total: len(parts), Err: err}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (bot *DingBot) textMessage(content string, atMobiles []string, isAtAll bool) TextMessage {
 	if bot.Keyword != "" {
 		content = fmt.Sprintf("[%s] %s", bot.Keyword, content)
 	}
@@ -131,10 +271,10 @@ func (bot *DingBot) SendText(content string, atMobiles []string, isAtAll bool) e
 		},
 	}
 	msg.Text.Content = content
-	return bot.send(msg)
+	return msg
 }
 
-func (bot *DingBot) SendMarkdown(title, text string, atMobiles []string, isAtAll bool) error {
+func (bot *DingBot) markdownMessage(title, text string, atMobiles []string, isAtAll bool) MarkdownMessage {
 	if bot.Keyword != "" {
 		if !strings.Contains(text, bot.Keyword) {
 			text = fmt.Sprintf("[%s]\n%s", bot.Keyword, text)
@@ -150,5 +290,47 @@ func (bot *DingBot) SendMarkdown(title, text string, atMobiles []string, isAtAll
 	}
 	msg.Markdown.Title = title
 	msg.Markdown.Text = text
-	return bot.send(msg)
+	return msg
+}
+
+// SendActionCard sends the markdown as an ActionCard. A single button fills the whole card footer,
+// several buttons are stacked vertically so long titles stay readable on mobile.
+func (bot *DingBot) SendActionCard(title, text string, buttons []alter.Button) error {
+	return bot.sendMarkdownParts(0, title, text, nil, false, buttons, PRIORITY_NORMAL)
+}
+
+func (bot *DingBot) actionCardMessage(title, text string, buttons []alter.Button) ActionCardMessage {
+	if bot.Keyword != "" {
+		if !strings.Contains(text, bot.Keyword) {
+			text = fmt.Sprintf("[%s]\n%s", bot.Keyword, text)
+		}
+	}
+
+	msg := ActionCardMessage{MsgType: "actionCard"}
+	msg.ActionCard.Title = title
+	msg.ActionCard.Text = text
+	msg.ActionCard.BtnOrientation = "0"
+	if len(buttons) == 1 {
+		msg.ActionCard.SingleTitle = buttons[0].Title
+		msg.ActionCard.SingleURL = buttons[0].URL
+	} else {
+		for _, b := range buttons {
+			msg.ActionCard.Btns = append(msg.ActionCard.Btns, ActionCardButton{Title: b.Title, ActionURL: b.URL})
+		}
+	}
+	return msg
+}
+
+// SendFeedCard sends a list of links. FeedCards carry no text, so the keyword is added to the first title.
+func (bot *DingBot) SendFeedCard(links []FeedCardLink) error {
+	if len(links) == 0 {
+		return fmt.Errorf("feed card requires at least one link")
+	}
+
+	msg := FeedCardMessage{MsgType: "feedCard"}
+	msg.FeedCard.Links = append(msg.FeedCard.Links, links...)
+	if bot.Keyword != "" && !strings.Contains(msg.FeedCard.Links[0].Title, bot.Keyword) {
+		msg.FeedCard.Links[0].Title = fmt.Sprintf("[%s] %s", bot.Keyword, msg.FeedCard.Links[0].Title)
+	}
+	return bot.dispatch(msg, PRIORITY_NORMAL)
 }
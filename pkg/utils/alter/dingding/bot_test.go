@@ -2,6 +2,7 @@ package dingding
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,10 +10,12 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/ka1fe1/crypto-monitoring/config"
 	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
 	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
 )
 
@@ -110,3 +113,160 @@ func TestSendMarkdown(t *testing.T) {
 		t.Fatalf("SendMarkdown failed: %v", err)
 	}
 }
+
+func TestSendActionCard(t *testing.T) {
+	var msg ActionCardMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &msg)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	cardBot := NewDingBot("token", "secret", "x")
+	cardBot.BaseURL = server.URL
+
+	// Single button
+	err := cardBot.SendActionCard("x New Tweets", "- hello", []alter.Button{{Title: "View on Twitter", URL: "https://x.com/a/status/1"}})
+	if err != nil {
+		t.Fatalf("SendActionCard failed: %v", err)
+	}
+	if msg.MsgType != "actionCard" || msg.ActionCard.SingleTitle != "View on Twitter" || len(msg.ActionCard.Btns) != 0 {
+		t.Errorf("unexpected single button card: %+v", msg)
+	}
+	if !strings.HasPrefix(msg.ActionCard.Text, "[x]") {
+		t.Errorf("expected keyword in card text, got %s", msg.ActionCard.Text)
+	}
+
+	// Multiple buttons
+	msg = ActionCardMessage{}
+	err = cardBot.SendActionCard("x New Tweets", "x - hello", []alter.Button{
+		{Title: "View on Twitter #1", URL: "https://x.com/a/status/1"},
+		{Title: "View on Twitter #2", URL: "https://x.com/a/status/2"},
+	})
+	if err != nil {
+		t.Fatalf("SendActionCard failed: %v", err)
+	}
+	if msg.ActionCard.SingleTitle != "" || len(msg.ActionCard.Btns) != 2 || msg.ActionCard.Btns[1].ActionURL != "https://x.com/a/status/2" {
+		t.Errorf("unexpected multi button card: %+v", msg)
+	}
+}
+
+func TestSendFeedCard(t *testing.T) {
+	var msg FeedCardMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &msg)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	feedBot := NewDingBot("token", "", "poly")
+	feedBot.BaseURL = server.URL
+
+	if err := feedBot.SendFeedCard(nil); err == nil {
+		t.Errorf("expected error for empty feed card")
+	}
+
+	err := feedBot.SendFeedCard([]FeedCardLink{
+		{Title: "Market A", MessageURL: "https://polymarket.com/market/a"},
+		{Title: "Market B", MessageURL: "https://polymarket.com/market/b"},
+	})
+	if err != nil {
+		t.Fatalf("SendFeedCard failed: %v", err)
+	}
+	if msg.MsgType != "feedCard" || len(msg.FeedCard.Links) != 2 || msg.FeedCard.Links[0].Title != "[poly] Market A" {
+		t.Errorf("unexpected feed card: %+v", msg)
+	}
+}
+
+func TestSendMarkdown_SplitsOversizedMessage(t *testing.T) {
+	var received []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if len(body) > MAX_MARKDOWN_BYTES+1024 {
+			t.Errorf("message too large: %d bytes", len(body))
+		}
+		var msg map[string]interface{}
+		json.Unmarshal(body, &msg)
+		received = append(received, msg)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	splitBot := NewDingBot("token", "", "x")
+	splitBot.BaseURL = server.URL
+
+	var tweets []string
+	for i := 0; i < 30; i++ {
+		tweets = append(tweets, "### Tweet\n- "+strings.Repeat("很长的推文内容", 80))
+	}
+	text := "## x New Tweets\n\n" + strings.Join(tweets, "\n---\n")
+
+	err := splitBot.SendActionCard("x New Tweets", text, []alter.Button{{Title: "View on Twitter", URL: "https://x.com/a/status/1"}})
+	if err != nil {
+		t.Fatalf("SendActionCard failed: %v", err)
+	}
+
+	if len(received) < 2 {
+		t.Fatalf("expected the message to be split, got %d messages", len(received))
+	}
+	n := len(received)
+	for i, msg := range received {
+		last := i == n-1
+		if last && msg["msgtype"] != "actionCard" || !last && msg["msgtype"] != "markdown" {
+			t.Errorf("unexpected msgtype %v for part %d/%d", msg["msgtype"], i+1, n)
+		}
+		var title string
+		if last {
+			title = msg["actionCard"].(map[string]interface{})["title"].(string)
+		} else {
+			title = msg["markdown"].(map[string]interface{})["title"].(string)
+		}
+		if expected := fmt.Sprintf("x New Tweets (%d/%d)", i+1, n); title != expected {
+			t.Errorf("expected title %q, got %q", expected, title)
+		}
+	}
+}
+
+func TestSendEventFrom_ResumesAfterPartialFailure(t *testing.T) {
+	var titles []string
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&msg)
+		w.Header().Set("Content-Type", "application/json")
+		// Fail the second part of the first attempt.
+		if requests++; requests == 2 {
+			w.Write([]byte(`{"errcode":310000,"errmsg":"keywords not in content"}`))
+			return
+		}
+		titles = append(titles, msg["markdown"]["title"].(string))
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	splitBot := NewDingBot("token", "", "x")
+	splitBot.BaseURL = server.URL
+	e := alter.NewEvent("", constant.SEVERITY_INFO, "Report", strings.Repeat("### Section\n- "+strings.Repeat("内容", 2000)+"\n", 6))
+
+	var partial *alter.PartialSendError
+	if err := splitBot.SendEvent(e); !errors.As(err, &partial) || partial.Sent != 1 || partial.Total < 3 {
+		t.Fatalf("expected a partial failure after the first part, got %v", err)
+	}
+	total := partial.Total
+
+	titles = titles[:0]
+	if err := splitBot.SendEventFrom(partial.Sent, e); err != nil {
+		t.Fatalf("SendEventFrom failed: %v", err)
+	}
+	if len(titles) != total-1 || titles[0] != fmt.Sprintf("Report (2/%d)", total) {
+		t.Errorf("expected parts 2..%d to be sent, got %v", total, titles)
+	}
+}
@@ -0,0 +1,288 @@
+package dingding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
+)
+
+const (
+	PRIORITY_NORMAL = iota // periodic reports
+	PRIORITY_HIGH          // alerts, sent ahead of queued reports
+
+	// DEFAULT_RATE_PER_MINUTE is the DingTalk robot limit, exceeding it returns ERRCODE_RATE_LIMITED.
+	DEFAULT_RATE_PER_MINUTE = 20
+	DEFAULT_QUEUE_SIZE      = 100
+
+	defaultMaxRetries = 3
+	defaultRetryDelay = 5 * time.Second
+	// DingTalk throttles a robot for a while after 410100, so back off longer than for server errors.
+	rateLimitedRetryDelay = time.Minute
+)
+
+var errQueueAborted = errors.New("dingtalk send queue aborted before delivery")
+
+type queuedMessage struct {
+	msg      interface{}
+	priority int
+	result   chan error // receives the final delivery result
+}
+
+// sendQueue paces messages evenly to the rate limit and sends alerts ahead of reports.
+// Messages rejected with ERRCODE_RATE_LIMITED or a 5xx response are retried with backoff.
+type sendQueue struct {
+	post   func(msg interface{}) error
+	bucket *tokenBucket
+	high   chan queuedMessage
+	normal chan queuedMessage
+
+	maxRetries        int
+	async             bool // senders do not wait for the result, failures are logged here
+	retryDelay        time.Duration
+	rateLimitedDelay  time.Duration
+	stop, abort, done chan struct{}
+	mu                sync.RWMutex // serializes enqueue against shutdown so nothing lands after the final drain
+	stopped           bool
+	abortOnce         sync.Once
+	name              string
+}
+
+func newSendQueue(name string, post func(msg interface{}) error, ratePerMinute, size int) *sendQueue {
+	if ratePerMinute <= 0 {
+		ratePerMinute = DEFAULT_RATE_PER_MINUTE
+	}
+	if size <= 0 {
+		size = DEFAULT_QUEUE_SIZE
+	}
+	return &sendQueue{
+		post: post,
+		// A capacity of one spaces the sends evenly, a full bucket after an idle minute would
+		// send a minute's quota at once and exceed the limit within the next 60s.
+		bucket:           newTokenBucket(1, time.Minute/time.Duration(ratePerMinute)),
+		high:             make(chan queuedMessage, size),
+		normal:           make(chan queuedMessage, size),
+		maxRetries:       defaultMaxRetries,
+		retryDelay:       defaultRetryDelay,
+		rateLimitedDelay: rateLimitedRetryDelay,
+		stop:             make(chan struct{}),
+		abort:            make(chan struct{}),
+		done:             make(chan struct{}),
+		name:             name,
+	}
+}
+
+func (q *sendQueue) start() {
+	go func() {
+		defer close(q.done)
+		for {
+			m, ok := q.next()
+			if !ok {
+				return
+			}
+			err := q.deliver(m)
+			if err != nil && q.async {
+				logger.Error("Failed to send DingTalk message for %s: %v", q.name, err)
+			}
+			m.result <- err
+		}
+	}()
+}
+
+// enqueue adds msg to its lane and returns a channel that receives the delivery result.
+func (q *sendQueue) enqueue(msg interface{}, priority int) (<-chan error, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.stopped {
+		return nil, fmt.Errorf("dingtalk send queue for %s is stopped", q.name)
+	}
+
+	lane := q.normal
+	if priority == PRIORITY_HIGH {
+		lane = q.high
+	}
+	m := queuedMessage{msg: msg, priority: priority, result: make(chan error, 1)}
+	select {
+	case lane <- m:
+		return m.result, nil
+	default:
+		return nil, fmt.Errorf("dingtalk send queue for %s is full", q.name)
+	}
+}
+
+// next returns the next message, always preferring the high priority lane.
+// After stop it keeps draining until both lanes are empty.
+func (q *sendQueue) next() (queuedMessage, bool) {
+	select {
+	case m := <-q.high:
+		return m, true
+	default:
+	}
+
+	select {
+	case m := <-q.high:
+		return m, true
+	case m := <-q.normal:
+		return m, true
+	case <-q.stop:
+		select {
+		case m := <-q.high:
+			return m, true
+		default:
+		}
+		select {
+		case m := <-q.normal:
+			return m, true
+		default:
+		}
+		return queuedMessage{}, false
+	}
+}
+
+func (q *sendQueue) deliver(m queuedMessage) error {
+	for attempt := 0; ; attempt++ {
+		if !q.bucket.wait(q.abort) {
+			return errQueueAborted
+		}
+
+		err := q.post(m.msg)
+		if err == nil {
+			return nil
+		}
+
+		var apiErr *APIError
+		retryable := errors.As(err, &apiErr) && (apiErr.RateLimited() || apiErr.StatusCode >= 500)
+		if !retryable || attempt >= q.maxRetries {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt+1, err)
+		}
+
+		delay := q.retryDelay << attempt
+		if apiErr.RateLimited() && delay < q.rateLimitedDelay {
+			delay = q.rateLimitedDelay
+		}
+		logger.Warn("DingTalk send for %s failed (%v), retrying in %s", q.name, err, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-q.abort:
+			timer.Stop()
+			return errQueueAborted
+		}
+	}
+}
+
+// shutdown stops accepting messages and waits for the queued ones to be sent.
+// When ctx ends first the remaining messages are dropped.
+func (q *sendQueue) shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.stopped {
+		q.stopped = true
+		close(q.stop)
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		q.abortOnce.Do(func() { close(q.abort) })
+		<-q.done
+		return ctx.Err()
+	}
+}
+
+// tokenBucket allows bursts of up to capacity messages and refills one token per interval.
+type tokenBucket struct {
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	interval time.Duration
+	last     time.Time
+}
+
+func newTokenBucket(capacity int, interval time.Duration) *tokenBucket {
+	return &tokenBucket{
+		capacity: float64(capacity),
+		tokens:   float64(capacity),
+		interval: interval,
+		last:     time.Now(),
+	}
+}
+
+// reserve takes a token if one is available, otherwise it returns how long to wait for the next one.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens += float64(now.Sub(b.last)) / float64(b.interval)
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) * float64(b.interval))
+}
+
+// wait blocks until a token is available. It returns false if abort is closed first.
+func (b *tokenBucket) wait(abort <-chan struct{}) bool {
+	for {
+		d := b.reserve(time.Now())
+		if d == 0 {
+			return true
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-abort:
+			timer.Stop()
+			return false
+		}
+	}
+}
+
+// StartQueue routes every send through the queue: messages are paced to ratePerMinute and
+// alerts are sent ahead of reports. On its own the queue is asynchronous: send methods return
+// once the message is queued, rate-limited or 5xx responses are retried and the final error is
+// logged. behindOutbox is set when an outbox delivers through the bot, which retries itself and
+// records the outcome, so send methods then wait for the result of a single attempt.
+func (bot *DingBot) StartQueue(ratePerMinute, size int, behindOutbox bool) {
+	if bot.queue != nil {
+		return
+	}
+	bot.queue = newSendQueue(bot.Keyword, bot.send, ratePerMinute, size)
+	if behindOutbox {
+		bot.queue.maxRetries = 0
+	} else {
+		bot.queue.async = true
+	}
+	bot.queue.start()
+	logger.Info("Started DingTalk send queue for %s", bot.Keyword)
+}
+
+// StopQueue stops accepting messages and waits for the queued ones to be sent.
+// Messages still queued when ctx ends are dropped.
+func (bot *DingBot) StopQueue(ctx context.Context) error {
+	if bot.queue == nil {
+		return nil
+	}
+	return bot.queue.shutdown(ctx)
+}
+
+func (bot *DingBot) dispatch(msg interface{}, priority int) error {
+	if bot.queue == nil {
+		return bot.send(msg)
+	}
+	result, err := bot.queue.enqueue(msg, priority)
+	if err != nil || bot.queue.async {
+		return err
+	}
+	return <-result
+}
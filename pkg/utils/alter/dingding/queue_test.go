@@ -0,0 +1,193 @@
+package dingding
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket_Reserve(t *testing.T) {
+	b := newTokenBucket(2, time.Second)
+	now := b.last
+
+	if b.reserve(now) != 0 || b.reserve(now) != 0 {
+		t.Fatalf("expected burst of 2 to pass")
+	}
+	if d := b.reserve(now); d != time.Second {
+		t.Errorf("expected to wait 1s for the next token, got %s", d)
+	}
+	if d := b.reserve(now.Add(500 * time.Millisecond)); d != 500*time.Millisecond {
+		t.Errorf("expected to wait 500ms after partial refill, got %s", d)
+	}
+	if d := b.reserve(now.Add(time.Second)); d != 0 {
+		t.Errorf("expected a token after a full interval, got wait %s", d)
+	}
+}
+
+func TestSendQueue_RateWindow(t *testing.T) {
+	const rate = 20
+	q := newSendQueue("test", nil, rate, 10)
+
+	// Start after an idle stretch and send as fast as the bucket allows for five minutes.
+	now := q.bucket.last.Add(2 * time.Minute)
+	end := now.Add(5 * time.Minute)
+	var sent []time.Time
+	for now.Before(end) {
+		if d := q.bucket.reserve(now); d > 0 {
+			now = now.Add(d)
+			continue
+		}
+		sent = append(sent, now)
+	}
+
+	for i, start := range sent {
+		n := 0
+		for _, at := range sent[i:] {
+			if at.Sub(start) < time.Minute {
+				n++
+			}
+		}
+		if n > rate {
+			t.Fatalf("%d sends in the minute from %s, limit is %d", n, start.Sub(sent[0]), rate)
+		}
+	}
+	if len(sent) < 5*rate {
+		t.Errorf("expected about %d sends in five minutes, got %d", 5*rate, len(sent))
+	}
+}
+
+func TestSendQueue_PriorityAndRetry(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	failures := map[string]int{"alert": 1}
+
+	post := func(msg interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		name := msg.(string)
+		if failures[name] > 0 {
+			failures[name]--
+			return &APIError{StatusCode: 200, ErrCode: ERRCODE_RATE_LIMITED, ErrMsg: "send too fast"}
+		}
+		sent = append(sent, name)
+		return nil
+	}
+
+	q := newSendQueue("test", post, 600, 10)
+	q.retryDelay = time.Millisecond
+	q.rateLimitedDelay = 10 * time.Millisecond
+
+	// Queue before starting so the worker sees both lanes filled.
+	q.enqueue("report-1", PRIORITY_NORMAL)
+	q.enqueue("report-2", PRIORITY_NORMAL)
+	q.enqueue("alert", PRIORITY_HIGH)
+	q.start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := q.shutdown(ctx); err != nil {
+		t.Fatalf("shutdown failed: %v", err)
+	}
+
+	expected := []string{"alert", "report-1", "report-2"}
+	if len(sent) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, sent)
+	}
+	for i := range expected {
+		if sent[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, sent)
+		}
+	}
+
+	if _, err := q.enqueue("late", PRIORITY_NORMAL); err == nil {
+		t.Errorf("expected enqueue after shutdown to fail")
+	}
+}
+
+func TestSendQueue_NoRetryOnClientError(t *testing.T) {
+	calls := 0
+	post := func(msg interface{}) error {
+		calls++
+		return &APIError{StatusCode: 200, ErrCode: 310000, ErrMsg: "keywords not in content"}
+	}
+
+	q := newSendQueue("test", post, 600, 10)
+	q.retryDelay = time.Millisecond
+	result, _ := q.enqueue("msg", PRIORITY_NORMAL)
+	q.start()
+	q.shutdown(context.Background())
+
+	if calls != 1 {
+		t.Errorf("expected a single attempt for a non-retryable error, got %d", calls)
+	}
+	var apiErr *APIError
+	if err := <-result; !errors.As(err, &apiErr) || apiErr.ErrCode != 310000 {
+		t.Errorf("expected the api error as delivery result, got %v", err)
+	}
+}
+
+func TestSendQueue_ShutdownTimeout(t *testing.T) {
+	post := func(msg interface{}) error {
+		return &APIError{StatusCode: 502, ErrMsg: "bad gateway"}
+	}
+
+	q := newSendQueue("test", post, 600, 10)
+	q.retryDelay = time.Hour
+	result, _ := q.enqueue("msg", PRIORITY_NORMAL)
+	q.start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := q.shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if err := <-result; !errors.Is(err, errQueueAborted) {
+		t.Errorf("expected aborted delivery result, got %v", err)
+	}
+}
+
+func TestStartQueue_AsyncUnlessBehindOutbox(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	bot := NewDingBot("token", "secret", "x")
+	bot.BaseURL = server.URL
+	bot.StartQueue(600, 10, false)
+
+	sent := make(chan error, 1)
+	go func() { sent <- bot.SendText("x hello", nil, false) }()
+	select {
+	case err := <-sent:
+		if err != nil {
+			t.Fatalf("SendText failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected SendText to return once the message is queued")
+	}
+
+	outboxBot := NewDingBot("token", "secret", "x")
+	outboxBot.BaseURL = server.URL
+	outboxBot.StartQueue(600, 10, true)
+	go func() { sent <- outboxBot.SendText("x hello", nil, false) }()
+	select {
+	case <-sent:
+		t.Fatalf("expected SendText behind an outbox to wait for the delivery")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-sent; err != nil {
+		t.Errorf("SendText behind an outbox failed: %v", err)
+	}
+	bot.StopQueue(context.Background())
+	outboxBot.StopQueue(context.Background())
+}
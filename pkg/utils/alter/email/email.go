@@ -0,0 +1,280 @@
+package email
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/markdown"
+)
+
+const (
+	// maxKeptEntries bounds the messages kept for the next digest after a failed send.
+	maxKeptEntries = 500
+	// maxKeptEntryAge drops kept messages that are too old to be worth sending.
+	maxKeptEntryAge = 3 * 24 * time.Hour
+)
+
+// EmailBot collects messages from the tasks and sends them as one HTML digest per hour or per day.
+type EmailBot struct {
+	Host      string
+	Port      int
+	Username  string
+	Password  string
+	From      string
+	To        []string
+	Keyword   string
+	Digest    string // "hourly" or "daily"
+	DailyHour int    // hour (UTC+8) at which the daily digest is sent
+
+	mu      sync.Mutex
+	entries []digestEntry
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+type digestEntry struct {
+	title    string
+	markdown string
+	text     string
+	time     time.Time
+}
+
+func NewEmailBot(host string, port int, username, password, from string, to []string, keyword, digest string, dailyHour int) *EmailBot {
+	if digest != constant.EMAIL_DIGEST_DAILY {
+		digest = constant.EMAIL_DIGEST_HOURLY
+	}
+	return &EmailBot{
+		Host:      host,
+		Port:      port,
+		Username:  username,
+		Password:  password,
+		From:      from,
+		To:        to,
+		Keyword:   keyword,
+		Digest:    digest,
+		DailyHour: dailyHour,
+	}
+}
+
+func (bot *EmailBot) GetKeyword() string {
+	return bot.Keyword
+}
+
+// SendText queues a text message for the next digest.
+func (bot *EmailBot) SendText(content string, mentions []string, mentionAll bool) error {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	bot.entries = append(bot.entries, digestEntry{text: content, time: time.Now()})
+	return nil
+}
+
+// SendMarkdown queues a markdown message for the next digest.
+func (bot *EmailBot) SendMarkdown(title, text string, mentions []string, mentionAll bool) error {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	bot.entries = append(bot.entries, digestEntry{title: title, markdown: text, time: time.Now()})
+	return nil
+}
+
+// Start runs the digest loop until Stop is called.
+func (bot *EmailBot) Start() {
+	bot.stop = make(chan struct{})
+	bot.done = make(chan struct{})
+	logger.Info("Starting %s email digest for %s", bot.Digest, bot.Keyword)
+
+	go func() {
+		defer close(bot.done)
+		for {
+			timer := time.NewTimer(time.Until(bot.nextDigestTime(time.Now())))
+			select {
+			case <-timer.C:
+				if err := bot.Flush(); err != nil {
+					logger.Error("Error sending email digest for %s: %v", bot.Keyword, err)
+				}
+			case <-bot.stop:
+				timer.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the digest loop and sends whatever is still queued.
+func (bot *EmailBot) Stop() error {
+	if bot.stop != nil {
+		close(bot.stop)
+		<-bot.done
+		bot.stop = nil
+	}
+	return bot.Flush()
+}
+
+// Flush sends the queued messages as one digest. Messages are kept if sending fails, up to
+// maxKeptEntries and maxKeptEntryAge.
+func (bot *EmailBot) Flush() error {
+	bot.mu.Lock()
+	entries := bot.entries
+	bot.entries = nil
+	bot.mu.Unlock()
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if err := bot.sendMail(bot.buildMessage(entries, time.Now())); err != nil {
+		bot.mu.Lock()
+		bot.entries = bot.keep(entries, bot.entries, time.Now())
+		bot.mu.Unlock()
+		return err
+	}
+
+	logger.Info("Sent email digest for %s with %d messages", bot.Keyword, len(entries))
+	return nil
+}
+
+// keep puts the entries of a failed digest back ahead of those queued since. Entries older than
+// maxKeptEntryAge are dropped, then the oldest beyond maxKeptEntries, so a mail server that stays
+// down does not grow the queue forever.
+func (bot *EmailBot) keep(failed, queued []digestEntry, now time.Time) []digestEntry {
+	entries := make([]digestEntry, 0, len(failed)+len(queued))
+	expired := 0
+	for _, e := range append(failed, queued...) {
+		if now.Sub(e.time) > maxKeptEntryAge {
+			expired++
+			continue
+		}
+		entries = append(entries, e)
+	}
+	overflow := max(len(entries)-maxKeptEntries, 0)
+	entries = entries[overflow:]
+
+	if expired > 0 || overflow > 0 {
+		logger.Warn("Dropped %d messages older than %s and %d over the limit of %d from the email digest for %s",
+			expired, maxKeptEntryAge, overflow, maxKeptEntries, bot.Keyword)
+	}
+	return entries
+}
+
+// nextDigestTime returns the next top of the hour, or the next DailyHour:00 in UTC+8 for daily digests.
+func (bot *EmailBot) nextDigestTime(now time.Time) time.Time {
+	location := time.FixedZone("CST", 8*3600)
+	nowBJ := now.In(location)
+
+	if bot.Digest == constant.EMAIL_DIGEST_DAILY {
+		next := time.Date(nowBJ.Year(), nowBJ.Month(), nowBJ.Day(), bot.DailyHour, 0, 0, 0, location)
+		if !next.After(nowBJ) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	}
+	return nowBJ.Truncate(time.Hour).Add(time.Hour)
+}
+
+func (bot *EmailBot) buildMessage(entries []digestEntry, now time.Time) []byte {
+	location := time.FixedZone("CST", 8*3600)
+	period := "Hourly"
+	if bot.Digest == constant.EMAIL_DIGEST_DAILY {
+		period = "Daily"
+	}
+	subject := fmt.Sprintf("[%s] %s Digest - %s (%d messages)", bot.Keyword, period, now.In(location).Format("2006-01-02 15:04"), len(entries))
+
+	var body strings.Builder
+	body.WriteString(`<html><body style="font-family:-apple-system,Helvetica,Arial,sans-serif;font-size:14px;color:#222">`)
+	body.WriteString(fmt.Sprintf("<h2>%s</h2>\n", html.EscapeString(subject)))
+	for i, e := range entries {
+		if i > 0 {
+			body.WriteString(`<hr style="border:none;border-top:2px solid #eee;margin:24px 0"/>`)
+		}
+		if e.title != "" {
+			body.WriteString(fmt.Sprintf("<h3>%s</h3>\n", html.EscapeString(e.title)))
+		}
+		body.WriteString(fmt.Sprintf(`<p style="color:#888;font-size:12px">%s</p>`, e.time.In(location).Format("2006-01-02 15:04:05")))
+		if e.markdown != "" {
+			body.WriteString(markdown.ToHTML(e.markdown))
+		} else {
+			body.WriteString("<pre>" + html.EscapeString(e.text) + "</pre>")
+		}
+	}
+	body.WriteString("</body></html>")
+
+	var msg bytes.Buffer
+	msg.WriteString(fmt.Sprintf("From: %s\r\n", bot.From))
+	msg.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(bot.To, ", ")))
+	msg.WriteString(fmt.Sprintf("Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject)))
+	msg.WriteString(fmt.Sprintf("Date: %s\r\n", now.Format(time.RFC1123Z)))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(body.String()))
+	for len(encoded) > 76 {
+		msg.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	msg.WriteString(encoded + "\r\n")
+
+	return msg.Bytes()
+}
+
+func (bot *EmailBot) sendMail(msg []byte) error {
+	addr := net.JoinHostPort(bot.Host, fmt.Sprintf("%d", bot.Port))
+
+	var auth smtp.Auth
+	if bot.Username != "" {
+		auth = smtp.PlainAuth("", bot.Username, bot.Password, bot.Host)
+	}
+
+	// Port 465 uses implicit TLS, everything else goes through smtp.SendMail (STARTTLS when offered).
+	if bot.Port != 465 {
+		if err := smtp.SendMail(addr, auth, bot.From, bot.To, msg); err != nil {
+			return fmt.Errorf("failed to send mail: %w", err)
+		}
+		return nil
+	}
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: bot.Host})
+	if err != nil {
+		return fmt.Errorf("failed to dial smtp server: %w", err)
+	}
+	client, err := smtp.NewClient(conn, bot.Host)
+	if err != nil {
+		return fmt.Errorf("failed to create smtp client: %w", err)
+	}
+	defer client.Close()
+
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("smtp auth failed: %w", err)
+		}
+	}
+	if err := client.Mail(bot.From); err != nil {
+		return fmt.Errorf("smtp MAIL FROM failed: %w", err)
+	}
+	for _, to := range bot.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("smtp RCPT TO %s failed: %w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("failed to write mail body: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to finish mail body: %w", err)
+	}
+	return client.Quit()
+}
@@ -0,0 +1,170 @@
+package email
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+// startSMTPSink runs a minimal local SMTP server and returns every DATA payload it receives.
+func startSMTPSink(t *testing.T) (string, int, <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	mails := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				write := func(s string) { conn.Write([]byte(s + "\r\n")) }
+				write("220 localhost ESMTP sink")
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					cmd := strings.ToUpper(strings.TrimSpace(line))
+					switch {
+					case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+						write("250 localhost")
+					case strings.HasPrefix(cmd, "DATA"):
+						write("354 end with <CRLF>.<CRLF>")
+						var data strings.Builder
+						for {
+							l, err := r.ReadString('\n')
+							if err != nil {
+								return
+							}
+							if l == ".\r\n" {
+								break
+							}
+							data.WriteString(l)
+						}
+						mails <- data.String()
+						write("250 OK")
+					case strings.HasPrefix(cmd, "QUIT"):
+						write("221 bye")
+						return
+					default:
+						write("250 OK")
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	host, portStr, _ := net.SplitHostPort(ln.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	return host, port, mails
+}
+
+func decodeBody(t *testing.T, mail string) string {
+	parts := strings.SplitN(mail, "\r\n\r\n", 2)
+	if len(parts) != 2 {
+		t.Fatalf("malformed mail: %s", mail)
+	}
+	body, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(parts[1], "\r\n", ""))
+	if err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	return string(body)
+}
+
+func TestFlush_SendsDigest(t *testing.T) {
+	host, port, mails := startSMTPSink(t)
+	bot := NewEmailBot(host, port, "", "", "monitor@example.com", []string{"boss@example.com"}, "token", constant.EMAIL_DIGEST_DAILY, 9)
+
+	bot.SendMarkdown("token Price Alerts", "### Crypto Assets\n- **BTC**: ***$60000.00*** (0.50%)", nil, false)
+	bot.SendText("plain <text>", nil, false)
+
+	if err := bot.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	select {
+	case mail := <-mails:
+		if !strings.Contains(mail, "Content-Type: text/html; charset=UTF-8") {
+			t.Errorf("expected html mail, got: %s", mail)
+		}
+		body := decodeBody(t, mail)
+		for _, e := range []string{"<h3>token Price Alerts</h3>", ">BTC</th>", "<pre>plain &lt;text&gt;</pre>", "(2 messages)"} {
+			if !strings.Contains(body, e) {
+				t.Errorf("expected %q in body, got: %s", e, body)
+			}
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("no mail received")
+	}
+
+	// Queue is empty after a successful flush.
+	if err := bot.Flush(); err != nil {
+		t.Fatalf("empty Flush failed: %v", err)
+	}
+	select {
+	case <-mails:
+		t.Errorf("expected no mail for an empty digest")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestFlush_KeepsEntriesOnFailure(t *testing.T) {
+	// Nothing listens on this port.
+	bot := NewEmailBot("127.0.0.1", 1, "", "", "monitor@example.com", []string{"boss@example.com"}, "token", constant.EMAIL_DIGEST_HOURLY, 0)
+	bot.SendMarkdown("title", "text", nil, false)
+
+	if err := bot.Flush(); err == nil {
+		t.Fatalf("expected Flush to fail")
+	}
+	if len(bot.entries) != 1 {
+		t.Errorf("expected entry to be kept for the next digest, got %d", len(bot.entries))
+	}
+}
+
+func TestKeep_CapsKeptEntries(t *testing.T) {
+	bot := NewEmailBot("127.0.0.1", 1, "", "", "monitor@example.com", []string{"boss@example.com"}, "token", constant.EMAIL_DIGEST_HOURLY, 0)
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	failed := []digestEntry{{text: "stale", time: now.Add(-maxKeptEntryAge - time.Minute)}}
+	for i := 0; i < maxKeptEntries; i++ {
+		failed = append(failed, digestEntry{text: fmt.Sprintf("failed %d", i), time: now.Add(-time.Hour)})
+	}
+	queued := []digestEntry{{text: "new", time: now}}
+
+	kept := bot.keep(failed, queued, now)
+	if len(kept) != maxKeptEntries {
+		t.Fatalf("expected %d kept entries, got %d", maxKeptEntries, len(kept))
+	}
+	if kept[0].text != "failed 1" || kept[len(kept)-1].text != "new" {
+		t.Errorf("expected the stale and the oldest entry to be dropped, got %q ... %q", kept[0].text, kept[len(kept)-1].text)
+	}
+}
+
+func TestNextDigestTime(t *testing.T) {
+	location := time.FixedZone("CST", 8*3600)
+	now := time.Date(2026, 3, 2, 10, 15, 0, 0, location)
+
+	hourly := NewEmailBot("", 0, "", "", "", nil, "", constant.EMAIL_DIGEST_HOURLY, 0)
+	if got := hourly.nextDigestTime(now); !got.Equal(time.Date(2026, 3, 2, 11, 0, 0, 0, location)) {
+		t.Errorf("unexpected hourly digest time: %v", got)
+	}
+
+	daily := NewEmailBot("", 0, "", "", "", nil, "", constant.EMAIL_DIGEST_DAILY, 9)
+	if got := daily.nextDigestTime(now); !got.Equal(time.Date(2026, 3, 3, 9, 0, 0, 0, location)) {
+		t.Errorf("unexpected daily digest time: %v", got)
+	}
+}
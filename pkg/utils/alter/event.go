@@ -0,0 +1,107 @@
+package alter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var fieldRegex = regexp.MustCompile(`^-\s+\*\*(.+?)\*\*\s*[:：]\s*(.*)$`)
+
+// Event is the structured form of a task notification. Channels that only understand
+// markdown receive Title and Markdown, structured channels (webhooks, ...) get everything.
+type Event struct {
+	Bot       string    `json:"bot"`
+	Task      string    `json:"task"`
+	Severity  string    `json:"severity"` // constant.SEVERITY_*
+	Title     string    `json:"title"`
+	Fields    []Field   `json:"fields"`
+	Markdown  string    `json:"markdown"`
+	Buttons   []Button  `json:"buttons,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Button is a link rendered as a tappable button by channels that support cards.
+type Button struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// EventNotifier is implemented by channels that consume the structured event.
+type EventNotifier interface {
+	Notifier
+	SendEvent(e Event) error
+}
+
+// ActionCardSender is implemented by channels that can send markdown with buttons (DingTalk ActionCard).
+type ActionCardSender interface {
+	Notifier
+	SendActionCard(title, text string, buttons []Button) error
+}
+
+// NewEvent builds an event from a task's markdown message, extracting "- **Key**: value" lines as fields.
+func NewEvent(task, severity, title, markdown string) Event {
+	return Event{
+		Task:      task,
+		Severity:  severity,
+		Title:     title,
+		Fields:    ParseFields(markdown),
+		Markdown:  markdown,
+		Timestamp: time.Now(),
+	}
+}
+
+// ParseFields extracts the "- **Key**: value" lines of a markdown message, with emphasis markers removed.
+func ParseFields(markdown string) []Field {
+	var fields []Field
+	for _, line := range strings.Split(markdown, "\n") {
+		m := fieldRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		fields = append(fields, Field{
+			Name:  strings.TrimSpace(m[1]),
+			Value: strings.TrimSpace(strings.ReplaceAll(m[2], "*", "")),
+		})
+	}
+	return fields
+}
+
+// WithButtons attaches buttons to the event.
+func (e Event) WithButtons(buttons ...Button) Event {
+	e.Buttons = append(e.Buttons, buttons...)
+	return e
+}
+
+// MarkdownWithLinks returns the markdown with the buttons appended as link lines,
+// for channels that cannot render buttons.
+func (e Event) MarkdownWithLinks() string {
+	if len(e.Buttons) == 0 {
+		return e.Markdown
+	}
+	var sb strings.Builder
+	sb.WriteString(e.Markdown)
+	sb.WriteString("\n")
+	for _, b := range e.Buttons {
+		sb.WriteString(fmt.Sprintf("\n- [%s](%s)", b.Title, b.URL))
+	}
+	return sb.String()
+}
+
+// Notify delivers e through n in the richest form n supports: a structured event,
+// an action card when the event has buttons, and plain markdown otherwise.
+func Notify(n Notifier, e Event) error {
+	if en, ok := n.(EventNotifier); ok {
+		return en.SendEvent(e)
+	}
+	if cs, ok := n.(ActionCardSender); ok && len(e.Buttons) > 0 {
+		return cs.SendActionCard(e.Title, e.Markdown, e.Buttons)
+	}
+	return n.SendMarkdown(e.Title, e.MarkdownWithLinks(), nil, false)
+}
@@ -0,0 +1,86 @@
+package alter
+
+import "testing"
+
+type recordEventNotifier struct {
+	recordNotifier
+	events []Event
+}
+
+func (r *recordEventNotifier) SendEvent(e Event) error {
+	r.events = append(r.events, e)
+	return nil
+}
+
+func TestNewEvent_ParsesFields(t *testing.T) {
+	e := NewEvent("TokenPriceMonitorTask", "info", "token Price Alerts",
+		"#### token Price Alerts\n\n- **BTC**: ***$60000.00*** (0.50%)\n  - detail\n- **ETH**：$3000.00\n---\n**Last Updated**: now")
+
+	if len(e.Fields) != 2 {
+		t.Fatalf("expected 2 fields, got %+v", e.Fields)
+	}
+	if e.Fields[0].Name != "BTC" || e.Fields[0].Value != "$60000.00 (0.50%)" {
+		t.Errorf("unexpected field: %+v", e.Fields[0])
+	}
+	if e.Fields[1].Name != "ETH" || e.Fields[1].Value != "$3000.00" {
+		t.Errorf("unexpected field: %+v", e.Fields[1])
+	}
+	if e.Timestamp.IsZero() {
+		t.Errorf("expected timestamp to be set")
+	}
+}
+
+type recordCardNotifier struct {
+	recordNotifier
+	buttons []Button
+}
+
+func (r *recordCardNotifier) SendActionCard(title, text string, buttons []Button) error {
+	r.buttons = append(r.buttons, buttons...)
+	return nil
+}
+
+func TestNotify_Buttons(t *testing.T) {
+	var markdown string
+	plain := &markdownNotifier{onSend: func(text string) { markdown = text }}
+	card := &recordCardNotifier{recordNotifier: recordNotifier{keyword: "x"}}
+
+	e := NewEvent("TwitterMonitorTask", "info", "x New Tweets", "- hello").
+		WithButtons(Button{Title: "View on Twitter", URL: "https://x.com/a/status/1"})
+	if err := Notify(NewMultiNotifier("x", plain, card), e); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	if len(card.buttons) != 1 || len(card.sent) != 0 {
+		t.Errorf("expected an action card, got buttons=%d sent=%d", len(card.buttons), len(card.sent))
+	}
+	if markdown != "- hello\n\n- [View on Twitter](https://x.com/a/status/1)" {
+		t.Errorf("expected buttons to fall back to links, got %q", markdown)
+	}
+}
+
+type markdownNotifier struct {
+	recordNotifier
+	onSend func(text string)
+}
+
+func (m *markdownNotifier) SendMarkdown(title, text string, mentions []string, mentionAll bool) error {
+	m.onSend(text)
+	return nil
+}
+
+func TestNotify(t *testing.T) {
+	plain := &recordNotifier{keyword: "token"}
+	structured := &recordEventNotifier{recordNotifier: recordNotifier{keyword: "token"}}
+	multi := NewMultiNotifier("token", plain, structured)
+
+	if err := Notify(multi, NewEvent("task", "info", "title", "text")); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if len(plain.sent) != 1 || plain.sent[0] != "title" {
+		t.Errorf("expected markdown fallback for plain notifier, got %v", plain.sent)
+	}
+	if len(structured.events) != 1 || len(structured.sent) != 0 {
+		t.Errorf("expected structured event only, got events=%d sent=%d", len(structured.events), len(structured.sent))
+	}
+}
@@ -0,0 +1,256 @@
+package feishu
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// BaseURL is the Feishu custom bot webhook prefix, use https://open.larksuite.com/open-apis/bot/v2/hook for Lark.
+	BaseURL = "https://open.feishu.cn/open-apis/bot/v2/hook"
+
+	defaultTemplate = "blue"
+)
+
+var (
+	headingRegex = regexp.MustCompile(`^#{1,6}\s+(.*)$`)
+	fieldRegex   = regexp.MustCompile(`^-\s+\*\*(.+?)\*\*\s*[:：]\s*(.*)$`)
+	buttonRegex  = regexp.MustCompile(`^-?\s*\[([^\]]+)\]\(([^)\s]+)\)\s*$`)
+)
+
+type FeishuBot struct {
+	Token   string
+	Secret  string
+	Keyword string
+	BaseURL string
+	Client  *http.Client
+}
+
+func NewFeishuBot(token, secret, keyword string) *FeishuBot {
+	return &FeishuBot{
+		Token:   token,
+		Secret:  secret,
+		Keyword: keyword,
+		BaseURL: BaseURL,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (bot *FeishuBot) GetKeyword() string {
+	return bot.Keyword
+}
+
+// sign follows the Feishu spec: HMAC-SHA256 keyed by "timestamp\nsecret" over an empty message.
+func (bot *FeishuBot) sign(t int64) string {
+	if bot.Secret == "" {
+		return ""
+	}
+	stringToSign := fmt.Sprintf("%d\n%s", t, bot.Secret)
+	h := hmac.New(sha256.New, []byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func (bot *FeishuBot) send(msg interface{}) error {
+	u := fmt.Sprintf("%s/%s", strings.TrimRight(bot.BaseURL, "/"), bot.Token)
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	resp, err := bot.Client.Post(u, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var feishuResp Response
+	if err := json.Unmarshal(respBody, &feishuResp); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if feishuResp.Code != 0 {
+		return fmt.Errorf("feishu api error: %s (code: %d)", feishuResp.Msg, feishuResp.Code)
+	}
+	if feishuResp.StatusCode != 0 {
+		return fmt.Errorf("feishu api error: %s (code: %d)", feishuResp.StatusMessage, feishuResp.StatusCode)
+	}
+
+	return nil
+}
+
+// SendText sends a text message. mentions are Feishu open_ids (ou_xxx).
+func (bot *FeishuBot) SendText(content string, mentions []string, mentionAll bool) error {
+	if bot.Keyword != "" {
+		content = fmt.Sprintf("[%s] %s", bot.Keyword, content)
+	}
+	for _, m := range mentions {
+		content += fmt.Sprintf(` <at user_id="%s"></at>`, m)
+	}
+	if mentionAll {
+		content += ` <at user_id="all">所有人</at>`
+	}
+
+	msg := TextMessage{MsgType: "text"}
+	msg.Timestamp, msg.Sign = bot.signFields()
+	msg.Content.Text = content
+	return bot.send(msg)
+}
+
+// SendMarkdown renders the markdown report as an interactive card.
+func (bot *FeishuBot) SendMarkdown(title, text string, mentions []string, mentionAll bool) error {
+	if bot.Keyword != "" && !strings.Contains(title, bot.Keyword) && !strings.Contains(text, bot.Keyword) {
+		title = fmt.Sprintf("[%s] %s", bot.Keyword, title)
+	}
+
+	card := RenderCard(title, text)
+	if len(mentions) > 0 || mentionAll {
+		var ats []string
+		for _, m := range mentions {
+			ats = append(ats, fmt.Sprintf("<at id=%s></at>", m))
+		}
+		if mentionAll {
+			ats = append(ats, "<at id=all></at>")
+		}
+		card.Elements = append(card.Elements, CardElement{Tag: "div", Text: &CardText{Tag: "lark_md", Content: strings.Join(ats, " ")}})
+	}
+
+	msg := CardMessage{MsgType: "interactive", Card: card}
+	msg.Timestamp, msg.Sign = bot.signFields()
+	return bot.send(msg)
+}
+
+func (bot *FeishuBot) signFields() (string, string) {
+	if bot.Secret == "" {
+		return "", ""
+	}
+	timestamp := time.Now().Unix()
+	return fmt.Sprintf("%d", timestamp), bot.sign(timestamp)
+}
+
+// RenderCard converts the markdown produced by the tasks into a Feishu card:
+//   - "#" headings become bold section titles (a leading heading equal to the title is dropped)
+//   - "---" separators become hr elements
+//   - "- **Key**: value" lines are laid out as two-column fields, indented sub-items stay with their field
+//   - lines holding only a link become buttons
+func RenderCard(title, text string) Card {
+	card := Card{
+		Config: CardConfig{WideScreenMode: true},
+		Header: CardHeader{
+			Title:    CardText{Tag: "plain_text", Content: title},
+			Template: defaultTemplate,
+		},
+	}
+
+	var textLines []string
+	var fields []CardField
+	var buttons []CardButton
+
+	flush := func() {
+		if content := strings.TrimSpace(strings.Join(textLines, "\n")); content != "" {
+			card.Elements = append(card.Elements, CardElement{Tag: "div", Text: &CardText{Tag: "lark_md", Content: content}})
+		}
+		textLines = nil
+		if len(fields) > 0 {
+			card.Elements = append(card.Elements, CardElement{Tag: "div", Fields: fields})
+			fields = nil
+		}
+		if len(buttons) > 0 {
+			card.Elements = append(card.Elements, CardElement{Tag: "action", Actions: buttons})
+			buttons = nil
+		}
+	}
+
+	first := true
+	for _, rawLine := range strings.Split(text, "\n") {
+		line := strings.TrimSpace(rawLine)
+		if line == "" {
+			continue
+		}
+
+		if line == "---" {
+			flush()
+			if n := len(card.Elements); n > 0 && card.Elements[n-1].Tag != "hr" {
+				card.Elements = append(card.Elements, CardElement{Tag: "hr"})
+			}
+			first = false
+			continue
+		}
+
+		if m := headingRegex.FindStringSubmatch(line); m != nil {
+			heading := strings.TrimSpace(m[1])
+			if first && (heading == title || strings.Contains(title, heading)) {
+				first = false
+				continue
+			}
+			flush()
+			textLines = append(textLines, "**"+toLarkMd(heading)+"**")
+			first = false
+			continue
+		}
+		first = false
+
+		if m := buttonRegex.FindStringSubmatch(line); m != nil {
+			if len(textLines) > 0 || len(fields) > 0 {
+				flush()
+			}
+			buttons = append(buttons, CardButton{
+				Tag:  "button",
+				Text: CardText{Tag: "plain_text", Content: m[1]},
+				URL:  m[2],
+				Type: "default",
+			})
+			continue
+		}
+
+		if m := fieldRegex.FindStringSubmatch(line); m != nil {
+			if len(textLines) > 0 || len(buttons) > 0 {
+				flush()
+			}
+			fields = append(fields, CardField{
+				IsShort: true,
+				Text:    CardText{Tag: "lark_md", Content: fmt.Sprintf("**%s**\n%s", toLarkMd(m[1]), toLarkMd(m[2]))},
+			})
+			continue
+		}
+
+		// Indented sub-items belong to the preceding field.
+		if len(fields) > 0 && rawLine != line && strings.HasPrefix(line, "- ") {
+			last := &fields[len(fields)-1]
+			last.Text.Content += "\n" + toLarkMd(strings.TrimPrefix(line, "- "))
+			continue
+		}
+
+		if len(fields) > 0 || len(buttons) > 0 {
+			flush()
+		}
+		textLines = append(textLines, toLarkMd(line))
+	}
+	flush()
+
+	// Drop a trailing separator left by the report footer layout.
+	if n := len(card.Elements); n > 0 && card.Elements[n-1].Tag == "hr" {
+		card.Elements = card.Elements[:n-1]
+	}
+
+	return card
+}
+
+// toLarkMd adapts DingTalk markdown to lark_md, which has no bold-italic marker.
+func toLarkMd(s string) string {
+	return strings.ReplaceAll(s, "***", "**")
+}
@@ -0,0 +1,158 @@
+package feishu
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ka1fe1/crypto-monitoring/config"
+	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+var (
+	cfg *config.Config
+	bot *FeishuBot
+)
+
+// loadTestConfig resolves the absolute path to config.yaml and loads it.
+func loadTestConfig() (*config.Config, error) {
+	// 1. Get the absolute path of the current file to determine the project root.
+	_, filename, _, ok := runtime.Caller(0)
+	if !ok {
+		return nil, fmt.Errorf("failed to get current file path")
+	}
+
+	// The current file is in <ProjectRoot>/pkg/utils/alter/feishu/bot_test.go
+	// So we go up four levels to get to <ProjectRoot>
+	rootDir := filepath.Dir(filepath.Dir(filepath.Dir(filepath.Dir(filepath.Dir(filename)))))
+
+	// 2. Construct the absolute path to config.yaml
+	configPath := filepath.Join(rootDir, "config", "config.yaml")
+
+	// 3. Load the configuration
+	return config.LoadConfig(configPath)
+}
+
+func TestMain(m *testing.M) {
+	var err error
+	cfg, err = loadTestConfig()
+	if err != nil {
+		logger.Warn("Warning: Could not load config: %v", err)
+	}
+
+	var token, secret, keyword string
+	if cfg != nil {
+		token = cfg.Feishu[constant.DEFAULT_BOT_NAME].AccessToken
+		secret = cfg.Feishu[constant.DEFAULT_BOT_NAME].Secret
+		keyword = cfg.Feishu[constant.DEFAULT_BOT_NAME].Keyword
+	}
+
+	bot = NewFeishuBot(token, secret, keyword)
+	os.Exit(m.Run())
+}
+
+func TestSendMarkdown(t *testing.T) {
+	title := "token Price Alerts"
+	text := "#### token Price Alerts\n\n### Crypto Assets\n- **BTC**: ***$60000.00*** (0.50%)\n---\n**Last Updated**: 2026-03-02 16:27:00"
+
+	// Mock server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var msg CardMessage
+		json.Unmarshal(body, &msg)
+
+		if msg.MsgType != "interactive" {
+			t.Errorf("expected msg_type interactive, got %s", msg.MsgType)
+		}
+		if msg.Sign == "" || msg.Timestamp == "" {
+			t.Errorf("expected signed message")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":0,"msg":"success","data":{}}`))
+	}))
+	defer server.Close()
+
+	// Only use mock server if no token is configured
+	testBot := bot
+	if bot.Token == "" {
+		testBot = NewFeishuBot("token", "secret", "")
+		testBot.BaseURL = server.URL
+	}
+
+	err := testBot.SendMarkdown(title, text, nil, false)
+	if err != nil {
+		t.Fatalf("SendMarkdown failed: %v", err)
+	}
+}
+
+func TestSendText_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":19021,"msg":"sign match fail or timestamp is not within one hour from current time"}`))
+	}))
+	defer server.Close()
+
+	errBot := NewFeishuBot("token", "secret", "")
+	errBot.BaseURL = server.URL
+
+	err := errBot.SendText("hello", nil, true)
+	if err == nil || !strings.Contains(err.Error(), "19021") {
+		t.Fatalf("expected feishu api error, got %v", err)
+	}
+}
+
+func TestSign(t *testing.T) {
+	signBot := NewFeishuBot("token", "secret", "")
+	// HMAC-SHA256 keyed by "1599360473\nsecret" over an empty message.
+	if got := signBot.sign(1599360473); got != "q4jswNiMy51J5JuQV566yJat0/lQ/c+22kINzUgKsGU=" {
+		t.Errorf("unexpected sign: %s", got)
+	}
+	if NewFeishuBot("token", "", "").sign(1599360473) != "" {
+		t.Errorf("expected empty sign without secret")
+	}
+}
+
+func TestRenderCard(t *testing.T) {
+	text := "### 📉 BTC 宏观周期指标监控\n\n" +
+		"- **当前价格**: $60000.00\n" +
+		"- **200 周均线 (200WMA)**: $40000.00\n  - 偏离度: 1.50x (状态: 正常牛市区间)\n" +
+		"---\n" +
+		"### Tweet\n- Type: tweet | IsReply: No\n- [View on Twitter](https://x.com/cz_binance/status/1)\n"
+
+	card := RenderCard("btc-metric BTC 宏观周期指标", text)
+
+	if card.Header.Title.Content != "btc-metric BTC 宏观周期指标" {
+		t.Errorf("unexpected header: %s", card.Header.Title.Content)
+	}
+
+	var tags []string
+	for _, e := range card.Elements {
+		tags = append(tags, e.Tag)
+	}
+	expectedTags := "div,div,hr,div,action"
+	if strings.Join(tags, ",") != expectedTags {
+		t.Fatalf("expected elements %s, got %s", expectedTags, strings.Join(tags, ","))
+	}
+
+	fields := card.Elements[1].Fields
+	if len(fields) != 2 {
+		t.Fatalf("expected 2 fields, got %d", len(fields))
+	}
+	if !strings.Contains(fields[1].Text.Content, "偏离度: 1.50x") {
+		t.Errorf("expected sub item to stay with its field, got %s", fields[1].Text.Content)
+	}
+
+	button := card.Elements[4].Actions[0]
+	if button.Text.Content != "View on Twitter" || button.URL != "https://x.com/cz_binance/status/1" {
+		t.Errorf("unexpected button: %+v", button)
+	}
+}
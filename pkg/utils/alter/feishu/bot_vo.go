@@ -0,0 +1,68 @@
+package feishu
+
+// TextMessage is the payload for a plain text message.
+type TextMessage struct {
+	Timestamp string `json:"timestamp,omitempty"`
+	Sign      string `json:"sign,omitempty"`
+	MsgType   string `json:"msg_type"`
+	Content   struct {
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+// CardMessage is the payload for an interactive card message.
+type CardMessage struct {
+	Timestamp string `json:"timestamp,omitempty"`
+	Sign      string `json:"sign,omitempty"`
+	MsgType   string `json:"msg_type"`
+	Card      Card   `json:"card"`
+}
+
+type Card struct {
+	Config   CardConfig    `json:"config"`
+	Header   CardHeader    `json:"header"`
+	Elements []CardElement `json:"elements"`
+}
+
+type CardConfig struct {
+	WideScreenMode bool `json:"wide_screen_mode"`
+}
+
+type CardHeader struct {
+	Title    CardText `json:"title"`
+	Template string   `json:"template"`
+}
+
+// CardText is used both for plain_text and lark_md content.
+type CardText struct {
+	Tag     string `json:"tag"`
+	Content string `json:"content"`
+}
+
+type CardField struct {
+	IsShort bool     `json:"is_short"`
+	Text    CardText `json:"text"`
+}
+
+type CardButton struct {
+	Tag  string   `json:"tag"`
+	Text CardText `json:"text"`
+	URL  string   `json:"url"`
+	Type string   `json:"type"`
+}
+
+// CardElement covers the element kinds we render: div (text or fields), hr and action.
+type CardElement struct {
+	Tag     string       `json:"tag"`
+	Text    *CardText    `json:"text,omitempty"`
+	Fields  []CardField  `json:"fields,omitempty"`
+	Actions []CardButton `json:"actions,omitempty"`
+}
+
+// Response covers both the current ({code, msg}) and legacy ({StatusCode, StatusMessage}) webhook replies.
+type Response struct {
+	Code          int    `json:"code"`
+	Msg           string `json:"msg"`
+	StatusCode    int    `json:"StatusCode"`
+	StatusMessage string `json:"StatusMessage"`
+}
@@ -0,0 +1,102 @@
+package alter
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Notifier is implemented by every alert channel (DingTalk, Telegram, ...).
+// mentions are channel specific: mobile numbers for DingTalk, usernames for Telegram.
+type Notifier interface {
+	// GetKeyword returns the keyword the bot prefixes to its messages, usually the bot name.
+	GetKeyword() string
+	SendText(content string, mentions []string, mentionAll bool) error
+	SendMarkdown(title, text string, mentions []string, mentionAll bool) error
+}
+
+// PartSender is implemented by channels that split long messages into parts. The methods skip
+// the first skip parts, so a retry continues after the parts that were already delivered.
+type PartSender interface {
+	SendMarkdownFrom(skip int, title, text string, mentions []string, mentionAll bool) error
+	SendEventFrom(skip int, e Event) error
+}
+
+// PartialSendError reports that a message split into Total parts failed after the first Sent
+// parts were delivered.
+type PartialSendError struct {
+	Sent  int
+	Total int
+	Err   error
+}
+
+func (e *PartialSendError) Error() string {
+	return fmt.Sprintf("failed to send part %d/%d: %v", e.Sent+1, e.Total, e.Err)
+}
+
+func (e *PartialSendError) Unwrap() error {
+	return e.Err
+}
+
+// MultiNotifier fans a message out to several channels configured under the same bot name.
+type MultiNotifier struct {
+	keyword   string
+	notifiers []Notifier
+}
+
+func NewMultiNotifier(keyword string, notifiers ...Notifier) *MultiNotifier {
+	return &MultiNotifier{
+		keyword:   keyword,
+		notifiers: notifiers,
+	}
+}
+
+func (m *MultiNotifier) GetKeyword() string {
+	return m.keyword
+}
+
+func (m *MultiNotifier) SendText(content string, mentions []string, mentionAll bool) error {
+	var errs []error
+	for _, n := range m.notifiers {
+		if err := n.SendText(content, mentions, mentionAll); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *MultiNotifier) SendMarkdown(title, text string, mentions []string, mentionAll bool) error {
+	var errs []error
+	for _, n := range m.notifiers {
+		if err := n.SendMarkdown(title, text, mentions, mentionAll); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SendEvent forwards the event to every channel, each in the form it understands.
+func (m *MultiNotifier) SendEvent(e Event) error {
+	var errs []error
+	for _, n := range m.notifiers {
+		if err := Notify(n, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AddNotifier registers n under name. If the name is already taken by another channel,
+// both are combined into a MultiNotifier so the bot name resolves to every channel.
+func AddNotifier(notifiers map[string]Notifier, name string, n Notifier) {
+	existing, ok := notifiers[name]
+	if !ok {
+		notifiers[name] = n
+		return
+	}
+
+	if multi, ok := existing.(*MultiNotifier); ok {
+		multi.notifiers = append(multi.notifiers, n)
+		return
+	}
+	notifiers[name] = NewMultiNotifier(name, existing, n)
+}
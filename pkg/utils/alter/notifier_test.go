@@ -0,0 +1,59 @@
+package alter
+
+import (
+	"errors"
+	"testing"
+)
+
+type recordNotifier struct {
+	keyword string
+	sent    []string
+	err     error
+}
+
+func (r *recordNotifier) GetKeyword() string {
+	return r.keyword
+}
+
+func (r *recordNotifier) SendText(content string, mentions []string, mentionAll bool) error {
+	r.sent = append(r.sent, content)
+	return r.err
+}
+
+func (r *recordNotifier) SendMarkdown(title, text string, mentions []string, mentionAll bool) error {
+	r.sent = append(r.sent, title)
+	return r.err
+}
+
+func TestAddNotifier(t *testing.T) {
+	notifiers := make(map[string]Notifier)
+	ding := &recordNotifier{keyword: "token"}
+	tg := &recordNotifier{keyword: "token", err: errors.New("telegram down")}
+	other := &recordNotifier{keyword: "x"}
+
+	AddNotifier(notifiers, "token", ding)
+	AddNotifier(notifiers, "x", other)
+	if notifiers["token"] != ding {
+		t.Fatalf("expected single notifier to be registered as is")
+	}
+
+	AddNotifier(notifiers, "token", tg)
+	multi, ok := notifiers["token"].(*MultiNotifier)
+	if !ok {
+		t.Fatalf("expected MultiNotifier for shared bot name, got %T", notifiers["token"])
+	}
+	if multi.GetKeyword() != "token" {
+		t.Errorf("expected keyword token, got %s", multi.GetKeyword())
+	}
+
+	err := multi.SendMarkdown("title", "text", nil, false)
+	if err == nil {
+		t.Errorf("expected error from failing channel to be reported")
+	}
+	if len(ding.sent) != 1 || len(tg.sent) != 1 {
+		t.Errorf("expected message to reach every channel, got ding=%d telegram=%d", len(ding.sent), len(tg.sent))
+	}
+	if len(other.sent) != 0 {
+		t.Errorf("expected other bot to be untouched")
+	}
+}
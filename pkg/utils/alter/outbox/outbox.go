@@ -0,0 +1,490 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+const (
+	STATUS_PENDING  = "pending"  // recorded, first delivery in progress
+	STATUS_RETRYING = "retrying" // delivery failed, retried at NextRetry
+	STATUS_FAILED   = "failed"   // gave up after MaxAttempts, only re-sent on request
+	STATUS_SENT     = "sent"
+
+	KIND_TEXT  = "text"
+	KIND_EVENT = "event"
+
+	DEFAULT_MAX_ATTEMPTS = 5
+
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = time.Hour
+	retryInterval  = 15 * time.Second
+	// Delivered messages are dropped from the file when it is compacted after this long.
+	sentRetention = 7 * 24 * time.Hour
+	// The file is compacted once it holds more superseded lines than this and than current
+	// records, and at least once per compactInterval so old delivered messages are dropped.
+	compactMinStaleLines = 1000
+	compactInterval      = 24 * time.Hour
+)
+
+// Record is one outgoing message and its delivery state.
+type Record struct {
+	ID         string      `json:"id"`
+	Bot        string      `json:"bot"`
+	Channel    string      `json:"channel"`
+	Kind       string      `json:"kind"`
+	Text       string      `json:"text,omitempty"`
+	Event      alter.Event `json:"event"`
+	Mentions   []string    `json:"mentions,omitempty"`
+	MentionAll bool        `json:"mention_all,omitempty"`
+	Status     string      `json:"status"`
+	Attempts   int         `json:"attempts"`
+	PartsSent  int         `json:"parts_sent,omitempty"` // leading parts of a split message already delivered
+	LastError  string      `json:"last_error,omitempty"`
+	NextRetry  time.Time   `json:"next_retry,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// Outbox records every outgoing message in an append-only JSON lines file, delivers it
+// in the background and retries failed deliveries with backoff, also across restarts.
+// Each line holds the full record, the last line of an ID is its current state.
+type Outbox struct {
+	path        string
+	maxAttempts int
+
+	mu          sync.Mutex
+	file        *os.File
+	records     map[string]*Record
+	notifiers   map[string]alter.Notifier // key: bot/channel
+	inFlight    map[string]bool
+	lines       int // lines in the file, one per record after compaction
+	compactedAt time.Time
+	stopped     bool // set by Stop, no delivery is started afterwards
+
+	wg   sync.WaitGroup
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Open loads the outbox file at path, compacting it to the current state of every record.
+func Open(path string, maxAttempts int) (*Outbox, error) {
+	if maxAttempts <= 0 {
+		maxAttempts = DEFAULT_MAX_ATTEMPTS
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create outbox dir: %w", err)
+	}
+
+	records, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &Outbox{
+		path:        path,
+		maxAttempts: maxAttempts,
+		records:     records,
+		notifiers:   make(map[string]alter.Notifier),
+		inFlight:    make(map[string]bool),
+	}
+	if err := o.compact(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func load(path string) (map[string]*Record, error) {
+	records := make(map[string]*Record)
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open outbox: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			// A partially written last line after a crash, keep what we have.
+			logger.Warn("Skipping malformed outbox line: %v", err)
+			continue
+		}
+		records[r.ID] = &r
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read outbox: %w", err)
+	}
+	return records, nil
+}
+
+// compact rewrites the file with one line per record, dropping old delivered messages.
+// Caller holds o.mu once the outbox is in use.
+func (o *Outbox) compact() error {
+	cutoff := time.Now().Add(-sentRetention)
+	for id, r := range o.records {
+		if r.Status == STATUS_SENT && r.UpdatedAt.Before(cutoff) {
+			delete(o.records, id)
+		}
+	}
+
+	tmp := o.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	for _, r := range o.sorted() {
+		b, err := json.Marshal(r)
+		if err != nil {
+			f.Close()
+			return fmt.Errorf("failed to marshal outbox record: %w", err)
+		}
+		w.Write(append(b, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write outbox: %w", err)
+	}
+	f.Close()
+	if err := os.Rename(tmp, o.path); err != nil {
+		return fmt.Errorf("failed to replace outbox: %w", err)
+	}
+
+	file, err := os.OpenFile(o.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open outbox: %w", err)
+	}
+	if o.file != nil {
+		o.file.Close()
+	}
+	o.file = file
+	o.lines = len(o.records)
+	o.compactedAt = time.Now()
+	return nil
+}
+
+// compactIfNeeded compacts the file once superseded lines outweigh the current records or
+// compactInterval has passed. Caller holds o.mu.
+func (o *Outbox) compactIfNeeded(now time.Time) {
+	stale := o.lines - len(o.records)
+	if (stale < compactMinStaleLines || stale < len(o.records)) && now.Sub(o.compactedAt) < compactInterval {
+		return
+	}
+	if err := o.compact(); err != nil {
+		logger.Error("Failed to compact outbox: %v", err)
+	}
+}
+
+func (o *Outbox) sorted() []*Record {
+	list := make([]*Record, 0, len(o.records))
+	for _, r := range o.records {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list
+}
+
+// persist appends the record's current state. Caller holds o.mu.
+func (o *Outbox) persist(r *Record) {
+	r.UpdatedAt = time.Now()
+	if o.file == nil {
+		// A delivery that outlived Stop, the file still holds the record's previous state.
+		logger.Error("Outbox is closed, not recording %s message %s as %s", notifierKey(r.Bot, r.Channel), r.ID, r.Status)
+		return
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		logger.Error("Failed to marshal outbox record %s: %v", r.ID, err)
+		return
+	}
+	if _, err := o.file.Write(append(b, '\n')); err != nil {
+		logger.Error("Failed to write outbox record %s: %v", r.ID, err)
+		return
+	}
+	o.lines++
+}
+
+// Wrap returns a notifier that records every message for bot/channel in the outbox
+// and delivers it through n in the background.
+func (o *Outbox) Wrap(bot, channel string, n alter.Notifier) alter.Notifier {
+	o.mu.Lock()
+	o.notifiers[notifierKey(bot, channel)] = n
+	o.mu.Unlock()
+	return &outboxNotifier{outbox: o, bot: bot, channel: channel, keyword: n.GetKeyword()}
+}
+
+func notifierKey(bot, channel string) string {
+	return bot + "/" + channel
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return fmt.Sprintf("%d-%s", time.Now().UnixMilli(), hex.EncodeToString(b))
+}
+
+// add records a new message and starts its delivery. Once the outbox is stopping the message
+// is only recorded and sent on the next start, after the file is closed it is refused.
+func (o *Outbox) add(r *Record) error {
+	r.ID = newID()
+	r.Status = STATUS_PENDING
+	r.CreatedAt = time.Now()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.file == nil {
+		return fmt.Errorf("outbox is closed, %s message dropped", notifierKey(r.Bot, r.Channel))
+	}
+	o.records[r.ID] = r
+	o.persist(r)
+	if o.stopped {
+		logger.Warn("Outbox is stopping, %s message %s is sent on the next start", notifierKey(r.Bot, r.Channel), r.ID)
+		return nil
+	}
+	o.inFlight[r.ID] = true
+	o.wg.Add(1)
+	go o.deliver(r.ID)
+	return nil
+}
+
+// deliver sends the record once and stores the outcome. The record must be marked in flight.
+func (o *Outbox) deliver(id string) {
+	defer o.wg.Done()
+
+	o.mu.Lock()
+	r, ok := o.records[id]
+	if !ok {
+		delete(o.inFlight, id)
+		o.mu.Unlock()
+		return
+	}
+	n := o.notifiers[notifierKey(r.Bot, r.Channel)]
+	snapshot := *r
+	o.mu.Unlock()
+
+	var err error
+	ps, resumable := n.(alter.PartSender)
+	if n == nil {
+		err = fmt.Errorf("no notifier configured for %s", notifierKey(snapshot.Bot, snapshot.Channel))
+	} else if snapshot.Kind == KIND_TEXT {
+		err = n.SendText(snapshot.Text, snapshot.Mentions, snapshot.MentionAll)
+	} else if resumable && snapshot.PartsSent > 0 {
+		// Continue a split message after the parts a previous attempt delivered.
+		if len(snapshot.Mentions) > 0 || snapshot.MentionAll {
+			err = ps.SendMarkdownFrom(snapshot.PartsSent, snapshot.Event.Title, snapshot.Event.MarkdownWithLinks(), snapshot.Mentions, snapshot.MentionAll)
+		} else {
+			err = ps.SendEventFrom(snapshot.PartsSent, snapshot.Event)
+		}
+	} else if len(snapshot.Mentions) > 0 || snapshot.MentionAll {
+		err = n.SendMarkdown(snapshot.Event.Title, snapshot.Event.MarkdownWithLinks(), snapshot.Mentions, snapshot.MentionAll)
+	} else {
+		err = alter.Notify(n, snapshot.Event)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.inFlight, id)
+
+	r.Attempts++
+	if err == nil {
+		r.Status = STATUS_SENT
+		r.LastError = ""
+		r.NextRetry = time.Time{}
+	} else {
+		r.LastError = err.Error()
+		var partial *alter.PartialSendError
+		if errors.As(err, &partial) && partial.Sent > r.PartsSent {
+			r.PartsSent = partial.Sent
+		}
+		if r.Attempts >= o.maxAttempts {
+			r.Status = STATUS_FAILED
+			r.NextRetry = time.Time{}
+			logger.Error("Giving up on %s message %s after %d attempts: %v", notifierKey(r.Bot, r.Channel), r.ID, r.Attempts, err)
+		} else {
+			r.Status = STATUS_RETRYING
+			r.NextRetry = time.Now().Add(backoff(r.Attempts))
+			logger.Warn("Error sending %s message %s (attempt %d), retrying at %s: %v", notifierKey(r.Bot, r.Channel), r.ID, r.Attempts, r.NextRetry.Format(time.RFC3339), err)
+		}
+	}
+	o.persist(r)
+}
+
+func backoff(attempts int) time.Duration {
+	d := retryBaseDelay << (attempts - 1)
+	if d > retryMaxDelay || d <= 0 {
+		d = retryMaxDelay
+	}
+	return d
+}
+
+// Start runs the retry loop. Messages left pending by a previous run are retried right away.
+func (o *Outbox) Start() {
+	o.stop = make(chan struct{})
+	o.done = make(chan struct{})
+
+	go func() {
+		defer close(o.done)
+		ticker := time.NewTicker(retryInterval)
+		defer ticker.Stop()
+
+		o.retryDue()
+		for {
+			select {
+			case <-ticker.C:
+				o.retryDue()
+			case <-o.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (o *Outbox) retryDue() {
+	now := time.Now()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.stopped {
+		return
+	}
+	o.compactIfNeeded(now)
+	for id, r := range o.records {
+		if o.inFlight[id] {
+			continue
+		}
+		if r.Status == STATUS_PENDING || (r.Status == STATUS_RETRYING && !r.NextRetry.After(now)) {
+			o.inFlight[id] = true
+			o.wg.Add(1)
+			go o.deliver(id)
+		}
+	}
+}
+
+// Stop ends the retry loop and waits for deliveries in progress until ctx ends.
+// Undelivered messages stay in the file and are retried on the next start, the outcome of a
+// delivery still running when ctx ends is lost and the message is sent again.
+func (o *Outbox) Stop(ctx context.Context) error {
+	// Deliveries are only started under o.mu while not stopped, so none is added to o.wg once
+	// Wait has begun.
+	o.mu.Lock()
+	o.stopped = true
+	o.mu.Unlock()
+
+	if o.stop != nil {
+		close(o.stop)
+		<-o.done
+		o.stop = nil
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		o.wg.Wait()
+		close(finished)
+	}()
+
+	var err error
+	select {
+	case <-finished:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.file != nil {
+		if closeErr := o.file.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		o.file = nil
+	}
+	return err
+}
+
+// List returns the records with the given status, or every undelivered record when status is empty, oldest first.
+func (o *Outbox) List(status string) []Record {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var list []Record
+	for _, r := range o.sorted() {
+		if status == "" && r.Status == STATUS_SENT {
+			continue
+		}
+		if status != "" && r.Status != status {
+			continue
+		}
+		list = append(list, *r)
+	}
+	return list
+}
+
+// Resend delivers an undelivered record again right away, also after it was given up on.
+func (o *Outbox) Resend(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.stopped {
+		return errors.New("outbox is stopped")
+	}
+	r, ok := o.records[id]
+	if !ok {
+		return fmt.Errorf("message %s not found", id)
+	}
+	if r.Status == STATUS_SENT {
+		return fmt.Errorf("message %s was already sent", id)
+	}
+	if o.inFlight[id] {
+		return fmt.Errorf("message %s is being sent", id)
+	}
+	r.Status = STATUS_RETRYING
+	r.Attempts = 0
+	r.NextRetry = time.Time{}
+	o.persist(r)
+	o.inFlight[id] = true
+	o.wg.Add(1)
+	go o.deliver(id)
+	return nil
+}
+
+// outboxNotifier records messages instead of sending them directly, so tasks never wait on delivery.
+type outboxNotifier struct {
+	outbox  *Outbox
+	bot     string
+	channel string
+	keyword string
+}
+
+func (n *outboxNotifier) GetKeyword() string {
+	return n.keyword
+}
+
+func (n *outboxNotifier) SendText(content string, mentions []string, mentionAll bool) error {
+	return n.outbox.add(&Record{Bot: n.bot, Channel: n.channel, Kind: KIND_TEXT, Text: content, Mentions: mentions, MentionAll: mentionAll})
+}
+
+func (n *outboxNotifier) SendMarkdown(title, text string, mentions []string, mentionAll bool) error {
+	e := alter.NewEvent("", constant.SEVERITY_INFO, title, text)
+	return n.outbox.add(&Record{Bot: n.bot, Channel: n.channel, Kind: KIND_EVENT, Event: e, Mentions: mentions, MentionAll: mentionAll})
+}
+
+func (n *outboxNotifier) SendEvent(e alter.Event) error {
+	return n.outbox.add(&Record{Bot: n.bot, Channel: n.channel, Kind: KIND_EVENT, Event: e})
+}
@@ -0,0 +1,288 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+type fakeNotifier struct {
+	mu     sync.Mutex
+	fail   int // number of sends to fail before succeeding
+	events []alter.Event
+}
+
+func (f *fakeNotifier) GetKeyword() string { return "token" }
+
+func (f *fakeNotifier) SendText(content string, mentions []string, mentionAll bool) error {
+	return f.SendEvent(alter.Event{Title: content})
+}
+
+func (f *fakeNotifier) SendMarkdown(title, text string, mentions []string, mentionAll bool) error {
+	return f.SendEvent(alter.Event{Title: title, Markdown: text})
+}
+
+func (f *fakeNotifier) SendEvent(e alter.Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail > 0 {
+		f.fail--
+		return errors.New("dingtalk down")
+	}
+	f.events = append(f.events, e)
+	return nil
+}
+
+func (f *fakeNotifier) sent() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.events)
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestOutbox_DeliverAndRetryAcrossRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	box, err := Open(path, 3)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	ok := &fakeNotifier{}
+	down := &fakeNotifier{fail: 100}
+	okNotifier := box.Wrap("token", "dingtalk", ok)
+	downNotifier := box.Wrap("token", "telegram", down)
+
+	e := alter.NewEvent("TokenPriceMonitorTask", constant.SEVERITY_WARNING, "token Price Alerts", "- **BTC**: $60000")
+	if err := alter.Notify(okNotifier, e); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if err := alter.Notify(downNotifier, e); err != nil {
+		t.Fatalf("Notify should not report delivery errors, got %v", err)
+	}
+
+	waitFor(t, func() bool { return len(box.List(STATUS_SENT)) == 1 && len(box.List(STATUS_RETRYING)) == 1 })
+	if ok.sent() != 1 || ok.events[0].Severity != constant.SEVERITY_WARNING {
+		t.Errorf("expected the structured event to be delivered, got %+v", ok.events)
+	}
+	failed := box.List("")
+	if len(failed) != 1 || failed[0].Channel != "telegram" || failed[0].LastError != "dingtalk down" {
+		t.Fatalf("unexpected undelivered records: %+v", failed)
+	}
+	if err := box.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	// Restart: the undelivered message is loaded from disk and delivered once the channel is back.
+	box, err = Open(path, 3)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	recovered := &fakeNotifier{}
+	box.Wrap("token", "telegram", recovered)
+	if err := box.Resend(failed[0].ID); err != nil {
+		t.Fatalf("Resend failed: %v", err)
+	}
+
+	waitFor(t, func() bool { return recovered.sent() == 1 })
+	waitFor(t, func() bool { return len(box.List("")) == 0 })
+	if recovered.events[0].Title != "token Price Alerts" {
+		t.Errorf("unexpected replayed event: %+v", recovered.events[0])
+	}
+	if err := box.Resend(failed[0].ID); err == nil {
+		t.Errorf("expected resending a delivered message to fail")
+	}
+	box.Stop(context.Background())
+}
+
+func TestOutbox_GivesUpAfterMaxAttempts(t *testing.T) {
+	box, err := Open(filepath.Join(t.TempDir(), "outbox.jsonl"), 2)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer box.Stop(context.Background())
+
+	down := &fakeNotifier{fail: 100}
+	box.Wrap("x", "dingtalk", down).SendText("hello", nil, false)
+	waitFor(t, func() bool { return len(box.List(STATUS_RETRYING)) == 1 })
+
+	// Make the retry due and run it.
+	box.mu.Lock()
+	for _, r := range box.records {
+		r.NextRetry = time.Now().Add(-time.Second)
+	}
+	box.mu.Unlock()
+	box.retryDue()
+
+	waitFor(t, func() bool { return len(box.List(STATUS_FAILED)) == 1 })
+	if r := box.List(STATUS_FAILED)[0]; r.Attempts != 2 || r.Kind != KIND_TEXT {
+		t.Errorf("unexpected failed record: %+v", r)
+	}
+}
+
+// partNotifier delivers every event as three parts and fails the second part once.
+type partNotifier struct {
+	fakeNotifier
+	parts   []int
+	failed  bool
+	skipped []int
+}
+
+func (p *partNotifier) SendEvent(e alter.Event) error {
+	return p.SendEventFrom(0, e)
+}
+
+func (p *partNotifier) SendMarkdownFrom(skip int, title, text string, mentions []string, mentionAll bool) error {
+	return p.SendEventFrom(skip, alter.Event{Title: title, Markdown: text})
+}
+
+func (p *partNotifier) SendEventFrom(skip int, e alter.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.skipped = append(p.skipped, skip)
+	for i := skip; i < 3; i++ {
+		if i == 1 && !p.failed {
+			p.failed = true
+			return &alter.PartialSendError{Sent: i, Total: 3, Err: errors.New("send too fast")}
+		}
+		p.parts = append(p.parts, i)
+	}
+	return nil
+}
+
+func TestOutbox_ResumesSplitMessage(t *testing.T) {
+	box, err := Open(filepath.Join(t.TempDir(), "outbox.jsonl"), 3)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer box.Stop(context.Background())
+
+	n := &partNotifier{}
+	alter.Notify(box.Wrap("x", "dingtalk", n), alter.NewEvent("", constant.SEVERITY_INFO, "long", "text"))
+	waitFor(t, func() bool { return len(box.List(STATUS_RETRYING)) == 1 })
+	if r := box.List(STATUS_RETRYING)[0]; r.PartsSent != 1 {
+		t.Fatalf("expected the first part to be recorded as sent, got %+v", r)
+	}
+
+	if err := box.Resend(box.List(STATUS_RETRYING)[0].ID); err != nil {
+		t.Fatalf("Resend failed: %v", err)
+	}
+	waitFor(t, func() bool { return len(box.List("")) == 0 })
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.parts) != 3 || n.parts[0] != 0 || n.parts[1] != 1 || n.parts[2] != 2 || n.skipped[1] != 1 {
+		t.Errorf("expected every part delivered once, got parts %v after skipping %v", n.parts, n.skipped)
+	}
+}
+
+func TestOutbox_CompactsPeriodically(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	box, err := Open(path, 3)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer box.Stop(context.Background())
+
+	ok := box.Wrap("x", "dingtalk", &fakeNotifier{})
+	for i := 0; i < compactMinStaleLines; i++ {
+		ok.SendText("hello", nil, false)
+	}
+	waitFor(t, func() bool { return len(box.List(STATUS_SENT)) == compactMinStaleLines })
+
+	box.retryDue()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read outbox: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != compactMinStaleLines {
+		t.Errorf("expected one line per record after compaction, got %d", lines)
+	}
+
+	// Appends keep going to the compacted file.
+	ok.SendText("after", nil, false)
+	waitFor(t, func() bool { return len(box.List(STATUS_SENT)) == compactMinStaleLines+1 })
+	reopened, err := load(path)
+	if err != nil || len(reopened) != compactMinStaleLines+1 {
+		t.Errorf("expected %d records after reloading, got %d, %v", compactMinStaleLines+1, len(reopened), err)
+	}
+}
+
+// blockingNotifier holds every send until release is closed.
+type blockingNotifier struct {
+	fakeNotifier
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingNotifier) SendEvent(e alter.Event) error {
+	close(b.started)
+	<-b.release
+	return b.fakeNotifier.SendEvent(e)
+}
+
+func TestOutbox_StopWhileDeliveryBlocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	box, err := Open(path, 3)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	box.Start()
+
+	n := &blockingNotifier{started: make(chan struct{}), release: make(chan struct{})}
+	wrapped := box.Wrap("x", "dingtalk", n)
+	alter.Notify(wrapped, alter.NewEvent("", constant.SEVERITY_INFO, "stuck", "text"))
+	<-n.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := box.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected Stop to give up on the blocked delivery, got %v", err)
+	}
+	if err := wrapped.SendText("late", nil, false); err == nil {
+		t.Errorf("expected a message after Stop to be refused")
+	}
+	if err := box.Resend("any"); err == nil {
+		t.Errorf("expected Resend after Stop to be refused")
+	}
+
+	// The delivery finishes after the file was closed, its outcome is not recorded.
+	close(n.release)
+	waitFor(t, func() bool { return n.sent() == 1 && len(box.List(STATUS_SENT)) == 1 })
+	box.wg.Wait()
+
+	reopened, err := load(path)
+	if err != nil || len(reopened) != 1 {
+		t.Fatalf("expected the message to stay in the file, got %v, %v", reopened, err)
+	}
+	for _, r := range reopened {
+		if r.Status != STATUS_PENDING {
+			t.Errorf("expected the message to be retried on the next start, got %+v", r)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	if backoff(1) != retryBaseDelay || backoff(2) != 2*retryBaseDelay {
+		t.Errorf("unexpected backoff: %s %s", backoff(1), backoff(2))
+	}
+	if backoff(30) != retryMaxDelay {
+		t.Errorf("expected backoff to be capped, got %s", backoff(30))
+	}
+}
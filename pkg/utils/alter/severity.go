@@ -0,0 +1,46 @@
+package alter
+
+import (
+	"strings"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+// SeverityRank orders severities from info (0) to critical (2). Unknown values return -1,
+// an empty severity counts as info.
+func SeverityRank(severity string) int {
+	switch strings.ToLower(severity) {
+	case constant.SEVERITY_INFO, "":
+		return 0
+	case constant.SEVERITY_WARNING:
+		return 1
+	case constant.SEVERITY_CRITICAL:
+		return 2
+	default:
+		return -1
+	}
+}
+
+// severityFilter drops events below a minimum severity above info. Plain text and
+// markdown messages carry no severity to judge, they are forwarded unchanged.
+type severityFilter struct {
+	Notifier
+	min int
+}
+
+// WithMinSeverity wraps n so events below min are dropped. n is returned as is
+// when min is info, empty or unknown.
+func WithMinSeverity(n Notifier, min string) Notifier {
+	rank := SeverityRank(min)
+	if n == nil || rank <= 0 {
+		return n
+	}
+	return &severityFilter{Notifier: n, min: rank}
+}
+
+func (f *severityFilter) SendEvent(e Event) error {
+	if SeverityRank(e.Severity) < f.min {
+		return nil
+	}
+	return Notify(f.Notifier, e)
+}
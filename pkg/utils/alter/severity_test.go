@@ -0,0 +1,36 @@
+package alter
+
+import (
+	"testing"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+func TestWithMinSeverity(t *testing.T) {
+	inner := &recordEventNotifier{recordNotifier: recordNotifier{keyword: "alerts"}}
+
+	if n := WithMinSeverity(inner, constant.SEVERITY_INFO); n != Notifier(inner) {
+		t.Errorf("expected no filter for info")
+	}
+	if n := WithMinSeverity(inner, "loud"); n != Notifier(inner) {
+		t.Errorf("expected no filter for an unknown severity")
+	}
+
+	n := WithMinSeverity(inner, constant.SEVERITY_WARNING)
+	for _, severity := range []string{constant.SEVERITY_INFO, constant.SEVERITY_WARNING, constant.SEVERITY_CRITICAL} {
+		if err := Notify(n, NewEvent("TokenPriceMonitorTask", severity, severity, "- body")); err != nil {
+			t.Fatalf("Notify failed: %v", err)
+		}
+	}
+	n.SendMarkdown("report", "- body", nil, false)
+
+	if len(inner.events) != 2 || inner.events[0].Severity != constant.SEVERITY_WARNING || inner.events[1].Severity != constant.SEVERITY_CRITICAL {
+		t.Errorf("expected only warning and critical events, got %+v", inner.events)
+	}
+	if len(inner.sent) != 1 {
+		t.Errorf("expected plain markdown to be forwarded, got %v", inner.sent)
+	}
+	if n.GetKeyword() != "alerts" {
+		t.Errorf("expected the inner keyword, got %q", n.GetKeyword())
+	}
+}
@@ -0,0 +1,159 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	BaseURL = "https://api.telegram.org"
+)
+
+var (
+	headingRegex    = regexp.MustCompile(`^#{1,6}\s+(.*)$`)
+	boldItalicRegex = regexp.MustCompile(`\*\*\*(.+?)\*\*\*`)
+	boldRegex       = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	linkRegex       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+type TelegramBot struct {
+	Token   string
+	ChatID  string
+	Keyword string
+	BaseURL string
+	Client  *http.Client
+}
+
+func NewTelegramBot(token, chatID, keyword string) *TelegramBot {
+	return &TelegramBot{
+		Token:   token,
+		ChatID:  chatID,
+		Keyword: keyword,
+		BaseURL: BaseURL,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type SendMessageRequest struct {
+	ChatID                string `json:"chat_id"`
+	Text                  string `json:"text"`
+	ParseMode             string `json:"parse_mode,omitempty"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+type Response struct {
+	Ok          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+}
+
+func (bot *TelegramBot) GetKeyword() string {
+	return bot.Keyword
+}
+
+func (bot *TelegramBot) send(msg SendMessageRequest) error {
+	u := fmt.Sprintf("%s/bot%s/sendMessage", strings.TrimRight(bot.BaseURL, "/"), bot.Token)
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	resp, err := bot.Client.Post(u, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var tgResp Response
+	if err := json.Unmarshal(respBody, &tgResp); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if !tgResp.Ok {
+		return fmt.Errorf("telegram api error: %s (code: %d)", tgResp.Description, tgResp.ErrorCode)
+	}
+
+	return nil
+}
+
+// SendText sends a plain text message. Telegram has no "@all", so mentionAll is ignored.
+func (bot *TelegramBot) SendText(content string, mentions []string, mentionAll bool) error {
+	if bot.Keyword != "" {
+		content = fmt.Sprintf("[%s] %s", bot.Keyword, content)
+	}
+	if len(mentions) > 0 {
+		content += "\n" + formatMentions(mentions)
+	}
+
+	return bot.send(SendMessageRequest{
+		ChatID:                bot.ChatID,
+		Text:                  content,
+		DisableWebPagePreview: true,
+	})
+}
+
+// SendMarkdown converts the DingTalk flavoured markdown used by the tasks into Telegram HTML,
+// because Telegram's MarkdownV2 rejects unescaped characters that are common in our reports.
+func (bot *TelegramBot) SendMarkdown(title, text string, mentions []string, mentionAll bool) error {
+	if bot.Keyword != "" {
+		if !strings.Contains(text, bot.Keyword) {
+			text = fmt.Sprintf("[%s]\n%s", bot.Keyword, text)
+		}
+	}
+
+	content := markdownToHTML(text)
+	if len(mentions) > 0 {
+		content += "\n" + html.EscapeString(formatMentions(mentions))
+	}
+
+	return bot.send(SendMessageRequest{
+		ChatID:                bot.ChatID,
+		Text:                  content,
+		ParseMode:             "HTML",
+		DisableWebPagePreview: true,
+	})
+}
+
+func formatMentions(mentions []string) string {
+	parts := make([]string, 0, len(mentions))
+	for _, m := range mentions {
+		parts = append(parts, "@"+strings.TrimPrefix(m, "@"))
+	}
+	return strings.Join(parts, " ")
+}
+
+// markdownToHTML handles the subset of markdown the tasks produce:
+// headings, bold, bold-italic, links and "---" separators.
+func markdownToHTML(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "---" {
+			lines[i] = "──────────"
+			continue
+		}
+
+		line = html.EscapeString(line)
+		if m := headingRegex.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			line = "<b>" + m[1] + "</b>"
+		}
+		line = linkRegex.ReplaceAllString(line, `<a href="$2">$1</a>`)
+		line = boldItalicRegex.ReplaceAllString(line, "<b><i>$1</i></b>")
+		line = boldRegex.ReplaceAllString(line, "<b>$1</b>")
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
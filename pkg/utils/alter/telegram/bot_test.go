@@ -0,0 +1,127 @@
+package telegram
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ka1fe1/crypto-monitoring/config"
+	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+var (
+	cfg *config.Config
+	bot *TelegramBot
+)
+
+// loadTestConfig resolves the absolute path to config.yaml and loads it.
+func loadTestConfig() (*config.Config, error) {
+	// 1. Get the absolute path of the current file to determine the project root.
+	_, filename, _, ok := runtime.Caller(0)
+	if !ok {
+		return nil, fmt.Errorf("failed to get current file path")
+	}
+
+	// The current file is in <ProjectRoot>/pkg/utils/alter/telegram/bot_test.go
+	// So we go up four levels to get to <ProjectRoot>
+	rootDir := filepath.Dir(filepath.Dir(filepath.Dir(filepath.Dir(filepath.Dir(filename)))))
+
+	// 2. Construct the absolute path to config.yaml
+	configPath := filepath.Join(rootDir, "config", "config.yaml")
+
+	// 3. Load the configuration
+	return config.LoadConfig(configPath)
+}
+
+func TestMain(m *testing.M) {
+	var err error
+	cfg, err = loadTestConfig()
+	if err != nil {
+		logger.Warn("Warning: Could not load config: %v", err)
+	}
+
+	var token, chatID, keyword string
+	if cfg != nil {
+		token = cfg.Telegram[constant.DEFAULT_BOT_NAME].BotToken
+		chatID = cfg.Telegram[constant.DEFAULT_BOT_NAME].ChatID
+		keyword = cfg.Telegram[constant.DEFAULT_BOT_NAME].Keyword
+	}
+
+	bot = NewTelegramBot(token, chatID, keyword)
+	os.Exit(m.Run())
+}
+
+func TestSendMarkdown(t *testing.T) {
+	title := "title"
+	text := "### Crypto Assets\n- **BTC**: ***$60000.00*** (0.50%)\n---\n- [View on Twitter](https://x.com/a/status/1?s=1&t=2)"
+
+	// Mock server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/sendMessage") {
+			t.Errorf("expected sendMessage path, got %s", r.URL.Path)
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		var msg SendMessageRequest
+		json.Unmarshal(body, &msg)
+
+		if msg.ParseMode != "HTML" {
+			t.Errorf("expected parse_mode HTML, got %s", msg.ParseMode)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	// Only use mock server if no token is configured
+	if bot.Token == "" {
+		bot.BaseURL = server.URL
+	}
+
+	err := bot.SendMarkdown(title, text, nil, false)
+	if err != nil {
+		t.Fatalf("SendMarkdown failed: %v", err)
+	}
+}
+
+func TestSendText_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+	}))
+	defer server.Close()
+
+	errBot := NewTelegramBot("token", "chat", "")
+	errBot.BaseURL = server.URL
+
+	err := errBot.SendText("hello", nil, false)
+	if err == nil || !strings.Contains(err.Error(), "chat not found") {
+		t.Fatalf("expected telegram api error, got %v", err)
+	}
+}
+
+func TestMarkdownToHTML(t *testing.T) {
+	text := "#### token Price Alerts\n\n- **BTC**: ***$60000.00*** (0.50%)\n---\n- [View](https://x.com/a?b=1&c=2) <tag>"
+	got := markdownToHTML(text)
+
+	expected := []string{
+		"<b>token Price Alerts</b>",
+		"<b>BTC</b>: <b><i>$60000.00</i></b> (0.50%)",
+		"──────────",
+		`<a href="https://x.com/a?b=1&amp;c=2">View</a> &lt;tag&gt;`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(got, e) {
+			t.Errorf("expected %q in output, got:\n%s", e, got)
+		}
+	}
+}
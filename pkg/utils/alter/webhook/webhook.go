@@ -0,0 +1,150 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+const (
+	SignatureHeader = "X-Signature"
+	TimestampHeader = "X-Signature-Timestamp"
+)
+
+// WebhookBot POSTs every notification as a JSON event to an arbitrary URL.
+// The body is the JSON encoded alter.Event unless a text/template is configured.
+type WebhookBot struct {
+	URL      string
+	Secret   string
+	Keyword  string
+	Headers  map[string]string
+	Template *template.Template
+	Client   *http.Client
+}
+
+// NewWebhookBot creates a webhook notifier. tmpl is optional, it is executed with the alter.Event
+// and may use the "json" function to embed values safely, e.g. {"text": {{json .Title}}}.
+func NewWebhookBot(url, secret, keyword, tmpl string, headers map[string]string) (*WebhookBot, error) {
+	bot := &WebhookBot{
+		URL:     url,
+		Secret:  secret,
+		Keyword: keyword,
+		Headers: headers,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+
+	if strings.TrimSpace(tmpl) != "" {
+		t, err := template.New(keyword).Funcs(template.FuncMap{"json": toJSON}).Parse(tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse webhook template: %w", err)
+		}
+		bot.Template = t
+	}
+
+	return bot, nil
+}
+
+func toJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (bot *WebhookBot) GetKeyword() string {
+	return bot.Keyword
+}
+
+// sign follows DingBot.sign, but covers the body: HMAC-SHA256 keyed by the secret over "timestamp\nbody".
+func (bot *WebhookBot) sign(t int64, body []byte) string {
+	if bot.Secret == "" {
+		return ""
+	}
+	stringToSign := fmt.Sprintf("%d\n%s", t, body)
+	h := hmac.New(sha256.New, []byte(bot.Secret))
+	h.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func (bot *WebhookBot) render(e alter.Event) ([]byte, error) {
+	if bot.Template == nil {
+		body, err := json.Marshal(e)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal event: %w", err)
+		}
+		return body, nil
+	}
+
+	var buf bytes.Buffer
+	if err := bot.Template.Execute(&buf, e); err != nil {
+		return nil, fmt.Errorf("failed to execute webhook template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// SendEvent renders the event and POSTs it to the webhook URL.
+func (bot *WebhookBot) SendEvent(e alter.Event) error {
+	if e.Bot == "" {
+		e.Bot = bot.Keyword
+	}
+	if e.Severity == "" {
+		e.Severity = constant.SEVERITY_INFO
+	}
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+
+	body, err := bot.render(e)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, bot.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range bot.Headers {
+		req.Header.Set(k, v)
+	}
+	if bot.Secret != "" {
+		timestamp := time.Now().UnixMilli()
+		req.Header.Set(TimestampHeader, fmt.Sprintf("%d", timestamp))
+		req.Header.Set(SignatureHeader, bot.sign(timestamp, body))
+	}
+
+	resp, err := bot.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook error: %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	return nil
+}
+
+// SendText sends the content as an info event titled with the bot keyword, so templates reading
+// the title do not get the whole message. Mentions have no meaning for webhooks.
+func (bot *WebhookBot) SendText(content string, mentions []string, mentionAll bool) error {
+	return bot.SendEvent(alter.NewEvent("", constant.SEVERITY_INFO, bot.Keyword, content))
+}
+
+func (bot *WebhookBot) SendMarkdown(title, text string, mentions []string, mentionAll bool) error {
+	return bot.SendEvent(alter.NewEvent("", constant.SEVERITY_INFO, title, text))
+}
@@ -0,0 +1,117 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/alter"
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+func TestSendEvent_DefaultJSON(t *testing.T) {
+	var got alter.Event
+	var signature, timestamp string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		json.Unmarshal(body, &got)
+		signature = r.Header.Get(SignatureHeader)
+		timestamp = r.Header.Get(TimestampHeader)
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			t.Errorf("expected custom header, got %q", r.Header.Get("Authorization"))
+		}
+	}))
+	defer server.Close()
+
+	bot, err := NewWebhookBot(server.URL, "secret", "ops", "", map[string]string{"Authorization": "Bearer abc"})
+	if err != nil {
+		t.Fatalf("NewWebhookBot failed: %v", err)
+	}
+
+	e := alter.NewEvent("TokenPriceMonitorTask", constant.SEVERITY_WARNING, "ops Price Alerts", "- **BTC**: ***$60000.00***")
+	if err := alter.Notify(bot, e); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	if got.Bot != "ops" || got.Task != "TokenPriceMonitorTask" || got.Severity != constant.SEVERITY_WARNING {
+		t.Errorf("unexpected event: %+v", got)
+	}
+	if len(got.Fields) != 1 || got.Fields[0].Value != "$60000.00" {
+		t.Errorf("unexpected fields: %+v", got.Fields)
+	}
+
+	ts, _ := strconv.ParseInt(timestamp, 10, 64)
+	if signature == "" || signature != bot.sign(ts, body) {
+		t.Errorf("unexpected signature %q", signature)
+	}
+}
+
+func TestSendText(t *testing.T) {
+	var got alter.Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	bot, err := NewWebhookBot(server.URL, "", "ops", "", nil)
+	if err != nil {
+		t.Fatalf("NewWebhookBot failed: %v", err)
+	}
+	if err := bot.SendText("ops hello\nsecond line", nil, false); err != nil {
+		t.Fatalf("SendText failed: %v", err)
+	}
+	if got.Title != "ops" || got.Markdown != "ops hello\nsecond line" || got.Severity != constant.SEVERITY_INFO || got.Timestamp.IsZero() {
+		t.Errorf("unexpected event: %+v", got)
+	}
+}
+
+func TestSendEvent_Template(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer server.Close()
+
+	tmpl := `{"text": {{json (printf "[%s] %s" .Severity .Title)}}, "fields": {{json .Fields}}}`
+	bot, err := NewWebhookBot(server.URL, "", "ops", tmpl, nil)
+	if err != nil {
+		t.Fatalf("NewWebhookBot failed: %v", err)
+	}
+
+	if err := bot.SendMarkdown(`say "hi"`, "- **K**: v", nil, false); err != nil {
+		t.Fatalf("SendMarkdown failed: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &decoded); err != nil {
+		t.Fatalf("template produced invalid json %q: %v", body, err)
+	}
+	if decoded["text"] != `[info] say "hi"` {
+		t.Errorf("unexpected text: %v", decoded["text"])
+	}
+}
+
+func TestSendEvent_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	bot, _ := NewWebhookBot(server.URL, "", "ops", "", nil)
+	err := bot.SendText("hello", nil, false)
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Fatalf("expected http error, got %v", err)
+	}
+}
+
+func TestNewWebhookBot_InvalidTemplate(t *testing.T) {
+	if _, err := NewWebhookBot("http://localhost", "", "ops", "{{.Title", nil); err == nil {
+		t.Fatalf("expected template parse error")
+	}
+}
@@ -3,9 +3,9 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
-
-	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
 )
 
 func PrintJson(obj interface{}) string {
@@ -43,59 +43,6 @@ func FormatRelativeTime(t time.Time) string {
 	}
 }
 
-// QuietHoursParams defines the quiet hours configuration for a task
-type QuietHoursParams struct {
-	Enabled            bool
-	StartHour          int
-	EndHour            int
-	Behavior           string // "pause" or "throttle"
-	ThrottleMultiplier int    // e.g. 5
-}
-
-// ShouldExecTask determines if a task should run based on Quiet Hours configuration.
-// It returns true if the task should run.
-func ShouldExecTask(params QuietHoursParams, lastRun time.Time, interval time.Duration) bool {
-	if !params.Enabled {
-		return true // Feature disabled, always run
-	}
-
-	location := time.FixedZone("CST", 8*3600)
-	nowBJ := time.Now().In(location)
-	hour := nowBJ.Hour()
-
-	inQuietHours := false
-	if params.StartHour < params.EndHour {
-		// e.g., 0 to 7
-		if hour >= params.StartHour && hour < params.EndHour {
-			inQuietHours = true
-		}
-	} else if params.StartHour > params.EndHour {
-		// e.g., 22 to 7 (crosses midnight)
-		if hour >= params.StartHour || hour < params.EndHour {
-			inQuietHours = true
-		}
-	} else {
-		// startH == endH, effectively disabled or empty range
-		return true
-	}
-
-	if !inQuietHours {
-		return true
-	}
-
-	// In Quiet Hours
-	if params.Behavior == constant.QUIET_HOURS_BEHAVIOR_THROTTLE {
-		if params.ThrottleMultiplier <= 1 {
-			return true // No throttling
-		}
-		// Check if enough time has passed: interval * multiplier
-		return time.Since(lastRun) >= interval*time.Duration(params.ThrottleMultiplier)
-	}
-
-	// Default behavior is "pause"
-	return false
-}
-
 // SnowflakeToTime converts a Twitter Snowflake ID to time.Time
 // Twitter Snowflake ID is (timestamp_ms - 1288834974657) << 22
 func SnowflakeToTime(snowflakeID string) (time.Time, error) {
@@ -109,3 +56,20 @@ func SnowflakeToTime(snowflakeID string) (time.Time, error) {
 	timestamp := (id >> 22) + 1288834974657
 	return time.UnixMilli(timestamp), nil
 }
+
+// ParseWindow parses a duration such as "30m", "24h" or "7d"; time.ParseDuration has no days.
+func ParseWindow(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid window %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid window %q", s)
+	}
+	return d, nil
+}
@@ -41,3 +41,16 @@ func TestShouldExecTask(t *testing.T) {
 
 	// Ideally I should refactor `ShouldExecTask` to `shouldExecTask(..., now time.Time)` and export a wrapper.
 }
+
+func TestParseWindow(t *testing.T) {
+	for in, expected := range map[string]time.Duration{"30m": 30 * time.Minute, "1h": time.Hour, "24h": 24 * time.Hour, "7d": 7 * 24 * time.Hour} {
+		if got, err := ParseWindow(in); err != nil || got != expected {
+			t.Errorf("ParseWindow(%q) = %v, %v; expected %v", in, got, err, expected)
+		}
+	}
+	for _, in := range []string{"", "d", "-1h", "1w", "0d"} {
+		if _, err := ParseWindow(in); err == nil {
+			t.Errorf("expected ParseWindow(%q) to fail", in)
+		}
+	}
+}
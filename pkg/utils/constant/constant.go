@@ -8,5 +8,12 @@ const (
 	QUIET_HOURS_BEHAVIOR_PAUSE    = "pause"
 	QUIET_HOURS_BEHAVIOR_THROTTLE = "throttle"
 
+	SEVERITY_INFO     = "info"
+	SEVERITY_WARNING  = "warning"
+	SEVERITY_CRITICAL = "critical"
+
+	EMAIL_DIGEST_HOURLY = "hourly"
+	EMAIL_DIGEST_DAILY  = "daily"
+
 	PAXG_TOKEN_ID = "4705"
 )
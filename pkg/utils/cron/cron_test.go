@@ -0,0 +1,80 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * * *",
+		"0 0 25 * * *",
+		"0 0 9-5 * * *",
+		"0 */0 * * * *",
+		"0 0 9 * * FOO",
+		"CRON_TZ=Mars/Base 0 0 9 * * *",
+		"CRON_TZ=Asia/Shanghai",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	bj := DefaultLocation
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+
+	tests := []struct {
+		spec     string
+		from     time.Time
+		expected time.Time
+	}{
+		// twice a day in Beijing time
+		{"0 0 9,21 * * *", time.Date(2025, 3, 1, 8, 59, 59, 0, bj), time.Date(2025, 3, 1, 9, 0, 0, 0, bj)},
+		{"0 0 9,21 * * *", time.Date(2025, 3, 1, 9, 0, 0, 0, bj), time.Date(2025, 3, 1, 21, 0, 0, 0, bj)},
+		{"0 0 9,21 * * *", time.Date(2025, 3, 1, 22, 0, 0, 0, bj), time.Date(2025, 3, 2, 9, 0, 0, 0, bj)},
+		// five fields, seconds default to 0
+		{"30 8 * * *", time.Date(2025, 3, 1, 8, 30, 0, 0, bj), time.Date(2025, 3, 2, 8, 30, 0, 0, bj)},
+		// seconds step
+		{"*/15 * * * * *", time.Date(2025, 3, 1, 8, 0, 7, 0, bj), time.Date(2025, 3, 1, 8, 0, 15, 0, bj)},
+		// weekdays by name, 2025-03-01 is a Saturday
+		{"0 0 9 * * MON-FRI", time.Date(2025, 3, 1, 10, 0, 0, 0, bj), time.Date(2025, 3, 3, 9, 0, 0, 0, bj)},
+		// Sunday as 7
+		{"0 0 9 * * 7", time.Date(2025, 3, 1, 10, 0, 0, 0, bj), time.Date(2025, 3, 2, 9, 0, 0, 0, bj)},
+		// day-of-month or day-of-week when both are restricted
+		{"0 0 0 15 * MON", time.Date(2025, 3, 4, 0, 0, 0, 0, bj), time.Date(2025, 3, 10, 0, 0, 0, 0, bj)},
+		// month rollover and leap day
+		{"0 0 0 29 2 *", time.Date(2025, 3, 1, 0, 0, 0, 0, bj), time.Date(2028, 2, 29, 0, 0, 0, 0, bj)},
+		// descriptor
+		{"@daily", time.Date(2025, 12, 31, 12, 0, 0, 0, bj), time.Date(2026, 1, 1, 0, 0, 0, 0, bj)},
+		// explicit timezone
+		{"CRON_TZ=America/New_York 0 0 9 * * *", time.Date(2025, 3, 1, 20, 0, 0, 0, bj), time.Date(2025, 3, 1, 9, 0, 0, 0, ny)},
+		{"TZ=UTC 0 0 0 * * *", time.Date(2025, 3, 1, 7, 0, 0, 0, bj), time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		s, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.spec, err)
+		}
+		if next := s.Next(tt.from); !next.Equal(tt.expected) {
+			t.Errorf("Next(%q, %s) = %s, expected %s", tt.spec, tt.from, next, tt.expected)
+		}
+	}
+}
+
+func TestSchedule_NextNever(t *testing.T) {
+	s, err := Parse("0 0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if next := s.Next(time.Now()); !next.IsZero() {
+		t.Errorf("expected no activation for Feb 30, got %s", next)
+	}
+}
@@ -0,0 +1,344 @@
+// Package expr evaluates boolean alert conditions over named metrics, e.g.
+//
+//	btc.ahr999 < 0.45 && fgi.value < 20
+//	polymarket["12345"].yes > 0.8
+//
+// Operands are numbers, metric references and parenthesized expressions. The operators are,
+// from lowest to highest precedence: ||, &&, comparisons (< <= > >= == !=), + -, * /,
+// and the unary ! and -. A metric reference is a dotted path whose segments may also be
+// written as an index ["key"] or [123]; segments are case-insensitive, so
+// polymarket["12345"].Yes and polymarket.12345.yes name the same metric.
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Metrics maps canonical metric names ("btc.ahr999", "polymarket.12345.yes") to values.
+type Metrics map[string]float64
+
+// Expr is a parsed expression.
+type Expr struct {
+	src     string
+	root    node
+	metrics []string
+}
+
+// Parse parses src, see the package documentation for the syntax.
+func Parse(src string) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at offset %d", t.text, t.pos)
+	}
+
+	e := &Expr{src: src, root: root}
+	seen := make(map[string]bool)
+	walk(root, func(n node) {
+		if m, ok := n.(metricNode); ok && !seen[string(m)] {
+			seen[string(m)] = true
+			e.metrics = append(e.metrics, string(m))
+		}
+	})
+	return e, nil
+}
+
+// String returns the source the expression was parsed from.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Metrics returns the canonical names of the metrics the expression references, in order of appearance.
+func (e *Expr) Metrics() []string {
+	return e.metrics
+}
+
+// Eval evaluates the expression, which must produce a boolean. It fails when a referenced
+// metric is missing from m.
+func (e *Expr) Eval(m Metrics) (bool, error) {
+	v, err := e.root.eval(m)
+	if err != nil {
+		return false, err
+	}
+	if !v.isBool {
+		return false, fmt.Errorf("expression %q is a number, not a condition", e.src)
+	}
+	return v.b, nil
+}
+
+// MetricName builds a canonical metric name from path segments.
+func MetricName(segments ...string) string {
+	return strings.ToLower(strings.Join(segments, "."))
+}
+
+type value struct {
+	num    float64
+	b      bool
+	isBool bool
+}
+
+type node interface {
+	eval(m Metrics) (value, error)
+}
+
+type numberNode float64
+
+type metricNode string
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func walk(n node, fn func(node)) {
+	fn(n)
+	switch n := n.(type) {
+	case unaryNode:
+		walk(n.operand, fn)
+	case binaryNode:
+		walk(n.left, fn)
+		walk(n.right, fn)
+	}
+}
+
+func (n numberNode) eval(Metrics) (value, error) {
+	return value{num: float64(n)}, nil
+}
+
+func (n metricNode) eval(m Metrics) (value, error) {
+	v, ok := m[string(n)]
+	if !ok {
+		return value{}, fmt.Errorf("unknown metric %s", string(n))
+	}
+	return value{num: v}, nil
+}
+
+func (n unaryNode) eval(m Metrics) (value, error) {
+	v, err := n.operand.eval(m)
+	if err != nil {
+		return value{}, err
+	}
+	if n.op == "!" {
+		if !v.isBool {
+			return value{}, fmt.Errorf("operator ! needs a condition")
+		}
+		return value{b: !v.b, isBool: true}, nil
+	}
+	if v.isBool {
+		return value{}, fmt.Errorf("operator - needs a number")
+	}
+	return value{num: -v.num}, nil
+}
+
+func (n binaryNode) eval(m Metrics) (value, error) {
+	l, err := n.left.eval(m)
+	if err != nil {
+		return value{}, err
+	}
+
+	switch n.op {
+	case "&&", "||":
+		if !l.isBool {
+			return value{}, fmt.Errorf("operator %s needs conditions", n.op)
+		}
+		// short-circuit, so a missing metric on the other side does not matter
+		if n.op == "&&" && !l.b || n.op == "||" && l.b {
+			return l, nil
+		}
+		r, err := n.right.eval(m)
+		if err != nil {
+			return value{}, err
+		}
+		if !r.isBool {
+			return value{}, fmt.Errorf("operator %s needs conditions", n.op)
+		}
+		return r, nil
+	}
+
+	r, err := n.right.eval(m)
+	if err != nil {
+		return value{}, err
+	}
+	if l.isBool || r.isBool {
+		if (n.op == "==" || n.op == "!=") && l.isBool && r.isBool {
+			return value{b: (l.b == r.b) == (n.op == "=="), isBool: true}, nil
+		}
+		return value{}, fmt.Errorf("operator %s needs numbers", n.op)
+	}
+
+	switch n.op {
+	case "+":
+		return value{num: l.num + r.num}, nil
+	case "-":
+		return value{num: l.num - r.num}, nil
+	case "*":
+		return value{num: l.num * r.num}, nil
+	case "/":
+		if r.num == 0 {
+			return value{}, fmt.Errorf("division by zero")
+		}
+		return value{num: l.num / r.num}, nil
+	case "<":
+		return value{b: l.num < r.num, isBool: true}, nil
+	case "<=":
+		return value{b: l.num <= r.num, isBool: true}, nil
+	case ">":
+		return value{b: l.num > r.num, isBool: true}, nil
+	case ">=":
+		return value{b: l.num >= r.num, isBool: true}, nil
+	case "==":
+		return value{b: l.num == r.num, isBool: true}, nil
+	case "!=":
+		return value{b: l.num != r.num, isBool: true}, nil
+	}
+	return value{}, fmt.Errorf("unknown operator %s", n.op)
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// binary parses a left-associative chain of the given operators.
+func (p *parser) binary(ops []string, operand func() (node, error)) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokOp || !contains(ops, t.text) {
+			return left, nil
+		}
+		p.next()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: t.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.binary([]string{"||"}, p.parseAnd)
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.binary([]string{"&&"}, p.parseComparison)
+}
+
+func (p *parser) parseComparison() (node, error) {
+	return p.binary([]string{"<", "<=", ">", ">=", "==", "!="}, p.parseSum)
+}
+
+func (p *parser) parseSum() (node, error) {
+	return p.binary([]string{"+", "-"}, p.parseProduct)
+}
+
+func (p *parser) parseProduct() (node, error) {
+	return p.binary([]string{"*", "/"}, p.parseUnary)
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if t := p.peek(); t.kind == tokOp && (t.text == "!" || t.text == "-") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op: t.text, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at offset %d", t.text, t.pos)
+		}
+		return numberNode(v), nil
+	case tokIdent:
+		return p.parseMetric(t)
+	case tokOp:
+		if t.text == "(" {
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if closing := p.next(); closing.text != ")" {
+				return nil, fmt.Errorf("expected ) at offset %d", closing.pos)
+			}
+			return inner, nil
+		}
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at offset %d", t.text, t.pos)
+}
+
+// parseMetric parses the rest of a metric path starting with the identifier first.
+func (p *parser) parseMetric(first token) (node, error) {
+	segments := []string{first.text}
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokOp && t.text == ".":
+			p.next()
+			seg := p.next()
+			if seg.kind != tokIdent && seg.kind != tokNumber {
+				return nil, fmt.Errorf("expected a name after . at offset %d", seg.pos)
+			}
+			segments = append(segments, seg.text)
+		case t.kind == tokOp && t.text == "[":
+			p.next()
+			key := p.next()
+			if key.kind != tokString && key.kind != tokNumber {
+				return nil, fmt.Errorf("expected a string or number index at offset %d", key.pos)
+			}
+			if closing := p.next(); closing.text != "]" {
+				return nil, fmt.Errorf("expected ] at offset %d", closing.pos)
+			}
+			segments = append(segments, key.text)
+		default:
+			return metricNode(MetricName(segments...)), nil
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
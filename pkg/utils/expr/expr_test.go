@@ -0,0 +1,77 @@
+package expr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	metrics := Metrics{
+		"btc.ahr999":                0.42,
+		"fgi.value":                 15,
+		"polymarket.12345.yes":      0.83,
+		"token.1.price":             61000,
+		"nft.infinex-patrons.floor": 0.9,
+	}
+
+	tests := []struct {
+		src      string
+		expected bool
+	}{
+		{"btc.ahr999 < 0.45 && fgi.value < 20", true},
+		{"btc.ahr999 < 0.45 && fgi.value > 20", false},
+		{`polymarket["12345"].yes > 0.8`, true},
+		{"polymarket.12345.Yes >= 0.9 || token[1].price > 60000", true},
+		{`nft["infinex-patrons"].floor * 2 >= 1.8`, true},
+		{"!(fgi.value < 20)", false},
+		{"token.1.price - 1000 == 60000", true},
+		{"-btc.ahr999 < 0", true},
+		{"1 + 2 * 3 == 7", true},
+		{"(1 + 2) * 3 == 9", true},
+		// short-circuit skips the missing metric
+		{"fgi.value > 50 && missing.metric > 1", false},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.src)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.src, err)
+			continue
+		}
+		got, err := e.Eval(metrics)
+		if err != nil || got != tt.expected {
+			t.Errorf("Eval(%q) = %v, %v; expected %v", tt.src, got, err, tt.expected)
+		}
+	}
+}
+
+func TestEval_Errors(t *testing.T) {
+	for _, src := range []string{"missing.metric > 1", "fgi.value + 1", "fgi.value / 0 > 1", "!fgi.value", "fgi.value < 20 + (1 < 2)"} {
+		e, err := Parse(src)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", src, err)
+			continue
+		}
+		if _, err := e.Eval(Metrics{"fgi.value": 10}); err == nil {
+			t.Errorf("expected Eval(%q) to fail", src)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, src := range []string{"", "fgi.value <", "(fgi.value < 1", `polymarket["1".yes`, "fgi.value $ 1", `polymarket["1`, "fgi. < 1", "1 2"} {
+		if _, err := Parse(src); err == nil {
+			t.Errorf("expected Parse(%q) to fail", src)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	e, err := Parse(`btc.ahr999 < 0.45 && polymarket["12345"].YES > 0.8 && btc.ahr999 > 0.1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"btc.ahr999", "polymarket.12345.yes"}
+	if !reflect.DeepEqual(e.Metrics(), expected) {
+		t.Errorf("expected %v, got %v", expected, e.Metrics())
+	}
+}
@@ -0,0 +1,148 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlHeadingRegex    = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	htmlFieldRegex      = regexp.MustCompile(`^-\s+\*\*(.+?)\*\*\s*[:：]\s*(.*)$`)
+	htmlBoldItalicRegex = regexp.MustCompile(`\*\*\*(.+?)\*\*\*`)
+	htmlBoldRegex       = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	htmlCodeRegex       = regexp.MustCompile("`([^`]+)`")
+	htmlLinkRegex       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	htmlBrRegex         = regexp.MustCompile(`(?i)&lt;br\s*/?&gt;`)
+)
+
+const (
+	tableStyle = `style="border-collapse:collapse;margin:8px 0;font-size:13px"`
+	cellStyle  = `style="border:1px solid #ddd;padding:4px 8px;text-align:left;vertical-align:top"`
+)
+
+// ToHTML converts the markdown produced by the monitor tasks into HTML.
+// Besides headings, separators and lists it renders:
+//   - pipe tables (e.g. the Polymarket daily report) as <table>
+//   - consecutive "- **Key**: value" lines (token prices, BTC dashboard) as a two-column <table>
+func ToHTML(text string) string {
+	var sb strings.Builder
+	lines := strings.Split(text, "\n")
+
+	for i := 0; i < len(lines); {
+		line := strings.TrimSpace(lines[i])
+
+		switch {
+		case line == "":
+			i++
+
+		case line == "---":
+			sb.WriteString("<hr/>\n")
+			i++
+
+		case htmlHeadingRegex.MatchString(line):
+			m := htmlHeadingRegex.FindStringSubmatch(line)
+			level := len(m[1])
+			sb.WriteString(fmt.Sprintf("<h%d>%s</h%d>\n", level, inlineHTML(m[2]), level))
+			i++
+
+		case strings.HasPrefix(line, "|"):
+			var rows []string
+			for i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|") {
+				rows = append(rows, strings.TrimSpace(lines[i]))
+				i++
+			}
+			sb.WriteString(tableToHTML(rows))
+
+		case htmlFieldRegex.MatchString(line):
+			sb.WriteString("<table " + tableStyle + ">\n")
+			for i < len(lines) {
+				raw := lines[i]
+				l := strings.TrimSpace(raw)
+				if m := htmlFieldRegex.FindStringSubmatch(l); m != nil && raw == strings.TrimLeft(raw, " \t") {
+					sb.WriteString(fmt.Sprintf("<tr><th %s>%s</th><td %s>%s</td></tr>\n", cellStyle, inlineHTML(m[1]), cellStyle, inlineHTML(m[2])))
+					i++
+					continue
+				}
+				// Indented sub-items are rendered as extra detail rows of the preceding field.
+				if raw != l && strings.HasPrefix(l, "-") {
+					sb.WriteString(fmt.Sprintf("<tr><td %s></td><td %s>%s</td></tr>\n", cellStyle, cellStyle, inlineHTML(strings.TrimLeft(l, "- \t"))))
+					i++
+					continue
+				}
+				break
+			}
+			sb.WriteString("</table>\n")
+
+		case strings.HasPrefix(line, "- "):
+			sb.WriteString("<ul>\n")
+			for i < len(lines) {
+				l := strings.TrimSpace(lines[i])
+				if !strings.HasPrefix(l, "- ") || htmlFieldRegex.MatchString(l) {
+					break
+				}
+				sb.WriteString("<li>" + inlineHTML(strings.TrimPrefix(l, "- ")) + "</li>\n")
+				i++
+			}
+			sb.WriteString("</ul>\n")
+
+		default:
+			sb.WriteString("<p>" + inlineHTML(line) + "</p>\n")
+			i++
+		}
+	}
+
+	return sb.String()
+}
+
+// tableToHTML renders markdown pipe table rows, the first row being the header.
+func tableToHTML(rows []string) string {
+	var sb strings.Builder
+	sb.WriteString("<table " + tableStyle + ">\n")
+	header := true
+	for _, row := range rows {
+		if strings.Contains(row, "---") && strings.Trim(row, "|-: ") == "" {
+			continue
+		}
+		tag := "td"
+		if header {
+			tag = "th"
+			header = false
+		}
+		sb.WriteString("<tr>")
+		for _, cell := range splitTableRow(row) {
+			sb.WriteString(fmt.Sprintf("<%s %s>%s</%s>", tag, cellStyle, inlineHTML(cell), tag))
+		}
+		sb.WriteString("</tr>\n")
+	}
+	sb.WriteString("</table>\n")
+	return sb.String()
+}
+
+// splitTableRow splits a table row on unescaped pipes and restores escaped ones.
+func splitTableRow(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	row = strings.TrimSuffix(row, "|")
+
+	const placeholder = "\x00"
+	row = strings.ReplaceAll(row, `\|`, placeholder)
+
+	parts := strings.Split(row, "|")
+	cells := make([]string, 0, len(parts))
+	for _, p := range parts {
+		cells = append(cells, strings.TrimSpace(strings.ReplaceAll(p, placeholder, "|")))
+	}
+	return cells
+}
+
+func inlineHTML(s string) string {
+	s = html.EscapeString(s)
+	s = htmlBrRegex.ReplaceAllString(s, "<br/>")
+	s = htmlLinkRegex.ReplaceAllString(s, `<a href="$2">$1</a>`)
+	s = htmlBoldItalicRegex.ReplaceAllString(s, "<b><i>$1</i></b>")
+	s = htmlBoldRegex.ReplaceAllString(s, "<b>$1</b>")
+	s = htmlCodeRegex.ReplaceAllString(s, "<code>$1</code>")
+	return s
+}
@@ -0,0 +1,65 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToHTML_FieldsAndHeadings(t *testing.T) {
+	text := "## token General Update\n\n" +
+		"### Token Prices\n" +
+		"#### Crypto Assets\n" +
+		"- **BTC**: ***$60000.00*** (0.50%)\n" +
+		"- **200 周均线 (200WMA)**: $40000.00\n  - 偏离度: 1.50x\n" +
+		"---\n" +
+		"- [View on Twitter](https://x.com/a/status/1)\n" +
+		"**Last Updated**: 2026-03-02 16:27:00"
+
+	got := ToHTML(text)
+
+	expected := []string{
+		"<h2>token General Update</h2>",
+		"<h4>Crypto Assets</h4>",
+		"<th " + cellStyle + ">BTC</th><td " + cellStyle + "><b><i>$60000.00</i></b> (0.50%)</td>",
+		"<td " + cellStyle + "></td><td " + cellStyle + ">偏离度: 1.50x</td>",
+		"<hr/>",
+		`<li><a href="https://x.com/a/status/1">View on Twitter</a></li>`,
+		"<p><b>Last Updated</b>: 2026-03-02 16:27:00</p>",
+	}
+	for _, e := range expected {
+		if !strings.Contains(got, e) {
+			t.Errorf("expected %q in output, got:\n%s", e, got)
+		}
+	}
+	if strings.Count(got, "<table") != 1 {
+		t.Errorf("expected field lines to share one table, got:\n%s", got)
+	}
+}
+
+func TestToHTML_ReportTable(t *testing.T) {
+	data := []TraderReportData{
+		{
+			WalletName:       "alice",
+			Address:          "0x1111111111111111111111111111111111111111",
+			ProxyAddr:        "0xaaaa111111111111111111111111111111111111",
+			Volume:           1000.50,
+			Rank:             "1",
+			CurrentPositions: "- Will BTC hit 100k? | Yes <br> - Fed cut? | No",
+		},
+	}
+
+	got := ToHTML(FormatReportTable(data))
+
+	if strings.Count(got, "<tr>") != 2 {
+		t.Fatalf("expected header and one row, got:\n%s", got)
+	}
+	if !strings.Contains(got, ">wallet_addr</th>") {
+		t.Errorf("expected header cells, got:\n%s", got)
+	}
+	if !strings.Contains(got, "<code>0x1111111111111111111111111111111111111111</code>") {
+		t.Errorf("expected code formatted address, got:\n%s", got)
+	}
+	if !strings.Contains(got, "- Will BTC hit 100k? | Yes <br/> - Fed cut? | No") {
+		t.Errorf("expected escaped pipes and line breaks restored in cell, got:\n%s", got)
+	}
+}
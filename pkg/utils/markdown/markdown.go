@@ -107,17 +107,40 @@ func ParseAddressList(filePath string) ([]WalletEntry, error) {
 	return entries, nil
 }
 
+const (
+	// REPORT_FILE_PREFIX starts the name of every report file, followed by ReportTimestamp.
+	REPORT_FILE_PREFIX = "polymarket_volume_"
+	// REPORT_TIMESTAMP_LAYOUT names report files in Beijing time, so they sort chronologically.
+	REPORT_TIMESTAMP_LAYOUT = "20060102_1504"
+)
+
+// ReportLocation is the time zone report files are named and dated in.
+var ReportLocation = time.FixedZone("UTC+8", 8*3600)
+
+// ReportTimestamp is the timestamp in the report file names of a run at t.
+func ReportTimestamp(t time.Time) string {
+	return t.In(ReportLocation).Format(REPORT_TIMESTAMP_LAYOUT)
+}
+
+// ParseReportTimestamp is the inverse of ReportTimestamp.
+func ParseReportTimestamp(s string) (time.Time, error) {
+	return time.ParseInLocation(REPORT_TIMESTAMP_LAYOUT, s, ReportLocation)
+}
+
 // WriteReportTable generates a markdown table and writes it to a new file in the output directory.
 func WriteReportTable(outputDir string, data []TraderReportData) error {
+	return WriteReportTableAt(outputDir, data, time.Now())
+}
+
+// WriteReportTableAt writes the report of a run at now, see WriteReportTable.
+func WriteReportTableAt(outputDir string, data []TraderReportData, now time.Time) error {
 	// Create output dir if it doesn't exist
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	bjLoc := time.FixedZone("UTC+8", 8*3600)
-	nowBJ := time.Now().In(bjLoc)
-	timestamp := nowBJ.Format("20060102_1504")
-	filename := fmt.Sprintf("polymarket_volume_%s.md", timestamp)
+	nowBJ := now.In(ReportLocation)
+	filename := REPORT_FILE_PREFIX + ReportTimestamp(now) + ".md"
 	filePath := filepath.Join(outputDir, filename)
 
 	file, err := os.Create(filePath)
@@ -134,19 +157,26 @@ func WriteReportTable(outputDir string, data []TraderReportData) error {
 		return err
 	}
 
-	// Write Table Headers
-	_, err = writer.WriteString("| wallet_addr | wallet_name | proxy_addr | total_volume | vol_rank | total_pnl | position_value | last_active | current_position |\n")
-	if err != nil {
-		return err
-	}
-	_, err = writer.WriteString("|---|---|---|---|---|---|---|---|---|\n")
+	// Write Table
+	_, err = writer.WriteString(FormatReportTable(data))
 	if err != nil {
 		return err
 	}
 
-	// Write Table Rows
+	return writer.Flush()
+}
+
+// FormatReportTable renders the trader report rows as a markdown table.
+func FormatReportTable(data []TraderReportData) string {
+	var sb strings.Builder
+
+	// Table Headers
+	sb.WriteString("| wallet_addr | wallet_name | proxy_addr | total_volume | vol_rank | total_pnl | position_value | last_active | current_position |\n")
+	sb.WriteString("|---|---|---|---|---|---|---|---|---|\n")
+
+	// Table Rows
 	for _, row := range data {
-		line := fmt.Sprintf("| `%s` | %s | `%s` | $%.2f | %s | $%.2f | $%.2f | %s | %s |\n",
+		sb.WriteString(fmt.Sprintf("| `%s` | %s | `%s` | $%.2f | %s | $%.2f | $%.2f | %s | %s |\n",
 			row.Address,
 			row.WalletName,
 			row.ProxyAddr,
@@ -156,14 +186,10 @@ func WriteReportTable(outputDir string, data []TraderReportData) error {
 			row.PositionValue,
 			row.LastActiveTime,
 			escapeMarkdown(row.CurrentPositions),
-		)
-		_, err = writer.WriteString(line)
-		if err != nil {
-			return err
-		}
+		))
 	}
 
-	return writer.Flush()
+	return sb.String()
 }
 
 // Helper functions
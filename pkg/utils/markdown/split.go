@@ -0,0 +1,104 @@
+package markdown
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Split breaks text into parts of at most maxBytes, cutting on section boundaries:
+// before "###" headings and at "---" separators (the separator itself is dropped at a cut).
+// A section that is still too long is cut between lines, and a single oversized line is cut
+// at a rune boundary. Text that fits is returned as the only part.
+func Split(text string, maxBytes int) []string {
+	if maxBytes <= 0 || len(text) <= maxBytes {
+		return []string{text}
+	}
+
+	var parts []string
+	var current strings.Builder
+
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			parts = append(parts, s)
+		}
+		current.Reset()
+	}
+
+	for _, sec := range splitSections(text) {
+		section := sec.text
+		joiner := "\n\n"
+		if sec.separated {
+			joiner = "\n\n---\n\n"
+		}
+		if current.Len() > 0 && current.Len()+len(joiner)+len(section) > maxBytes {
+			flush()
+		}
+		if len(section) <= maxBytes {
+			if current.Len() > 0 {
+				current.WriteString(joiner)
+			}
+			current.WriteString(section)
+			continue
+		}
+
+		// Oversized section: pack it line by line.
+		flush()
+		for _, line := range strings.Split(section, "\n") {
+			for len(line) > maxBytes {
+				flush()
+				cut := maxBytes
+				for cut > 0 && !utf8.RuneStart(line[cut]) {
+					cut--
+				}
+				parts = append(parts, line[:cut])
+				line = line[cut:]
+			}
+			if current.Len() > 0 && current.Len()+len(line)+1 > maxBytes {
+				flush()
+			}
+			if current.Len() > 0 {
+				current.WriteString("\n")
+			}
+			current.WriteString(line)
+		}
+	}
+	flush()
+
+	return parts
+}
+
+type section struct {
+	text      string
+	separated bool // preceded by a "---" separator
+}
+
+// splitSections cuts text before "###" headings and at "---" separators.
+func splitSections(text string) []section {
+	var sections []section
+	var lines []string
+	separated := false
+
+	cut := func() {
+		if s := strings.TrimSpace(strings.Join(lines, "\n")); s != "" {
+			sections = append(sections, section{text: s, separated: separated})
+			separated = false
+		}
+		lines = nil
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "---" {
+			cut()
+			separated = true
+			continue
+		}
+		if strings.HasPrefix(trimmed, "###") {
+			cut()
+		}
+		lines = append(lines, line)
+	}
+	cut()
+
+	return sections
+}
@@ -0,0 +1,56 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplit_FitsUnchanged(t *testing.T) {
+	text := "### A\n- item\n---\n### B"
+	parts := Split(text, 1000)
+	if len(parts) != 1 || parts[0] != text {
+		t.Errorf("expected text unchanged, got %q", parts)
+	}
+}
+
+func TestSplit_OnSectionBoundaries(t *testing.T) {
+	section := func(name string) string {
+		return "### " + name + "\n- " + strings.Repeat("x", 40)
+	}
+	text := section("A") + "\n\n---\n\n" + section("B") + "\n\n---\n\n" + section("C")
+
+	parts := Split(text, 120)
+	if len(parts) != 2 {
+		t.Fatalf("expected 2 parts, got %d: %q", len(parts), parts)
+	}
+	if parts[0] != section("A")+"\n\n---\n\n"+section("B") {
+		t.Errorf("expected separator to be kept inside a part, got %q", parts[0])
+	}
+	if parts[1] != section("C") {
+		t.Errorf("expected separator to be dropped at the cut, got %q", parts[1])
+	}
+	for _, p := range parts {
+		if len(p) > 120 {
+			t.Errorf("part exceeds limit: %d bytes", len(p))
+		}
+	}
+}
+
+func TestSplit_OversizedSectionAndLine(t *testing.T) {
+	text := "### Big\n" + strings.Repeat("a", 30) + "\n" + strings.Repeat("中", 20)
+
+	parts := Split(text, 25)
+	var joined strings.Builder
+	for _, p := range parts {
+		if len(p) > 25 {
+			t.Errorf("part exceeds limit: %d bytes", len(p))
+		}
+		if !strings.HasPrefix(p, "#") && strings.ContainsRune(p, '�') {
+			t.Errorf("part cut inside a rune: %q", p)
+		}
+		joined.WriteString(p)
+	}
+	if strings.Count(joined.String(), "中") != 20 || strings.Count(joined.String(), "a") != 30 {
+		t.Errorf("content lost while splitting: %q", parts)
+	}
+}
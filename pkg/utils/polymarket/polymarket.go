@@ -73,10 +73,13 @@ func (c *Client) fetchMarketRaw(marketID string) ([]byte, error) {
 
 func (c *Client) refineMarketData(market *Market) *MarketDetail {
 	detail := &MarketDetail{
+		ID:                 market.ID,
 		Question:           market.Question,
 		Slug:               market.Slug,
 		Closed:             market.Closed,
+		ClosedTime:         market.ClosedTime,
 		OneHourPriceChange: market.OneHourPriceChange,
+		OneDayPriceChange:  market.OneDayPriceChange,
 		OneWeekPriceChange: market.OneWeekPriceChange,
 	}
 
@@ -89,6 +92,10 @@ func (c *Client) refineMarketData(market *Market) *MarketDetail {
 
 	// Parse Outcomes and Prices
 	detail.OutcomePrices = c.parseOutcomePrices(market.Outcomes, market.OutcomePrices)
+	var outcomes []string
+	if err := json.Unmarshal([]byte(market.Outcomes), &outcomes); err == nil {
+		detail.Outcomes = outcomes
+	}
 
 	return detail
 }
@@ -76,12 +76,16 @@ type Market struct {
 
 // MarketDetail is the refined response structure
 type MarketDetail struct {
+	ID                 string             `json:"id"`
 	Question           string             `json:"question"`
 	Slug               string             `json:"slug"`
 	Volume             float64            `json:"volume"`
+	Outcomes           []string           `json:"outcomes"` // outcome names in market order, the price changes refer to the first
 	OutcomePrices      map[string]float64 `json:"outcome_prices"`
 	Closed             bool               `json:"closed"`
+	ClosedTime         string             `json:"closed_time,omitempty"`
 	OneHourPriceChange float64            `json:"one_hour_price_change"`
+	OneDayPriceChange  float64            `json:"one_day_price_change"`
 	OneWeekPriceChange float64            `json:"one_week_price_change"`
 }
 
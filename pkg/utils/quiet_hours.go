@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+// QuietHoursParams defines the quiet hours configuration for a task
+type QuietHoursParams struct {
+	Enabled            bool
+	StartHour          int // used when Windows is empty
+	EndHour            int
+	Behavior           string              // "pause" or "throttle"
+	ThrottleMultiplier int                 // e.g. 5
+	Location           *time.Location      // nil means Beijing time
+	Windows            []QuietWindow       // take precedence over StartHour/EndHour
+	Holidays           map[string]struct{} // "2006-01-02" dates that are quiet all day
+}
+
+// QuietWindow is a quiet period on selected days, in minutes since midnight.
+// A window with Start > End crosses midnight and belongs to the day it starts on.
+type QuietWindow struct {
+	Start              int
+	End                int         // exclusive, 1440 for end of day
+	Days               WeekdayMask // 0 means every day
+	Behavior           string      // overrides QuietHoursParams.Behavior when set
+	ThrottleMultiplier int         // overrides QuietHoursParams.ThrottleMultiplier when > 0
+}
+
+// WeekdayMask has bit time.Weekday set for each selected day.
+type WeekdayMask uint8
+
+const (
+	WEEKDAYS WeekdayMask = 1<<time.Monday | 1<<time.Tuesday | 1<<time.Wednesday | 1<<time.Thursday | 1<<time.Friday
+	WEEKENDS WeekdayMask = 1<<time.Saturday | 1<<time.Sunday
+)
+
+func (m WeekdayMask) Has(d time.Weekday) bool {
+	return m == 0 || m&(1<<d) != 0
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseWeekdays parses "weekdays", "weekends", "every day" (or empty), or a list of day names
+// and ranges such as "mon-thu,sat".
+func ParseWeekdays(s string) (WeekdayMask, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "", "*", "all", "everyday", "every day", "daily":
+		return 0, nil
+	case "weekdays", "weekday":
+		return WEEKDAYS, nil
+	case "weekends", "weekend":
+		return WEEKENDS, nil
+	}
+
+	var mask WeekdayMask
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		from, to, isRange := strings.Cut(part, "-")
+		start, ok := weekdayNames[strings.TrimSpace(from)]
+		if !ok {
+			return 0, fmt.Errorf("invalid weekday %q", from)
+		}
+		end := start
+		if isRange {
+			if end, ok = weekdayNames[strings.TrimSpace(to)]; !ok {
+				return 0, fmt.Errorf("invalid weekday %q", to)
+			}
+		}
+		// ranges may wrap around the week, e.g. "fri-mon"
+		for d := start; ; d = (d + 1) % 7 {
+			mask |= 1 << d
+			if d == end {
+				break
+			}
+		}
+	}
+	return mask, nil
+}
+
+// ParseClock parses "HH:MM" into minutes since midnight, "24:00" is accepted as end of day.
+func ParseClock(s string) (int, error) {
+	h, m, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	hour, err1 := strconv.Atoi(h)
+	minute, err2 := strconv.Atoi(m)
+	if err1 != nil || err2 != nil || hour < 0 || minute < 0 || minute > 59 || hour > 24 || hour == 24 && minute != 0 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return hour*60 + minute, nil
+}
+
+// ShouldExecTask determines if a task should run based on Quiet Hours configuration.
+// It returns true if the task should run.
+func ShouldExecTask(params QuietHoursParams, lastRun time.Time, interval time.Duration) bool {
+	return shouldExecTask(params, time.Now(), lastRun, interval)
+}
+
+func shouldExecTask(params QuietHoursParams, now, lastRun time.Time, interval time.Duration) bool {
+	if !params.Enabled {
+		return true // Feature disabled, always run
+	}
+
+	behavior, multiplier, inQuietHours := params.activeAt(now)
+	if !inQuietHours {
+		return true
+	}
+
+	// In Quiet Hours
+	if behavior == constant.QUIET_HOURS_BEHAVIOR_THROTTLE {
+		if multiplier <= 1 {
+			return true // No throttling
+		}
+		// Check if enough time has passed: interval * multiplier
+		return now.Sub(lastRun) >= interval*time.Duration(multiplier)
+	}
+
+	// Default behavior is "pause"
+	return false
+}
+
+// activeAt reports whether now falls in quiet hours and which behavior applies.
+func (p QuietHoursParams) activeAt(now time.Time) (behavior string, multiplier int, active bool) {
+	loc := p.Location
+	if loc == nil {
+		loc = time.FixedZone("CST", 8*3600)
+	}
+	local := now.In(loc)
+
+	if _, ok := p.Holidays[local.Format("2006-01-02")]; ok {
+		return p.Behavior, p.ThrottleMultiplier, true
+	}
+
+	windows := p.Windows
+	if len(windows) == 0 {
+		windows = []QuietWindow{{Start: p.StartHour * 60, End: p.EndHour * 60}}
+	}
+
+	minute := local.Hour()*60 + local.Minute()
+	today := local.Weekday()
+	yesterday := (today + 6) % 7
+	for _, w := range windows {
+		var in bool
+		switch {
+		case w.Start < w.End:
+			// e.g., 00:00 to 07:00
+			in = w.Days.Has(today) && minute >= w.Start && minute < w.End
+		case w.Start > w.End:
+			// e.g., 22:00 to 07:00 (crosses midnight)
+			in = w.Days.Has(today) && minute >= w.Start || w.Days.Has(yesterday) && minute < w.End
+		default:
+			// start == end, effectively an empty range
+		}
+		if !in {
+			continue
+		}
+
+		behavior, multiplier = p.Behavior, p.ThrottleMultiplier
+		if w.Behavior != "" {
+			behavior = w.Behavior
+		}
+		if w.ThrottleMultiplier > 0 {
+			multiplier = w.ThrottleMultiplier
+		}
+		return behavior, multiplier, true
+	}
+	return "", 0, false
+}
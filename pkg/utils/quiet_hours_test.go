@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/utils/constant"
+)
+
+func TestParseWeekdays(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected WeekdayMask
+	}{
+		{"", 0},
+		{"weekdays", WEEKDAYS},
+		{"Weekends", WEEKENDS},
+		{"mon-fri", WEEKDAYS},
+		{"sat, sun", WEEKENDS},
+		{"fri-mon", 1<<time.Friday | 1<<time.Saturday | 1<<time.Sunday | 1<<time.Monday},
+	}
+	for _, tt := range tests {
+		got, err := ParseWeekdays(tt.in)
+		if err != nil || got != tt.expected {
+			t.Errorf("ParseWeekdays(%q) = %b, %v; expected %b", tt.in, got, err, tt.expected)
+		}
+	}
+	if _, err := ParseWeekdays("mon-funday"); err == nil {
+		t.Errorf("expected an error for an unknown day")
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	for in, expected := range map[string]int{"00:00": 0, "07:30": 450, "23:59": 1439, "24:00": 1440} {
+		if got, err := ParseClock(in); err != nil || got != expected {
+			t.Errorf("ParseClock(%q) = %d, %v; expected %d", in, got, err, expected)
+		}
+	}
+	for _, in := range []string{"7", "25:00", "24:30", "12:60", "ab:cd"} {
+		if _, err := ParseClock(in); err == nil {
+			t.Errorf("expected ParseClock(%q) to fail", in)
+		}
+	}
+}
+
+func TestShouldExecTask_Windows(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	params := QuietHoursParams{
+		Enabled:  true,
+		Behavior: constant.QUIET_HOURS_BEHAVIOR_PAUSE,
+		Location: berlin,
+		Windows: []QuietWindow{
+			// weeknights 22:30-07:15
+			{Start: 22*60 + 30, End: 7*60 + 15, Days: WEEKDAYS},
+			// weekends are throttled during the day instead of paused
+			{Start: 0, End: 1440, Days: WEEKENDS, Behavior: constant.QUIET_HOURS_BEHAVIOR_THROTTLE, ThrottleMultiplier: 4},
+		},
+		Holidays: map[string]struct{}{"2025-12-25": {}},
+	}
+	interval := time.Hour
+	justRan := func(now time.Time) time.Time { return now.Add(-interval) }
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		lastRun  func(time.Time) time.Time
+		expected bool
+	}{
+		// 2025-03-03 is a Monday
+		{"weekday daytime", time.Date(2025, 3, 3, 12, 0, 0, 0, berlin), justRan, true},
+		{"weekday before window", time.Date(2025, 3, 3, 22, 29, 0, 0, berlin), justRan, true},
+		{"weekday window start", time.Date(2025, 3, 3, 22, 30, 0, 0, berlin), justRan, false},
+		{"after midnight of a weeknight", time.Date(2025, 3, 4, 7, 14, 0, 0, berlin), justRan, false},
+		{"window end", time.Date(2025, 3, 4, 7, 15, 0, 0, berlin), justRan, true},
+		// Saturday 03:00 still belongs to Friday night's window
+		{"friday night", time.Date(2025, 3, 8, 3, 0, 0, 0, berlin), justRan, false},
+		{"weekend throttled", time.Date(2025, 3, 8, 15, 0, 0, 0, berlin), justRan, false},
+		{"weekend throttle elapsed", time.Date(2025, 3, 8, 15, 0, 0, 0, berlin), func(now time.Time) time.Time { return now.Add(-4 * interval) }, true},
+		// Monday 03:00 belongs to Sunday night, which has no weeknight window, but the weekend window ended
+		{"monday early morning", time.Date(2025, 3, 10, 3, 0, 0, 0, berlin), justRan, true},
+		// holidays are quiet all day with the default behavior
+		{"holiday", time.Date(2025, 12, 25, 12, 0, 0, 0, berlin), justRan, false},
+		// evaluated in the configured timezone: 12:00 in Beijing is 05:00 in Berlin
+		{"timezone", time.Date(2025, 3, 4, 12, 0, 0, 0, time.FixedZone("CST", 8*3600)), justRan, false},
+	}
+	for _, tt := range tests {
+		if got := shouldExecTask(params, tt.now, tt.lastRun(tt.now), interval); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestShouldExecTask_LegacyHours(t *testing.T) {
+	bj := time.FixedZone("CST", 8*3600)
+	params := QuietHoursParams{Enabled: true, StartHour: 22, EndHour: 7, Behavior: constant.QUIET_HOURS_BEHAVIOR_PAUSE}
+
+	if shouldExecTask(params, time.Date(2025, 3, 3, 23, 0, 0, 0, bj), time.Time{}, time.Minute) {
+		t.Errorf("expected 23:00 Beijing time to be paused")
+	}
+	if !shouldExecTask(params, time.Date(2025, 3, 3, 7, 0, 0, 0, bj), time.Time{}, time.Minute) {
+		t.Errorf("expected 07:00 Beijing time to run")
+	}
+}
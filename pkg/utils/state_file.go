@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/ka1fe1/crypto-monitoring/pkg/logger"
+)
+
+// LoadStateFile reads JSON state into v. A missing file leaves v untouched, an unreadable
+// one is logged so the caller starts over instead of failing.
+func LoadStateFile(path string, v interface{}) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("Failed to read %s: %v", path, err)
+		}
+		return
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		logger.Warn("Failed to parse %s: %v", path, err)
+	}
+}
+
+// SaveStateFile writes v as JSON through a temporary file, so a crash never leaves half a file behind.
+func SaveStateFile(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
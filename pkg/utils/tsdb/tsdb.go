@@ -0,0 +1,371 @@
+// Package tsdb is a small embedded time-series store on top of bbolt. Every sample is kept
+// raw for a retention period and folded into downsampled buckets (rollups) as it is written,
+// so long ranges stay cheap to keep and to query.
+package tsdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	rawBucket    = "raw"
+	rollupPrefix = "rollup/"
+)
+
+// Sample is one observation of a series, e.g. series "token/1" with fields price and change_24h.
+type Sample struct {
+	Series string             `json:"series"`
+	At     time.Time          `json:"at"`
+	Fields map[string]float64 `json:"fields"`
+}
+
+// Aggregate summarises the values of one field within a rollup bucket.
+type Aggregate struct {
+	Open  float64 `json:"open"`
+	High  float64 `json:"high"`
+	Low   float64 `json:"low"`
+	Close float64 `json:"close"`
+	Sum   float64 `json:"sum"`
+	Count int     `json:"count"`
+}
+
+// Avg is the mean of the values, zero for an empty aggregate.
+func (a Aggregate) Avg() float64 {
+	if a.Count == 0 {
+		return 0
+	}
+	return a.Sum / float64(a.Count)
+}
+
+// add folds v into the aggregate. Samples are expected in time order, Close is the last one added.
+func (a *Aggregate) add(v float64) {
+	if a.Count == 0 {
+		a.Open, a.High, a.Low = v, v, v
+	}
+	a.High = max(a.High, v)
+	a.Low = min(a.Low, v)
+	a.Close = v
+	a.Sum += v
+	a.Count++
+}
+
+// merge folds b, which follows a in time, into a.
+func (a *Aggregate) merge(b Aggregate) {
+	if b.Count == 0 {
+		return
+	}
+	if a.Count == 0 {
+		*a = b
+		return
+	}
+	a.High = max(a.High, b.High)
+	a.Low = min(a.Low, b.Low)
+	a.Close = b.Close
+	a.Sum += b.Sum
+	a.Count += b.Count
+}
+
+// Bucket is one downsampled interval of a series, starting at Start.
+type Bucket struct {
+	Start  time.Time            `json:"start"`
+	Fields map[string]Aggregate `json:"fields"`
+}
+
+// Rollup keeps a series downsampled to Resolution for Retention, zero keeps it forever.
+// Buckets are aligned to UTC, so a 24h rollup starts at UTC midnight.
+type Rollup struct {
+	Resolution time.Duration
+	Retention  time.Duration
+}
+
+// Options are the retention and downsampling policies of a Store.
+type Options struct {
+	RawRetention time.Duration // zero keeps raw samples forever
+	Rollups      []Rollup
+}
+
+// Store holds the samples of any number of series in a single bbolt file.
+type Store struct {
+	db      *bolt.DB
+	options Options
+}
+
+// Open opens or creates the store at path. Rollups that are new to the file are built from the
+// raw samples still kept.
+func Open(path string, options Options) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create metric store dir: %w", err)
+	}
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open metric store: %w", err)
+	}
+
+	sort.Slice(options.Rollups, func(i, j int) bool { return options.Rollups[i].Resolution < options.Rollups[j].Resolution })
+	s := &Store{db: db, options: options}
+	if err := db.Update(s.init); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialise metric store: %w", err)
+	}
+	return s, nil
+}
+
+func (s *Store) init(tx *bolt.Tx) error {
+	raw, err := tx.CreateBucketIfNotExists([]byte(rawBucket))
+	if err != nil {
+		return err
+	}
+	for _, r := range s.options.Rollups {
+		if tx.Bucket(rollupName(r.Resolution)) != nil {
+			continue
+		}
+		if _, err := tx.CreateBucket(rollupName(r.Resolution)); err != nil {
+			return err
+		}
+		err := raw.ForEachBucket(func(series []byte) error {
+			return raw.Bucket(series).ForEach(func(k, v []byte) error {
+				var fields map[string]float64
+				if err := json.Unmarshal(v, &fields); err != nil {
+					return nil
+				}
+				return s.fold(tx, r.Resolution, string(series), decodeTime(k), fields)
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Write stores the samples in one transaction and folds them into every rollup. A sample at the
+// same time as one already stored for its series is skipped, the rollups would count it twice.
+func (s *Store) Write(samples ...Sample) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		raw := tx.Bucket([]byte(rawBucket))
+		for _, sample := range samples {
+			if sample.Series == "" || len(sample.Fields) == 0 {
+				continue
+			}
+			b, err := raw.CreateBucketIfNotExists([]byte(sample.Series))
+			if err != nil {
+				return err
+			}
+			key := encodeTime(sample.At)
+			if b.Get(key) != nil {
+				continue
+			}
+			data, err := json.Marshal(sample.Fields)
+			if err != nil {
+				return err
+			}
+			if err := b.Put(key, data); err != nil {
+				return err
+			}
+			for _, r := range s.options.Rollups {
+				if err := s.fold(tx, r.Resolution, sample.Series, sample.At, sample.Fields); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// fold adds the fields to the rollup bucket of resolution covering at.
+func (s *Store) fold(tx *bolt.Tx, resolution time.Duration, series string, at time.Time, fields map[string]float64) error {
+	b, err := tx.Bucket(rollupName(resolution)).CreateBucketIfNotExists([]byte(series))
+	if err != nil {
+		return err
+	}
+	key := encodeTime(at.Truncate(resolution))
+	aggs := make(map[string]Aggregate)
+	if v := b.Get(key); v != nil {
+		if err := json.Unmarshal(v, &aggs); err != nil {
+			return err
+		}
+	}
+	for name, value := range fields {
+		a := aggs[name]
+		a.add(value)
+		aggs[name] = a
+	}
+	data, err := json.Marshal(aggs)
+	if err != nil {
+		return err
+	}
+	return b.Put(key, data)
+}
+
+// Samples returns the raw samples of series in [from, to), oldest first.
+func (s *Store) Samples(series string, from, to time.Time) ([]Sample, error) {
+	var samples []Sample
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(rawBucket)).Bucket([]byte(series))
+		if b == nil {
+			return nil
+		}
+		return scan(b, from, to, func(at time.Time, v []byte) error {
+			var fields map[string]float64
+			if err := json.Unmarshal(v, &fields); err != nil {
+				return err
+			}
+			samples = append(samples, Sample{Series: series, At: at, Fields: fields})
+			return nil
+		})
+	})
+	return samples, err
+}
+
+// Buckets returns series downsampled to resolution in [from, to), oldest first. It reads the
+// coarsest rollup that divides resolution and merges its buckets; without one it aggregates
+// the raw samples.
+func (s *Store) Buckets(series string, resolution time.Duration, from, to time.Time) ([]Bucket, error) {
+	if resolution <= 0 {
+		return nil, fmt.Errorf("invalid resolution %s", resolution)
+	}
+	var source time.Duration
+	for _, r := range s.options.Rollups {
+		if resolution%r.Resolution == 0 {
+			source = r.Resolution
+		}
+	}
+
+	var buckets []Bucket
+	add := func(at time.Time, fields map[string]Aggregate) {
+		start := at.Truncate(resolution)
+		if n := len(buckets); n == 0 || !buckets[n-1].Start.Equal(start) {
+			buckets = append(buckets, Bucket{Start: start, Fields: make(map[string]Aggregate)})
+		}
+		last := buckets[len(buckets)-1].Fields
+		for name, agg := range fields {
+			a := last[name]
+			a.merge(agg)
+			last[name] = a
+		}
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if source == 0 {
+			b := tx.Bucket([]byte(rawBucket)).Bucket([]byte(series))
+			if b == nil {
+				return nil
+			}
+			return scan(b, from.Truncate(resolution), to, func(at time.Time, v []byte) error {
+				var fields map[string]float64
+				if err := json.Unmarshal(v, &fields); err != nil {
+					return err
+				}
+				aggs := make(map[string]Aggregate, len(fields))
+				for name, value := range fields {
+					var a Aggregate
+					a.add(value)
+					aggs[name] = a
+				}
+				add(at, aggs)
+				return nil
+			})
+		}
+		b := tx.Bucket(rollupName(source)).Bucket([]byte(series))
+		if b == nil {
+			return nil
+		}
+		return scan(b, from.Truncate(resolution), to, func(at time.Time, v []byte) error {
+			var aggs map[string]Aggregate
+			if err := json.Unmarshal(v, &aggs); err != nil {
+				return err
+			}
+			add(at, aggs)
+			return nil
+		})
+	})
+	return buckets, err
+}
+
+// Series lists the stored series starting with prefix.
+func (s *Store) Series(prefix string) ([]string, error) {
+	var series []string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(rawBucket)).ForEachBucket(func(k []byte) error {
+			if strings.HasPrefix(string(k), prefix) {
+				series = append(series, string(k))
+			}
+			return nil
+		})
+	})
+	return series, err
+}
+
+// Prune drops the raw samples and rollup buckets that are past their retention at now.
+func (s *Store) Prune(now time.Time) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if s.options.RawRetention > 0 {
+			if err := prune(tx.Bucket([]byte(rawBucket)), now.Add(-s.options.RawRetention)); err != nil {
+				return err
+			}
+		}
+		for _, r := range s.options.Rollups {
+			if r.Retention <= 0 {
+				continue
+			}
+			if err := prune(tx.Bucket(rollupName(r.Resolution)), now.Add(-r.Retention)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// prune deletes the keys before cutoff from every series bucket of parent.
+func prune(parent *bolt.Bucket, cutoff time.Time) error {
+	end := encodeTime(cutoff)
+	return parent.ForEachBucket(func(series []byte) error {
+		c := parent.Bucket(series).Cursor()
+		for k, _ := c.First(); k != nil && bytes.Compare(k, end) < 0; k, _ = c.First() {
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func scan(b *bolt.Bucket, from, to time.Time, fn func(at time.Time, v []byte) error) error {
+	end := encodeTime(to)
+	c := b.Cursor()
+	for k, v := c.Seek(encodeTime(from)); k != nil && bytes.Compare(k, end) < 0; k, v = c.Next() {
+		if err := fn(decodeTime(k), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rollupName(resolution time.Duration) []byte {
+	return []byte(rollupPrefix + resolution.String())
+}
+
+// encodeTime keys samples by Unix nanoseconds, big-endian so keys sort by time.
+func encodeTime(t time.Time) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, uint64(t.UnixNano()))
+	return k
+}
+
+func decodeTime(k []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(k)))
+}
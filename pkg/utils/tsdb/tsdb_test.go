@@ -0,0 +1,76 @@
+package tsdb
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStore_WriteRollupAndPrune(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.db")
+	options := Options{RawRetention: 24 * time.Hour, Rollups: []Rollup{{Resolution: time.Hour}}}
+	store, err := Open(path, options)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	for i, price := range []float64{100, 120, 90, 110, 130, 125} {
+		at := start.Add(time.Duration(i) * 20 * time.Minute)
+		if err := store.Write(Sample{Series: "token/1", At: at, Fields: map[string]float64{"price": price}}); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	// a second sample at the same time is skipped, in the raw samples and the rollups
+	if err := store.Write(Sample{Series: "token/1", At: start, Fields: map[string]float64{"price": 1000}}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	samples, err := store.Samples("token/1", start.Add(20*time.Minute), start.Add(time.Hour))
+	if err != nil || len(samples) != 2 || samples[0].Fields["price"] != 120 {
+		t.Fatalf("expected the two samples in range, got %+v, %v", samples, err)
+	}
+
+	buckets, err := store.Buckets("token/1", time.Hour, start, start.Add(2*time.Hour))
+	if err != nil || len(buckets) != 2 {
+		t.Fatalf("expected two hourly buckets, got %+v, %v", buckets, err)
+	}
+	want := Aggregate{Open: 100, High: 120, Low: 90, Close: 90, Sum: 310, Count: 3}
+	if got := buckets[0].Fields["price"]; got != want {
+		t.Errorf("first hour: got %+v, want %+v", got, want)
+	}
+
+	// two hours merge from the hourly rollup, 30 minutes fall back to the raw samples
+	buckets, _ = store.Buckets("token/1", 2*time.Hour, start, start.Add(2*time.Hour))
+	if len(buckets) != 1 || buckets[0].Fields["price"].Close != 125 || buckets[0].Fields["price"].High != 130 {
+		t.Errorf("expected one merged bucket, got %+v", buckets)
+	}
+	buckets, _ = store.Buckets("token/1", 30*time.Minute, start, start.Add(2*time.Hour))
+	if len(buckets) != 4 {
+		t.Errorf("expected four raw buckets, got %+v", buckets)
+	}
+
+	if err := store.Prune(start.Add(25 * time.Hour)); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if samples, _ := store.Samples("token/1", start, start.Add(2*time.Hour)); len(samples) != 3 {
+		t.Errorf("expected the samples older than a day to be pruned, got %+v", samples)
+	}
+	store.Close()
+
+	// a rollup added later is built from the raw samples kept
+	options.Rollups = append(options.Rollups, Rollup{Resolution: 24 * time.Hour})
+	store, err = Open(path, options)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer store.Close()
+	buckets, _ = store.Buckets("token/1", 24*time.Hour, start, start.Add(24*time.Hour))
+	if len(buckets) != 1 || buckets[0].Fields["price"].Count != 3 {
+		t.Errorf("expected the daily rollup from the kept samples, got %+v", buckets)
+	}
+	if series, _ := store.Series("token/"); len(series) != 1 || series[0] != "token/1" {
+		t.Errorf("unexpected series %v", series)
+	}
+}